@@ -0,0 +1,103 @@
+package klaviyo
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// KlaviyoAPI is every call *Client makes to Klaviyo, so services can depend on it instead of the Client and swap in a
+// mock, NoopClient or RecordingClient in tests and development. WithContext, WithTimeout, WithResponseMeta,
+// WithRawResponse and WithUnsafeRetries are not part of it since they configure a *Client.
+type KlaviyoAPI interface {
+	// Profiles
+	Identify(person *Person, opts ...IdentifyOption) error
+	IdentifySafe(person *Person, omit bool, opts ...IdentifyOption) error
+	IdentifyBatch(people []Person) ([]string, error)
+	ImportCSV(r io.Reader, opts *CSVImportOptions) (*CSVImportReport, error)
+	GetPerson(personId string) (*Person, error)
+	GetPersonAttributesOnly(personId string, names ...string) (Attributes, error)
+	GetPeople(page, count int) (*PeoplePage, error)
+	EachPerson(count int, fn func(*Person) error) error
+	EachPersonFrom(cursor *Cursor, count int, fn func(*Person) error) error
+	GetProfiles(q *Query) (*ProfilePage, error)
+	EachProfile(q *Query, fn func(*Profile) error) error
+	EachProfileFrom(q *Query, cursor *Cursor, fn func(*Profile) error) error
+	SyncChangedProfiles(since time.Time, fn func(*Profile) error) (time.Time, error)
+	FindPersonId(p *Person) (string, error)
+	UpdatePerson(person *Person) error
+	UpdatePersonDiff(old, new *Person) error
+	UpdatePersonByEmail(email string, attrs map[string]interface{}) error
+	PatchPerson(person *Person, patch *ProfilePatch) error
+	DeletePerson(profileId string) error
+	GetPredictiveAnalytics(profileId string) (*PredictiveAnalytics, error)
+	GetPersonGroups(personId string) ([]Group, error)
+
+	// Lists and segments
+	GetLists() ([]List, error)
+	GetList(listId string) (*List, error)
+	GetSegment(segmentId string) (*Segment, error)
+	DoubleOptIn(listId string) (bool, error)
+	CountListMembers(listId string) (int, error)
+	GroupSizes(groupIds ...string) (map[string]GroupSample, error)
+	SampleGroupGrowth(ctx context.Context, groupIds []string, interval time.Duration, samples int) (map[string][]GroupSample, error)
+	GetListExclusions(listId string, marker int) ([]ListExclusion, int, error)
+	GetGroupMembers(groupId string, marker int) ([]ListPerson, int, error)
+	StreamGroupMembers(groupId string, marker int, fn func(ListPerson) error) (int, error)
+	EachGroupMember(ctx context.Context, groupId string, fn func(ListPerson) error) error
+	EachGroupMemberFrom(ctx context.Context, groupId string, cursor *Cursor, fn func(ListPerson) error) error
+	ForEachListMember(ctx context.Context, listId string, concurrency int, fn func(ListPerson) error) error
+	ExportGroupMembers(w io.Writer, groupId string, format ExportFormat) error
+	ExportGroupMembersReader(groupId string, format ExportFormat) io.ReadCloser
+	ExportGroupMembersToFile(filename, groupId string, format ExportFormat) error
+	InList(listId string, emails, phoneNumbers, pushTokens []string) ([]ListPerson, error)
+	AddProfilesToList(listId string, profileIds []string) error
+	RemoveProfilesFromList(listId string, profileIds []string) error
+
+	// Subscriptions
+	Subscribe(listId string, emails, phoneNumbers []string) ([]ListPerson, error)
+	SubscribeSMS(listId, phone string, consentTimestamp time.Time, method string) (*ListPerson, error)
+	SubscribeProfiles(listId string, profiles []SubscribeProfile) ([]ListPerson, error)
+	SubscribeWithStatus(listId string, profiles []SubscribeProfile) (*SubscribeResponse, error)
+	Unsubscribe(listId string, emails, phoneNumbers, pushTokens []string) error
+	UnsubscribeAll(listId string, person *Person) error
+	BulkSubscribeProfiles(listId string, profiles []SubscriptionProfile) error
+	BulkUnsubscribeProfiles(listId string, profiles []SubscriptionProfile) error
+	RegisterPushToken(person *Person, token string, platform PushPlatform) error
+	UnregisterPushToken(person *Person, token string, platform PushPlatform) error
+	SuppressProfiles(emails []string) error
+	UnsuppressProfiles(emails []string) error
+	SuppressOnBounce(email, reason string) error
+
+	// Events and metrics
+	CreateEvent(e *NewEvent) error
+	CreateClientEvent(e *NewEvent) error
+	CreateClientProfile(person *Person) error
+	GetEvents(q *Query) (*EventPage, error)
+	GetMetrics(q *Query) (*MetricPage, error)
+	QueryMetricAggregates(q *MetricAggregateQuery) (*MetricAggregate, error)
+	GetMetricExport(metricId string, q *MetricExportQuery) (*MetricExport, error)
+
+	// Campaigns, flows, forms and templates
+	CreateCampaign(listId, templateId, subject, fromEmail, fromName, name string) (*Campaign, error)
+	GetCampaignRecipients(campaignId string) ([]CampaignRecipient, error)
+	GetCampaignStats(campaignId string, start, end time.Time) (*CampaignStats, error)
+	GetFlows(q *Query) (*FlowPage, error)
+	GetFlowActions(flowId string) ([]FlowAction, error)
+	GetFlowMessages(actionId string) ([]FlowMessage, error)
+	GetForms(q *Query) (*FormPage, error)
+	GetForm(formId string) (*Form, error)
+	TrackFormSubmission(s *FormSubmission) error
+	GetTemplate(templateId string) (*Template, error)
+	CloneTemplate(templateId, newName string) (*Template, error)
+	UpdateTemplateHTML(templateId, html string) (*TemplateBackup, error)
+	RestoreTemplate(backup *TemplateBackup) error
+	ValidateTemplate(templateId string, context map[string]interface{}) (*TemplateContextReport, error)
+	SendTemplateEmail(templateId, from, fromName, subject string, to []EmailRecipient, context map[string]interface{}) (*TemplateSendResult, error)
+	RenderTemplatePreview(templateId string, context map[string]interface{}) (*TemplatePreview, error)
+	RenderTemplatePreviewForPerson(templateId, personId string, context map[string]interface{}) (*TemplatePreview, error)
+
+	HealthCheck(ctx context.Context) (*HealthStatus, error)
+}
+
+var _ KlaviyoAPI = (*Client)(nil)
@@ -0,0 +1,135 @@
+package klaviyo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Klaviyo truncates longer attribute names.
+const maxAttributeNameLength = 255
+
+// Custom attribute names which clash with keys Klaviyo uses for the profile itself.
+var reservedAttributeNames = map[string]bool{
+	"id":     true,
+	"object": true,
+}
+
+type InvalidAttributeError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidAttributeError) Error() string {
+	return fmt.Sprintf("invalid attribute name %q: %s", e.Name, e.Reason)
+}
+
+// Checks that name can be used as a custom attribute. The $ prefix is reserved for Klaviyo's special properties, which
+// should be set through the fields on Person instead.
+func ValidateAttributeName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return &InvalidAttributeError{Name: name, Reason: "name is empty"}
+	case strings.HasPrefix(name, "$"):
+		return &InvalidAttributeError{Name: name, Reason: "the $ prefix is reserved for special properties"}
+	case reservedAttributeNames[strings.ToLower(name)]:
+		return &InvalidAttributeError{Name: name, Reason: "name is reserved"}
+	case strings.TrimSpace(name) != name:
+		return &InvalidAttributeError{Name: name, Reason: "name has leading or trailing whitespace"}
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return &InvalidAttributeError{Name: name, Reason: "name contains control characters"}
+	case len(name) > maxAttributeNameLength:
+		return &InvalidAttributeError{Name: name, Reason: fmt.Sprintf("name is longer than %d bytes", maxAttributeNameLength)}
+	}
+	return nil
+}
+
+// Turns name into a valid attribute name by removing control characters, surrounding whitespace and the $ prefix.
+// Reserved names get an underscore prefix. Returns an empty string if nothing usable is left.
+func SanitizeAttributeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(strings.TrimLeft(name, "$"))
+	if reservedAttributeNames[strings.ToLower(name)] {
+		name = "_" + name
+	}
+	if len(name) > maxAttributeNameLength {
+		name = strings.TrimSpace(name[:maxAttributeNameLength])
+	}
+	return name
+}
+
+// Returns the first invalid attribute name, checked in alphabetical order so the result is stable.
+func (a Attributes) Validate() error {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := ValidateAttributeName(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a copy with every name sanitized, attributes which cannot be sanitized are dropped.
+func (a Attributes) Sanitize() Attributes {
+	res := Attributes{}
+	for k, v := range a {
+		if name := SanitizeAttributeName(k); name != "" {
+			res[name] = v
+		}
+	}
+	return res
+}
+
+// Validates or sanitizes the custom attributes of p depending on Client.SanitizeAttributes, then checks them against
+// Client.Schema. The returned person is a copy when anything was changed.
+func (c *Client) checkAttributes(p *Person) (*Person, error) {
+	if err := p.Attributes.Validate(); err != nil {
+		if !c.SanitizeAttributes {
+			return p, err
+		}
+		cp := *p
+		cp.Attributes = p.Attributes.Sanitize()
+		p = &cp
+	}
+	if len(c.Schema) == 0 {
+		return p, nil
+	}
+	attrs, err := c.Schema.Check(p.Attributes, c.CoerceAttributes)
+	if err != nil {
+		return p, err
+	}
+	cp := *p
+	cp.Attributes = attrs
+	return &cp, nil
+}
+
+// Returns a copy of p with Client.DefaultAttributes added to its attributes, values already set on p win. The
+// TestAttribute is added on top in test mode. p is returned as is when there is nothing to add.
+func (c *Client) withDefaultAttributes(p *Person) *Person {
+	testAttribute := c.TestMode && c.TestAttribute != ""
+	if len(c.DefaultAttributes) == 0 && !testAttribute {
+		return p
+	}
+	cp := *p
+	cp.Attributes = Attributes{}
+	for k, v := range c.DefaultAttributes {
+		cp.Attributes[k] = v
+	}
+	for k, v := range p.Attributes {
+		cp.Attributes[k] = v
+	}
+	if testAttribute {
+		cp.Attributes[c.TestAttribute] = true
+	}
+	return &cp
+}
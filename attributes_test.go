@@ -0,0 +1,62 @@
+package klaviyo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAttributeName(t *testing.T) {
+	valid := []string{attrIsTest, attrLikesGold, "Favourite Genre", "ids"}
+	for _, name := range valid {
+		if err := ValidateAttributeName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %s", name, err)
+		}
+	}
+	invalid := []string{"", "  ", "$email", "id", "Object", " LikesGold", "Likes\nGold"}
+	for _, name := range invalid {
+		var attrErr *InvalidAttributeError
+		if err := ValidateAttributeName(name); !errors.As(err, &attrErr) {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestSanitizeAttributeName(t *testing.T) {
+	tests := map[string]string{
+		attrIsTest:    attrIsTest,
+		"$email":      "email",
+		" LikesGold ": "LikesGold",
+		"Likes\nGold": "LikesGold",
+		"id":          "_id",
+		"$":           "",
+	}
+	for in, expected := range tests {
+		if out := SanitizeAttributeName(in); out != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, in, out)
+		}
+		if out := SanitizeAttributeName(in); out != "" && ValidateAttributeName(out) != nil {
+			t.Errorf("Sanitized name %q is not valid", out)
+		}
+	}
+}
+
+func TestClient_CheckAttributes(t *testing.T) {
+	client := &Client{}
+	p := newTestPerson()
+	p.Attributes["$LikesGold"] = true
+	if _, err := client.checkAttributes(&p); err == nil {
+		t.Error("Expected an error for an invalid attribute")
+	}
+
+	client.SanitizeAttributes = true
+	s, err := client.checkAttributes(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Attributes[attrLikesGold] != true {
+		t.Error("Expected the attribute to be sanitized")
+	}
+	if _, ok := p.Attributes[attrLikesGold]; ok {
+		t.Error("The original person should not be changed")
+	}
+}
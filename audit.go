@@ -0,0 +1,58 @@
+package klaviyo
+
+// A change the client made to a profile, see Client.OnProfileChange.
+type ProfileChange struct {
+	// Identify or UpdatePerson. Calls built on top of them, such as IdentifySafe, UpdatePersonDiff or PatchPerson,
+	// report the call they went through.
+	Call string
+
+	// The Klaviyo id of the profile, empty when Identify was given a person without one.
+	ProfileId string
+
+	// The person given to the call.
+	Person *Person
+
+	// The values sent and what they were before the call, keyed like Person.GetMap. Keys the profile had no value for
+	// are missing from Before. Before is nil when the previous values are not known, see Client.FetchBeforeChange.
+	Before map[string]interface{}
+	After  map[string]interface{}
+}
+
+// Returns the values of the keys in after which the profile has before it is changed, or nil when they are not known.
+// old is the profile as the caller last saw it, when nil it is fetched if the client is configured to.
+func (c *Client) valuesBefore(id string, old *Person, after map[string]interface{}) map[string]interface{} {
+	if c.OnProfileChange == nil || c.DryRun {
+		return nil
+	}
+	if old == nil {
+		if !c.FetchBeforeChange || id == "" {
+			return nil
+		}
+		var err error
+		if old, err = c.GetPerson(id); err != nil {
+			return nil
+		}
+	}
+	current := old.GetMap()
+	before := map[string]interface{}{}
+	for k := range after {
+		if v, ok := current[k]; ok {
+			before[k] = v
+		}
+	}
+	return before
+}
+
+// Reports a successful change to OnProfileChange. Nothing changed in DryRun so nothing is reported.
+func (c *Client) auditChange(call, id string, person *Person, before, after map[string]interface{}) {
+	if c.OnProfileChange == nil || c.DryRun {
+		return
+	}
+	c.OnProfileChange(ProfileChange{
+		Call:      call,
+		ProfileId: id,
+		Person:    person,
+		Before:    before,
+		After:     after,
+	})
+}
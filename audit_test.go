@@ -0,0 +1,71 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClient_OnProfileChange(t *testing.T) {
+	var gets int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/identify" {
+			w.Header().Set("Content-Type", ContentHTML)
+			w.Write([]byte("1"))
+			return
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		switch {
+		case r.Method == http.MethodGet:
+			gets++
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1", "$email": "kitty@monstercat.com", "Tier": "silver"}`))
+		default:
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1", "$email": "kitty@monstercat.com", "Tier": "gold"}`))
+		}
+	})
+	var changes []ProfileChange
+	client.OnProfileChange = func(c ProfileChange) {
+		changes = append(changes, c)
+	}
+
+	old := &Person{Object: Object{Id: "PROFILE1"}, Email: "kitty@monstercat.com", Attributes: Attributes{"Tier": "silver"}}
+	updated := &Person{Object: Object{Id: "PROFILE1"}, Email: "kitty@monstercat.com", Attributes: Attributes{"Tier": "gold"}}
+	if err := client.UpdatePersonDiff(old, updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Call != "UpdatePerson" || changes[0].ProfileId != "PROFILE1" {
+		t.Fatalf("Unexpected changes %+v", changes)
+	}
+	if changes[0].Before["Tier"] != "silver" || changes[0].After["Tier"] != "gold" || len(changes[0].After) != 1 {
+		t.Errorf("Unexpected change %+v", changes[0])
+	}
+
+	if err := client.UpdatePerson(updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[1].Before != nil || gets != 0 {
+		t.Errorf("Expected no previous values without FetchBeforeChange, got %+v", changes[1])
+	}
+
+	client.FetchBeforeChange = true
+	if err := client.UpdatePerson(updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 || changes[2].Before["Tier"] != "silver" || gets != 1 {
+		t.Errorf("Expected the previous values to be fetched, got %+v", changes[2])
+	}
+
+	if err := client.Identify(&Person{Email: "kitty@monstercat.com", Attributes: Attributes{"Tier": "gold"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 || changes[3].Call != "Identify" || changes[3].Before != nil || changes[3].After["Tier"] != "gold" {
+		t.Errorf("Unexpected change %+v", changes[3])
+	}
+
+	client.DryRun = true
+	if err := client.UpdatePerson(updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("Expected no change to be reported in DryRun, got %+v", changes[4:])
+	}
+}
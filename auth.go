@@ -0,0 +1,231 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Klaviyo's OAuth token endpoint.
+const OAuthTokenURL = "https://a.klaviyo.com/oauth/token"
+
+// Tokens are refreshed this long before they expire so they do not expire in flight.
+const tokenExpiryMargin = time.Minute
+
+var (
+	ErrNoRefreshToken = errors.New("missing oauth refresh token")
+)
+
+// Authenticator adds credentials to v3 requests. The legacy v1 and v2 endpoints only accept Client.PrivateKey.
+type Authenticator interface {
+	Authorize(r *http.Request) error
+}
+
+// Authenticates with a private API key. This is what the client does when Client.Auth is not set.
+type APIKeyAuth struct {
+	PrivateKey string
+}
+
+func (a *APIKeyAuth) Authorize(r *http.Request) error {
+	if a.PrivateKey == "" {
+		return ErrNoPrivateKey
+	}
+	r.Header.Set("Authorization", "Klaviyo-API-Key "+a.PrivateKey)
+	return nil
+}
+
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// Returns true when the token is missing or about to expire.
+func (t *Token) expired() bool {
+	return t == nil || t.AccessToken == "" || (!t.Expiry.IsZero() && time.Now().Add(tokenExpiryMargin).After(t.Expiry))
+}
+
+// TokenSource returns a valid access token, refreshing it when needed. It has the same shape as oauth2.TokenSource
+// so those can be adapted easily.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// Authenticates as a Klaviyo OAuth app with bearer tokens from Source.
+type OAuthAuth struct {
+	Source TokenSource
+}
+
+func (a *OAuthAuth) Authorize(r *http.Request) error {
+	t, err := a.Source.Token()
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	return nil
+}
+
+// RefreshTokenSource exchanges a refresh token for access tokens through Klaviyo's token endpoint, caching the access
+// token until it is about to expire. Safe for concurrent use.
+type RefreshTokenSource struct {
+	ClientId     string
+	ClientSecret string
+
+	// Defaults to OAuthTokenURL.
+	TokenURL string
+
+	// Used to call the token endpoint, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Called after every refresh. Klaviyo rotates refresh tokens so the new one must be persisted.
+	OnRefresh func(t *Token)
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// Creates a source starting from a stored refresh token.
+func NewRefreshTokenSource(clientId, clientSecret, refreshToken string) *RefreshTokenSource {
+	return &RefreshTokenSource{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		token:        &Token{RefreshToken: refreshToken},
+	}
+}
+
+func (s *RefreshTokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.token.expired() {
+		return s.token, nil
+	}
+	if s.token == nil || s.token.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	t, err := s.refresh(s.token.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	s.token = t
+	if s.OnRefresh != nil {
+		s.OnRefresh(t)
+	}
+	return t, nil
+}
+
+func (s *RefreshTokenSource) refresh(refreshToken string) (*Token, error) {
+	tokenURL := s.TokenURL
+	if tokenURL == "" {
+		tokenURL = OAuthTokenURL
+	}
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", ContentForm)
+	req.SetBasicAuth(s.ClientId, s.ClientSecret)
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("refreshing oauth token: %d %s %s", res.StatusCode, body.Error, body.ErrorDescription)
+	}
+	t := &Token{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+	if body.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// Adds the credentials for a v3 request. Test mode always uses the test account's private key.
+func (c *Client) authorize(r *http.Request, test bool) error {
+	switch {
+	case test:
+		return (&APIKeyAuth{PrivateKey: c.TestPrivateKey}).Authorize(r)
+	case c.Auth != nil:
+		return c.Auth.Authorize(r)
+	}
+	return (&APIKeyAuth{PrivateKey: c.PrivateKey}).Authorize(r)
+}
+
+// Reported through Client.OnKeyRotation when a request was rejected with the primary private key and sent again with
+// SecondaryPrivateKey.
+type KeyRotationEvent struct {
+	Method string
+	Path   string
+
+	// Why the primary key was rejected.
+	PrimaryErr error
+
+	// The result of the request with the secondary key, nil when it went through.
+	Err error
+}
+
+// Klaviyo answers with 401 for invalid keys and 403 for revoked keys or missing scopes.
+func isAuthError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+}
+
+// Sends a request rejected with the primary private key again with the secondary one.
+func (c *Client) retryWithSecondaryKey(r *http.Request, out interface{}, v3 bool, primaryErr error) error {
+	if v3 {
+		if err := (&APIKeyAuth{PrivateKey: c.SecondaryPrivateKey}).Authorize(r); err != nil {
+			return err
+		}
+	} else {
+		values := r.URL.Query()
+		values.Set("api_key", c.SecondaryPrivateKey)
+		r.URL.RawQuery = values.Encode()
+	}
+	if err := c.sign(r); err != nil {
+		return err
+	}
+	if err := resetBody(r); err != nil {
+		return err
+	}
+	err := c.retryRoundTrip(r, out)
+	if c.OnKeyRotation != nil {
+		c.OnKeyRotation(KeyRotationEvent{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			PrimaryErr: primaryErr,
+			Err:        err,
+		})
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package klaviyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshTokenSource(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+		id, secret, _ := r.BasicAuth()
+		if id != "app" || secret != "secret" {
+			t.Errorf("Unexpected client credentials %s:%s", id, secret)
+		}
+		if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh1" {
+			t.Errorf("Unexpected form %v", r.Form)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"access_token": "access1", "refresh_token": "refresh2", "expires_in": 3600, "token_type": "Bearer"}`))
+	}))
+	defer server.Close()
+
+	var persisted string
+	s := NewRefreshTokenSource("app", "secret", "refresh1")
+	s.TokenURL = server.URL
+	s.OnRefresh = func(t *Token) {
+		persisted = t.RefreshToken
+	}
+	for i := 0; i < 2; i++ {
+		tok, err := s.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "access1" {
+			t.Errorf("Unexpected access token %s", tok.AccessToken)
+		}
+	}
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("Expected the token to be cached, refreshed %d times", n)
+	}
+	if persisted != "refresh2" {
+		t.Errorf("Expected the rotated refresh token to be passed to OnRefresh, got %s", persisted)
+	}
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token() (*Token, error) {
+	return &Token{AccessToken: string(s)}, nil
+}
+
+func TestClient_Auth(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access1" {
+			t.Errorf("Unexpected authorization %s", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("api_key") != "" {
+			t.Error("Did not expect api_key on v3 requests")
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": [], "links": {}}`))
+	})
+	client.PrivateKey = ""
+	client.Auth = &OAuthAuth{Source: staticTokenSource("access1")}
+	if _, err := client.GetMetrics(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The legacy endpoints only work with a private key.
+	if _, err := client.GetPerson("abc"); err != ErrNoPrivateKey {
+		t.Errorf("Expected ErrNoPrivateKey, got %v", err)
+	}
+}
+
+func TestClient_SecondaryPrivateKey(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("api_key")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			key = auth[len("Klaviyo-API-Key "):]
+		}
+		if key != "new" {
+			w.Header().Set("Content-Type", ContentJSON)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"detail": "revoked"}`))
+			return
+		}
+		if r.URL.Path == "/api/metrics" {
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": [], "links": {}}`))
+			return
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"object": "person", "id": "abc"}`))
+	})
+	client.PrivateKey = "old"
+	client.SecondaryPrivateKey = "new"
+	var events []KeyRotationEvent
+	client.OnKeyRotation = func(e KeyRotationEvent) {
+		events = append(events, e)
+	}
+
+	if _, err := client.GetPerson("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMetrics(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 rotation events, got %d", len(events))
+	}
+	if events[0].Path != "/api/v1/person/abc" || events[0].PrimaryErr == nil || events[0].Err != nil {
+		t.Errorf("Unexpected event %+v", events[0])
+	}
+
+	client.SecondaryPrivateKey = "older"
+	if _, err := client.GetPerson("abc"); !isAuthError(err) {
+		t.Errorf("Expected the error of the secondary key, got %v", err)
+	}
+	if len(events) != 3 || events[2].Err == nil {
+		t.Errorf("Expected a failed rotation event, got %+v", events)
+	}
+}
@@ -0,0 +1,68 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_IdentifyBatch(t *testing.T) {
+	var sent int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Data struct {
+				Type       string `json:"type"`
+				Attributes struct {
+					Profiles struct {
+						Data []Resource `json:"data"`
+					} `json:"profiles"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Data.Type != "profile-bulk-import-job" {
+			t.Errorf("Unexpected type %s", doc.Data.Type)
+		}
+		sent += len(doc.Data.Attributes.Profiles.Data)
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"data": {"type": "profile-bulk-import-job", "id": "JOB1"}}`))
+	})
+
+	a := newTestPerson()
+	b := newTestPerson()
+	b.Email, b.PhoneNumber = "", ""
+	c := newTestPerson()
+	c.Attributes = Attributes{"$bad": true}
+	ids, err := client.IdentifyBatch([]Person{a, b, c})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError, got %v", err)
+	}
+	if len(batchErr.Errors) != 2 || batchErr.Errors[1] != ErrNoProfileIdentifier || batchErr.Errors[2] == nil {
+		t.Errorf("Unexpected errors %v", batchErr.Errors)
+	}
+	if len(ids) != 1 || ids[0] != "JOB1" {
+		t.Errorf("Unexpected job ids %v", ids)
+	}
+	if sent != 1 {
+		t.Errorf("Expected 1 profile to be sent, got %d", sent)
+	}
+
+	// Large inputs are split into multiple jobs.
+	sent = 0
+	people := make([]Person, maxImportJobProfiles+1)
+	for i := range people {
+		people[i] = Person{Email: "kitty@monstercat.com"}
+	}
+	ids, err = client.IdentifyBatch(people)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || sent != len(people) {
+		t.Errorf("Expected 2 jobs with %d profiles, got %d jobs with %d", len(people), len(ids), sent)
+	}
+}
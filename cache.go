@@ -0,0 +1,107 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = time.Minute
+
+// Cache stores raw responses of read calls, see Client.Cache. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// MemoryCache is a Cache which keeps entries in memory until they expire. Expired entries are removed when they are
+// read or when Set is called, so it does not grow past the number of keys used within a TTL. The zero value is ready
+// to use.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	sets    int
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Expired entries are swept every this many sets.
+const memoryCacheSweepInterval = 1000
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = map[string]memoryCacheEntry{}
+	}
+	now := time.Now()
+	if m.sets++; m.sets%memoryCacheSweepInterval == 0 {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	m.entries[key] = memoryCacheEntry{value: value, expires: now.Add(ttl)}
+}
+
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+func (c *Client) cacheTTL() time.Duration {
+	if c.CacheTTL > 0 {
+		return c.CacheTTL
+	}
+	return defaultCacheTTL
+}
+
+// Decodes the cached response for key into out, or calls fetch and caches what it returns. fetch decodes into the
+// value it is given.
+func (c *Client) cached(key string, out interface{}, fetch func(out interface{}) error) error {
+	if c.Cache == nil {
+		return fetch(out)
+	}
+	if xs, ok := c.Cache.Get(key); ok {
+		return json.Unmarshal(xs, out)
+	}
+	var raw json.RawMessage
+	if err := fetch(&raw); err != nil {
+		return err
+	}
+	// Dry runs do not return anything worth caching.
+	if len(raw) > 0 {
+		c.Cache.Set(key, raw, c.cacheTTL())
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (c *Client) uncache(key string) {
+	if c.Cache != nil {
+		c.Cache.Delete(key)
+	}
+}
+
+func personCacheKey(personId string) string {
+	return "person:" + personId
+}
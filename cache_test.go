@@ -0,0 +1,63 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	var c MemoryCache
+	if _, ok := c.Get("a"); ok {
+		t.Error("Did not expect a value in an empty cache")
+	}
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), -time.Second)
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("Unexpected value %s", v)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expired entries should not be returned")
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("Deleted entries should not be returned")
+	}
+}
+
+func TestClient_Cache(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"object": "person", "id": "abc", "$email": "kitty@monstercat.com", "Plan": "gold"}`))
+		case http.MethodPut:
+			w.Write([]byte(`{"object": "person", "id": "abc", "$email": "kitty@monstercat.com", "Plan": "silver"}`))
+		}
+	})
+	client.Cache = &MemoryCache{}
+	for i := 0; i < 2; i++ {
+		p, err := client.GetPerson("abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Id != "abc" || p.Email != "kitty@monstercat.com" || p.Attributes["Plan"] != "gold" {
+			t.Errorf("Unexpected person %+v", p)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Expected the second call to be cached, got %d requests", hits)
+	}
+
+	if err := client.UpdatePerson(&Person{Object: Object{Id: "abc"}, Attributes: Attributes{"Plan": "silver"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetPerson("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected UpdatePerson to clear the cached person, got %d requests", hits)
+	}
+}
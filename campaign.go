@@ -0,0 +1,156 @@
+package klaviyo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Klaviyo's maximum page size for campaign recipients.
+const campaignRecipientsPageSize = 25000
+
+type CampaignRecipient struct {
+	CustomerId string `json:"customer_id"`
+	Email      string `json:"email"`
+
+	// e.g. Sent, Bounced or Skipped
+	Status string `json:"status"`
+}
+
+// https://apidocs.klaviyo.com/reference/campaigns#get-campaign-recipients
+// GET https://a.klaviyo.com/api/v1/campaign/campaign_id/recipients
+// Returns everyone a sent campaign went out to, going through all the pages.
+func (c *Client) GetCampaignRecipients(campaignId string) ([]CampaignRecipient, error) {
+	var recipients []CampaignRecipient
+	var offset string
+	for {
+		u := newEndpoint(EndpointV1, fmt.Sprintf("campaign/%s/recipients", campaignId))
+		values := u.Query()
+		values.Add("count", fmt.Sprint(campaignRecipientsPageSize))
+		if offset != "" {
+			values.Add("offset", offset)
+		}
+		u.RawQuery = values.Encode()
+		var res struct {
+			Data       []CampaignRecipient `json:"data"`
+			NextOffset string              `json:"next_offset"`
+		}
+		if err := c.send(http.MethodGet, ContentJSON, u, &res); err != nil {
+			return recipients, err
+		}
+		recipients = append(recipients, res.Data...)
+		if res.NextOffset == "" || len(res.Data) == 0 {
+			return recipients, nil
+		}
+		offset = res.NextOffset
+	}
+}
+
+type Campaign struct {
+	Object
+	Name      string `json:"name"`
+	Subject   string `json:"subject"`
+	FromEmail string `json:"from_email"`
+	FromName  string `json:"from_name"`
+	Lists     []List `json:"lists"`
+
+	// e.g. draft, scheduled or sent
+	Status        string `json:"status"`
+	NumRecipients KInt   `json:"num_recipients"`
+	SendTime      KTime  `json:"send_time"`
+	SentAt        KTime  `json:"sent_at"`
+	Created       KTime  `json:"created"`
+	Updated       KTime  `json:"updated"`
+
+	Template struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"template"`
+}
+
+// https://apidocs.klaviyo.com/reference/campaigns#create-campaign
+// POST https://a.klaviyo.com/api/v1/campaigns
+// Creates a draft campaign sending the template to everyone on the list. Nothing is sent until the campaign is
+// scheduled or sent from Klaviyo.
+func (c *Client) CreateCampaign(listId, templateId, subject, fromEmail, fromName, name string) (*Campaign, error) {
+	u := newEndpoint(EndpointV1, "campaigns")
+	values := url.Values{}
+	values.Set("list_id", listId)
+	values.Set("template_id", templateId)
+	values.Set("subject", subject)
+	values.Set("from_email", fromEmail)
+	values.Set("from_name", fromName)
+	values.Set("name", name)
+
+	var res Campaign
+	err := c.sendForm(http.MethodPost, ContentJSON, u, values, &res)
+	return &res, err
+}
+
+type CampaignStats struct {
+	CampaignId string
+
+	Recipients   int
+	Opens        int
+	UniqueOpens  int
+	Clicks       int
+	UniqueClicks int
+}
+
+// Fraction of recipients who opened the campaign at least once.
+func (s *CampaignStats) OpenRate() float64 {
+	if s.Recipients == 0 {
+		return 0
+	}
+	return float64(s.UniqueOpens) / float64(s.Recipients)
+}
+
+// Fraction of recipients who clicked a link in the campaign at least once.
+func (s *CampaignStats) ClickRate() float64 {
+	if s.Recipients == 0 {
+		return 0
+	}
+	return float64(s.UniqueClicks) / float64(s.Recipients)
+}
+
+// Counts the sends, opens and clicks of a campaign between start and end using the metric aggregates endpoint. Opens
+// and clicks keep coming in long after a campaign was sent so end should be well past the send time.
+func (c *Client) GetCampaignStats(campaignId string, start, end time.Time) (*CampaignStats, error) {
+	ids, err := c.getMetricIds(MetricReceivedEmail, MetricOpenedEmail, MetricClickedEmail)
+	if err != nil {
+		return nil, err
+	}
+	stats := &CampaignStats{CampaignId: campaignId}
+	for _, m := range []struct {
+		name   string
+		count  *int
+		unique *int
+	}{
+		{MetricReceivedEmail, &stats.Recipients, nil},
+		{MetricOpenedEmail, &stats.Opens, &stats.UniqueOpens},
+		{MetricClickedEmail, &stats.Clicks, &stats.UniqueClicks},
+	} {
+		id, ok := ids[m.name]
+		if !ok {
+			// The metric only exists once something was recorded for it.
+			continue
+		}
+		agg, err := c.QueryMetricAggregates(&MetricAggregateQuery{
+			MetricId:     id,
+			Measurements: []Measurement{MeasurementCount, MeasurementUnique},
+			Interval:     IntervalMonth,
+			Start:        start,
+			End:          end,
+			Filter:       []FilterBuilder{Equals(DimensionMessage, campaignId)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		*m.count = int(agg.Total(MeasurementCount))
+		if m.unique != nil {
+			*m.unique = int(agg.Total(MeasurementUnique))
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,127 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_CreateCampaign(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/campaigns" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("list_id") != "LIST1" || r.PostForm.Get("template_id") != "TEMPLATE1" ||
+			r.PostForm.Get("subject") != "Out now" || r.PostForm.Get("from_email") != "dev@monstercat.com" ||
+			r.PostForm.Get("from_name") != "Monstercat" || r.PostForm.Get("name") != "Release 001" {
+			t.Errorf("Unexpected form %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"object": "campaign", "id": "CAMP1", "name": "Release 001", "subject": "Out now",
+			"from_email": "dev@monstercat.com", "from_name": "Monstercat", "status": "draft", "num_recipients": 0,
+			"lists": [{"object": "list", "id": "LIST1", "name": "Announcements"}],
+			"template": {"object": "email-template", "id": "TEMPLATE1", "name": "Release"},
+			"created": "2022-01-02 03:04:05", "updated": "2022-01-02 03:04:05"}`))
+	})
+	campaign, err := client.CreateCampaign("LIST1", "TEMPLATE1", "Out now", "dev@monstercat.com", "Monstercat", "Release 001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if campaign.Id != "CAMP1" || campaign.Status != "draft" || campaign.Template.Id != "TEMPLATE1" {
+		t.Errorf("Unexpected campaign %+v", campaign)
+	}
+	if len(campaign.Lists) != 1 || campaign.Lists[0].Id != "LIST1" {
+		t.Errorf("Unexpected lists %+v", campaign.Lists)
+	}
+	if campaign.Created.IsZero() {
+		t.Error("Expected the created time to be parsed")
+	}
+}
+
+func TestClient_GetCampaignRecipients(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/campaign/CAMP1/recipients" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("offset") {
+		case "":
+			w.Write([]byte(`{"data": [{"customer_id": "abc", "email": "kitty@monstercat.com", "status": "Sent"}], "next_offset": "xyz"}`))
+		case "xyz":
+			w.Write([]byte(`{"data": [{"customer_id": "def", "email": "cat@monstercat.com", "status": "Bounced"}]}`))
+		default:
+			t.Errorf("Unexpected offset %s", r.URL.Query().Get("offset"))
+		}
+	})
+	recipients, err := client.GetCampaignRecipients("CAMP1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 2 || recipients[1].Status != "Bounced" {
+		t.Errorf("Unexpected recipients %+v", recipients)
+	}
+}
+
+func TestClient_GetCampaignStats(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Klaviyo-API-Key private" {
+			t.Errorf("Expected the main account's key, got %s", auth)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch r.URL.Path {
+		case "/api/metrics":
+			w.Write([]byte(`{"data": [
+				{"type": "metric", "id": "RECV", "attributes": {"name": "Received Email", "integration": {"name": "Klaviyo"}}},
+				{"type": "metric", "id": "OPEN", "attributes": {"name": "Opened Email", "integration": {"name": "Klaviyo"}}},
+				{"type": "metric", "id": "OTHER", "attributes": {"name": "Placed Order", "integration": {"name": "Shopify"}}}
+			], "links": {}}`))
+		case "/api/metric-aggregates":
+			var doc struct {
+				Data struct {
+					Attributes struct {
+						MetricId string   `json:"metric_id"`
+						Filter   []string `json:"filter"`
+					} `json:"attributes"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			attrs := doc.Data.Attributes
+			if len(attrs.Filter) != 3 || attrs.Filter[2] != `equals($message,"CAMP1")` {
+				t.Errorf("Unexpected filter %v", attrs.Filter)
+			}
+			switch attrs.MetricId {
+			case "RECV":
+				w.Write([]byte(`{"data": {"type": "metric-aggregate", "attributes": {"dates": ["2023-01-01T00:00:00+00:00"], "data": [{"dimensions": [], "measurements": {"count": [100], "unique": [100]}}]}}}`))
+			case "OPEN":
+				w.Write([]byte(`{"data": {"type": "metric-aggregate", "attributes": {"dates": ["2023-01-01T00:00:00+00:00", "2023-02-01T00:00:00+00:00"], "data": [{"dimensions": [], "measurements": {"count": [40, 10], "unique": [20, 5]}}]}}}`))
+			default:
+				t.Errorf("Unexpected metric %s", attrs.MetricId)
+			}
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := CampaignStats{CampaignId: "CAMP1", Recipients: 100, Opens: 50, UniqueOpens: 25}
+	for _, mode := range []string{"live", "dry run", "test mode"} {
+		// Aggregates are read from the account the metric ids came from, whatever the mode.
+		client.DryRun = mode == "dry run"
+		client.TestMode = mode == "test mode"
+		stats, err := client.GetCampaignStats("CAMP1", start, start.AddDate(0, 2, 0))
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if *stats != expected {
+			t.Errorf("%s: expected %+v, got %+v", mode, expected, *stats)
+		}
+		if stats.OpenRate() != 0.25 {
+			t.Errorf("%s: unexpected open rate %f", mode, stats.OpenRate())
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package klaviyo
+
+// Channels Klaviyo sends messages through, used by flows, campaigns and subscriptions.
+type MessageChannel string
+
+const (
+	ChannelEmail MessageChannel = "email"
+	ChannelSMS   MessageChannel = "sms"
+	ChannelPush  MessageChannel = "push"
+)
+
+// Returns the channel the consent applies to, e.g. ChannelSMS for ConsentSMSTransactional. Empty for an unknown
+// consent channel.
+func (ch ConsentChannel) Channel() MessageChannel {
+	return consentChannelKeys[ch].channel
+}
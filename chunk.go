@@ -0,0 +1,38 @@
+package klaviyo
+
+import "sync"
+
+// Calls fn for every chunk of at most size items out of n, running up to concurrency chunks at the same time. i is the
+// index of the chunk and the items are [start, end). Returns the error of each chunk in order.
+func runChunks(n, size, concurrency int, fn func(i, start, end int) error) []error {
+	if size <= 0 {
+		size = n
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var chunks int
+	if n > 0 {
+		chunks = (n + size - 1) / size
+	}
+	errs := make([]error, chunks)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < chunks; i++ {
+		start, end := i*size, (i+1)*size
+		if end > n {
+			end = n
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i, start, end int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i, start, end)
+		}(i, start, end)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,37 @@
+package klaviyo
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRunChunks(t *testing.T) {
+	var mu sync.Mutex
+	var chunks [][2]int
+	errs := runChunks(7, 3, 2, func(i, start, end int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for len(chunks) <= i {
+			chunks = append(chunks, [2]int{})
+		}
+		chunks[i] = [2]int{start, end}
+		if i == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(chunks, [][2]int{{0, 3}, {3, 6}, {6, 7}}) {
+		t.Errorf("Unexpected chunks %v", chunks)
+	}
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+	if errs := runChunks(0, 3, 1, func(i, start, end int) error {
+		t.Error("Expected no chunks")
+		return nil
+	}); len(errs) != 0 {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+}
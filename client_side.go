@@ -0,0 +1,68 @@
+package klaviyo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Requests to the client-side endpoints are authenticated by the company_id parameter instead of the private key.
+func isClientSide(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/client/")
+}
+
+// Returns the URL of a client-side endpoint with the public key set as company_id.
+func (c *Client) clientSideEndpoint(uri string) (*url.URL, error) {
+	publicKey, err := c.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	// Klaviyo redirects these endpoints when the trailing slash is missing.
+	u := newEndpoint(EndpointClient, uri)
+	u.Path += "/"
+	values := u.Query()
+	values.Set("company_id", publicKey)
+	u.RawQuery = values.Encode()
+	return u, nil
+}
+
+// https://developers.klaviyo.com/en/reference/create_client_event
+// POST https://a.klaviyo.com/client/events/
+// Same as CreateEvent but only needs the public key, so it can be used by services which should not hold the private
+// key, such as edge workers.
+func (c *Client) CreateClientEvent(e *NewEvent) error {
+	e, err := c.checkEvent(e)
+	if err != nil {
+		return err
+	}
+	u, err := c.clientSideEndpoint("events")
+	if err != nil {
+		return err
+	}
+	return c.sendV3(http.MethodPost, u, &document{Data: e.resource()}, nil)
+}
+
+// https://developers.klaviyo.com/en/reference/create_client_profile
+// POST https://a.klaviyo.com/client/profiles/
+// Creates or updates a profile with only the public key, like Identify. Empty values are left out.
+func (c *Client) CreateClientProfile(person *Person) error {
+	if !person.HasProfileIdentifier() {
+		return ErrNoProfileIdentifier
+	}
+	p, err := c.checkAttributes(person)
+	if err != nil {
+		return err
+	}
+	p = c.withDefaultAttributes(p)
+	u, err := c.clientSideEndpoint("profiles")
+	if err != nil {
+		return err
+	}
+	doc := &document{
+		Data: map[string]interface{}{
+			"type":       "profile",
+			"attributes": p.profileAttributes(),
+		},
+	}
+	return c.sendV3(http.MethodPost, u, doc, nil)
+}
@@ -0,0 +1,69 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_CreateClientEvent(t *testing.T) {
+	var body map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/client/events/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("company_id") != "public" {
+			t.Errorf("Expected the public key as company_id, got %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("api_key") != "" || r.Header.Get("Authorization") != "" {
+			t.Error("Expected the private key not to be sent")
+		}
+		if r.Header.Get("Revision") == "" {
+			t.Error("Expected a revision header")
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	client.PrivateKey = ""
+
+	e := &NewEvent{Metric: "Viewed Product", Person: &Person{Email: "kitty@monstercat.com"}}
+	if err := client.CreateClientEvent(e); err != nil {
+		t.Fatal(err)
+	}
+	data := body["data"].(map[string]interface{})
+	if data["type"] != "event" {
+		t.Errorf("Unexpected body %v", body)
+	}
+	if err := client.CreateClientEvent(&NewEvent{Person: e.Person}); err != ErrNoMetricName {
+		t.Errorf("Expected ErrNoMetricName, got %v", err)
+	}
+	client.PublicKey = ""
+	if err := client.CreateClientEvent(e); err != ErrNoPublicKey {
+		t.Errorf("Expected ErrNoPublicKey, got %v", err)
+	}
+}
+
+func TestClient_CreateClientProfile(t *testing.T) {
+	var body map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/client/profiles/" || r.URL.Query().Get("company_id") != "test-public" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	client.PrivateKey = ""
+	client.TestMode = true
+	client.TestPublicKey = "test-public"
+
+	if err := client.CreateClientProfile(&Person{Email: "kitty@monstercat.com"}); err != nil {
+		t.Fatal(err)
+	}
+	attrs := body["data"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if attrs["email"] != "kitty@monstercat.com" {
+		t.Errorf("Unexpected attributes %v", attrs)
+	}
+	if err := client.CreateClientProfile(&Person{}); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+}
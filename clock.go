@@ -0,0 +1,52 @@
+package klaviyo
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is how the client tells the time and waits between retries, rate limited pages and polls. Tests can set
+// Client.Clock to a fake which records the waits and returns right away, to check backoff timing or go through a storm
+// of 429 responses without real sleeps.
+type Clock interface {
+	Now() time.Time
+
+	// Waits for d, returning the context's error early if it is done first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// The real time, used when Client.Clock is nil.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) clock() Clock {
+	if c.Clock == nil {
+		return systemClock{}
+	}
+	return c.Clock
+}
+
+type clockKey struct{}
+
+// Returns the Clock of the client which sent a request with ctx, for middleware such as Scheduler. The real time for
+// requests not sent by a client with a Clock.
+func clockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return systemClock{}
+}
@@ -0,0 +1,86 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Moves its time forward instead of sleeping and remembers every wait.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waits = append(f.waits, d)
+	f.now = f.now.Add(d)
+	return ctx.Err()
+}
+
+func TestClient_ClockBackoff(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client.Clock = clock
+	client.MaxRetries = 3
+	client.RetryBackoff = time.Minute
+
+	start := time.Now()
+	if _, err := client.GetList(testListId); !isServerError(err) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+	if fmt.Sprint(clock.waits) != "[1m0s 2m0s 4m0s]" || time.Since(start) > time.Second {
+		t.Errorf("Unexpected waits %v", clock.waits)
+	}
+
+	// The budget is counted on the clock too.
+	clock.waits = nil
+	client.RetryBudget = 2 * time.Minute
+	var budgetErr *RetryBudgetError
+	if _, err := client.GetList(testListId); !errors.As(err, &budgetErr) || budgetErr.Elapsed != time.Minute {
+		t.Errorf("Expected the budget to run out after a minute, got %v", err)
+	}
+}
+
+func TestClient_ClockRateLimitStorm(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits <= 5 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"records": [{"id": "abc"}]}`))
+	})
+	clock := &fakeClock{now: time.Now()}
+	client.Clock = clock
+
+	var members int
+	err := client.EachGroupMember(context.Background(), testListId, func(ListPerson) error {
+		members++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if members != 1 || len(clock.waits) != 5 || clock.waits[4] != 30*time.Second {
+		t.Errorf("Expected 5 waits of 30s, got %v", clock.waits)
+	}
+}
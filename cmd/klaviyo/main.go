@@ -0,0 +1,145 @@
+// Command klaviyo runs one-off Klaviyo operations through the same code paths as our services. Results are printed to
+// stdout as JSON.
+//
+//	klaviyo person get <person_id>
+//	klaviyo list members [-format ndjson|csv] <list_id>
+//	klaviyo subscribe [-email a@b.com] [-phone +1234567890] <list_id>
+//	klaviyo track [-email a@b.com] [-phone +1234567890] [-id custom_id] [-properties '{"a": 1}'] [-value 9.99] <metric>
+//
+// Keys are read from the KLAVIYO_PUBLIC_KEY and KLAVIYO_PRIVATE_KEY environment variables.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/monstercat/go-klaviyo"
+)
+
+const usage = `usage:
+  klaviyo person get <person_id>
+  klaviyo list members [-format ndjson|csv] <list_id>
+  klaviyo subscribe [-email address]... [-phone number]... <list_id>
+  klaviyo track [-email address] [-phone number] [-id custom_id] [-properties json] [-value n] <metric>`
+
+var ErrUsage = errors.New(usage)
+
+func main() {
+	client := &klaviyo.Client{
+		PublicKey:      os.Getenv("KLAVIYO_PUBLIC_KEY"),
+		PrivateKey:     os.Getenv("KLAVIYO_PRIVATE_KEY"),
+		DefaultTimeout: 30 * time.Second,
+	}
+	if err := run(client, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(client *klaviyo.Client, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "person":
+		if len(args) != 3 || args[1] != "get" {
+			return ErrUsage
+		}
+		p, err := client.GetPerson(args[2])
+		if err != nil {
+			return err
+		}
+		return writeJSON(out, p)
+	case "list":
+		if len(args) < 2 || args[1] != "members" {
+			return ErrUsage
+		}
+		return listMembers(client, args[2:], out)
+	case "subscribe":
+		return subscribe(client, args[1:], out)
+	case "track":
+		return track(client, args[1:], out)
+	}
+	return ErrUsage
+}
+
+func listMembers(client *klaviyo.Client, args []string, out io.Writer) error {
+	fs := newFlagSet("list members")
+	format := fs.String("format", string(klaviyo.ExportNDJSON), "ndjson or csv")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return ErrUsage
+	}
+	return client.ExportGroupMembers(out, fs.Arg(0), klaviyo.ExportFormat(*format))
+}
+
+func subscribe(client *klaviyo.Client, args []string, out io.Writer) error {
+	fs := newFlagSet("subscribe")
+	var emails, phones stringsFlag
+	fs.Var(&emails, "email", "email address to subscribe, can be repeated")
+	fs.Var(&phones, "phone", "phone number to subscribe, can be repeated")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || len(emails)+len(phones) == 0 {
+		return ErrUsage
+	}
+	res, err := client.Subscribe(fs.Arg(0), emails, phones)
+	if err != nil {
+		return err
+	}
+	return writeJSON(out, res)
+}
+
+func track(client *klaviyo.Client, args []string, out io.Writer) error {
+	fs := newFlagSet("track")
+	person := &klaviyo.Person{}
+	fs.StringVar(&person.Email, "email", "", "email of the person")
+	fs.StringVar(&person.PhoneNumber, "phone", "", "phone number of the person")
+	fs.StringVar(&person.CustomId, "id", "", "custom id of the person")
+	properties := fs.String("properties", "", "event properties as a JSON object")
+	value := fs.Float64("value", 0, "monetary value of the event")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return ErrUsage
+	}
+	event := &klaviyo.NewEvent{
+		Metric: fs.Arg(0),
+		Person: person,
+		Value:  *value,
+	}
+	if *properties != "" {
+		if err := json.Unmarshal([]byte(*properties), &event.Properties); err != nil {
+			return fmt.Errorf("invalid properties: %w", err)
+		}
+	}
+	if err := client.CreateEvent(event); err != nil {
+		return err
+	}
+	return writeJSON(out, map[string]bool{"ok": true})
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Collects every value of a flag which can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/monstercat/go-klaviyo"
+)
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newMockClient(t *testing.T, handler http.HandlerFunc) *klaviyo.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &klaviyo.Client{
+		PublicKey:      "public",
+		PrivateKey:     "private",
+		DefaultTimeout: time.Second,
+		Transport:      &rewriteTransport{target: target},
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s", r.URL)
+	})
+	for _, args := range [][]string{
+		nil,
+		{"person"},
+		{"person", "delete", "abc"},
+		{"list", "members"},
+		{"subscribe", "LIST1"},
+		{"track", "-value", "x", "Placed Order"},
+		{"unknown"},
+	} {
+		if err := run(client, args, &bytes.Buffer{}); err != ErrUsage {
+			t.Errorf("Expected usage error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestRun_PersonGet(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/person/abc" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object": "person", "id": "abc", "$email": "kitty@monstercat.com"}`))
+	})
+	out := &bytes.Buffer{}
+	if err := run(client, []string{"person", "get", "abc"}, out); err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["$email"] != "kitty@monstercat.com" {
+		t.Errorf("Unexpected output %s", out)
+	}
+}
+
+func TestRun_Subscribe(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var p struct {
+			Profiles []map[string]interface{} `json:"profiles"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Profiles) != 3 {
+			t.Errorf("Expected 3 profiles, got %d", len(p.Profiles))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	args := []string{"subscribe", "-email", "a@monstercat.com", "-email", "b@monstercat.com", "-phone", "+1234567890", "LIST1"}
+	if err := run(client, args, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Track(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Data struct {
+				Attributes struct {
+					Properties map[string]interface{} `json:"properties"`
+					Value      float64                `json:"value"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Data.Attributes.Properties["sku"] != "MCS123" || doc.Data.Attributes.Value != 9.99 {
+			t.Errorf("Unexpected event %+v", doc.Data.Attributes)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	args := []string{"track", "-email", "kitty@monstercat.com", "-properties", `{"sku": "MCS123"}`, "-value", "9.99", "Placed Order"}
+	if err := run(client, args, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,212 @@
+package klaviyo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A profile in the shape most CRMs expect, see Person.Contact. Every value is trimmed and normalized so it can be
+// compared with what the CRM already holds.
+type Contact struct {
+	// The Klaviyo profile id and the profile's $id.
+	Id         string
+	ExternalId string
+
+	// Lowercased.
+	Email string
+
+	FirstName    string
+	LastName     string
+	Organization string
+	Title        string
+
+	// The phone number first followed by the ones in ContactMapping.PhoneAttributes, keeping only digits and a
+	// leading +. Duplicates are left out.
+	Phones []string
+
+	Address ContactAddress
+
+	Timezone string
+
+	// Custom attributes, renamed by ContactMapping.Fields.
+	Fields map[string]interface{}
+}
+
+type ContactAddress struct {
+	Street     []string
+	City       string
+	Region     string
+	PostalCode string
+
+	// Uppercased when it is a two letter code.
+	Country string
+}
+
+func (a *ContactAddress) IsZero() bool {
+	return len(a.Street) == 0 && a.City == "" && a.Region == "" && a.PostalCode == "" && a.Country == ""
+}
+
+// How custom attributes end up in a Contact.
+type ContactMapping struct {
+	// Custom attribute name to the name of the field in Contact.Fields, e.g. {"plan": "Plan__c"} for Salesforce.
+	Fields map[string]string
+
+	// Copy custom attributes which are not in Fields under their own name, otherwise they are left out.
+	IncludeUnmapped bool
+
+	// Custom attributes holding extra phone numbers, e.g. "work_phone".
+	PhoneAttributes []string
+}
+
+// Converts p to a Contact. m may be nil, in which case no custom attributes are copied.
+func (p *Person) Contact(m *ContactMapping) *Contact {
+	if m == nil {
+		m = &ContactMapping{}
+	}
+	c := &Contact{
+		Id:           p.Id,
+		ExternalId:   strings.TrimSpace(p.CustomId),
+		Email:        strings.ToLower(strings.TrimSpace(p.Email)),
+		FirstName:    strings.TrimSpace(p.FirstName),
+		LastName:     strings.TrimSpace(p.LastName),
+		Organization: strings.TrimSpace(p.Organization),
+		Title:        strings.TrimSpace(p.Title),
+		Timezone:     strings.TrimSpace(p.Timezone),
+		Address: ContactAddress{
+			City:       strings.TrimSpace(p.City),
+			Region:     strings.TrimSpace(p.Region),
+			PostalCode: strings.ToUpper(strings.TrimSpace(p.Zip)),
+			Country:    normalizeCountry(p.Country),
+		},
+	}
+	for _, line := range []string{p.Address1, p.Address2} {
+		if line = strings.TrimSpace(line); line != "" {
+			c.Address.Street = append(c.Address.Street, line)
+		}
+	}
+
+	phones := []string{p.PhoneNumber}
+	for _, k := range m.PhoneAttributes {
+		phones = append(phones, p.Attributes.ParseString(k))
+	}
+	seen := map[string]bool{}
+	for _, phone := range phones {
+		if phone = normalizePhone(phone); phone != "" && !seen[phone] {
+			seen[phone] = true
+			c.Phones = append(c.Phones, phone)
+		}
+	}
+
+	for k, v := range p.Attributes {
+		if name, ok := m.Fields[k]; ok {
+			c.setField(name, v)
+		} else if m.IncludeUnmapped {
+			c.setField(k, v)
+		}
+	}
+	return c
+}
+
+func (c *Contact) setField(name string, v interface{}) {
+	if c.Fields == nil {
+		c.Fields = map[string]interface{}{}
+	}
+	c.Fields[name] = v
+}
+
+// Returns the first and last name separated by a space, or the email when there is no name.
+func (c *Contact) FullName() string {
+	if name := strings.TrimSpace(c.FirstName + " " + c.LastName); name != "" {
+		return name
+	}
+	return c.Email
+}
+
+// Encodes the contact as a vCard 4.0, e.g. to import into an address book. Custom fields are added as X- properties
+// in alphabetical order.
+func (c *Contact) VCard() string {
+	var sb strings.Builder
+	line := func(name string, values ...string) {
+		for i, v := range values {
+			values[i] = escapeVCard(v)
+		}
+		sb.WriteString(name + ":" + strings.Join(values, ";") + "\r\n")
+	}
+	line("BEGIN", "VCARD")
+	line("VERSION", "4.0")
+	line("FN", c.FullName())
+	line("N", c.LastName, c.FirstName, "", "", "")
+	if c.Id != "" {
+		line("UID", "klaviyo:"+c.Id)
+	}
+	if c.Email != "" {
+		line("EMAIL", c.Email)
+	}
+	for _, phone := range c.Phones {
+		line("TEL;VALUE=uri", "tel:"+phone)
+	}
+	if c.Organization != "" {
+		line("ORG", c.Organization)
+	}
+	if c.Title != "" {
+		line("TITLE", c.Title)
+	}
+	if !c.Address.IsZero() {
+		a := c.Address
+		line("ADR", "", "", strings.Join(a.Street, ", "), a.City, a.Region, a.PostalCode, a.Country)
+	}
+	if c.Timezone != "" {
+		line("TZ", c.Timezone)
+	}
+	keys := make([]string, 0, len(c.Fields))
+	for k := range c.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		line("X-"+vCardName(k), fmt.Sprint(c.Fields[k]))
+	}
+	line("END", "VCARD")
+	return sb.String()
+}
+
+// Keeps the digits of a phone number along with a leading +.
+func normalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var sb strings.Builder
+	for i, r := range phone {
+		if unicode.IsDigit(r) || (r == '+' && i == 0) {
+			sb.WriteRune(r)
+		}
+	}
+	if s := sb.String(); s != "+" {
+		return s
+	}
+	return ""
+}
+
+func normalizeCountry(country string) string {
+	country = strings.TrimSpace(country)
+	if len(country) == 2 {
+		return strings.ToUpper(country)
+	}
+	return country
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+func escapeVCard(s string) string {
+	return vCardEscaper.Replace(s)
+}
+
+// Property names may only have letters, digits and dashes.
+func vCardName(s string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '-'
+	}, s))
+}
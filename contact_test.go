@@ -0,0 +1,80 @@
+package klaviyo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPerson_Contact(t *testing.T) {
+	p := &Person{
+		Object:      Object{Id: "PROFILE1"},
+		Email:       " Kitty@Monstercat.com ",
+		FirstName:   "Kitty",
+		LastName:    "Cat",
+		PhoneNumber: "+1 (555) 555-5555",
+		Address1:    "1 Main St",
+		Address2:    " ",
+		City:        "Vancouver",
+		Region:      "BC",
+		Zip:         "v6b 1a1",
+		Country:     "ca",
+		Attributes: Attributes{
+			"plan":       "gold",
+			"work_phone": "+1 555-555-5555",
+			"home_phone": "604 555 0000",
+			"internal":   true,
+		},
+	}
+	c := p.Contact(&ContactMapping{
+		Fields:          map[string]string{"plan": "Plan__c"},
+		PhoneAttributes: []string{"work_phone", "home_phone"},
+	})
+	if c.Id != "PROFILE1" || c.Email != "kitty@monstercat.com" || c.FullName() != "Kitty Cat" {
+		t.Errorf("Unexpected contact %+v", c)
+	}
+	if strings.Join(c.Phones, ",") != "+15555555555,6045550000" {
+		t.Errorf("Unexpected phones %v", c.Phones)
+	}
+	a := c.Address
+	if len(a.Street) != 1 || a.PostalCode != "V6B 1A1" || a.Country != "CA" {
+		t.Errorf("Unexpected address %+v", a)
+	}
+	if len(c.Fields) != 1 || c.Fields["Plan__c"] != "gold" {
+		t.Errorf("Expected only the mapped fields, got %v", c.Fields)
+	}
+
+	c = p.Contact(&ContactMapping{IncludeUnmapped: true})
+	if len(c.Fields) != 4 || len(c.Phones) != 1 {
+		t.Errorf("Expected every attribute and one phone, got %v and %v", c.Fields, c.Phones)
+	}
+}
+
+func TestContact_VCard(t *testing.T) {
+	c := &Contact{
+		Id:           "PROFILE1",
+		Email:        "kitty@monstercat.com",
+		FirstName:    "Kitty",
+		LastName:     "Cat",
+		Organization: "Monstercat; Inc",
+		Phones:       []string{"+15555555555"},
+		Address:      ContactAddress{Street: []string{"1 Main St"}, City: "Vancouver", Country: "CA"},
+		Fields:       map[string]interface{}{"plan_tier": "gold"},
+	}
+	expected := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:Kitty Cat",
+		"N:Cat;Kitty;;;",
+		"UID:klaviyo:PROFILE1",
+		"EMAIL:kitty@monstercat.com",
+		"TEL;VALUE=uri:tel:+15555555555",
+		`ORG:Monstercat\; Inc`,
+		"ADR:;;1 Main St;Vancouver;;;CA",
+		"X-PLAN-TIER:gold",
+		"END:VCARD",
+		"",
+	}, "\r\n")
+	if v := c.VCard(); v != expected {
+		t.Errorf("Unexpected vCard\n%s", v)
+	}
+}
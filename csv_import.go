@@ -0,0 +1,178 @@
+package klaviyo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownSpecialField = errors.New("unknown special field")
+
+// How the columns of a CSV file map onto people, see ImportCSV.
+type CSVImportOptions struct {
+	// Column header to where its values go: a special field by its $ key, e.g. "$email" or "$consent", or the name of a
+	// custom attribute. Map a column to "" to skip it. Columns which are not listed keep their header, so files can use
+	// "$email" and custom attribute names as headers directly.
+	Columns map[string]string
+
+	// Separator of the channels in $consent columns, defaults to a comma.
+	ConsentSeparator string
+
+	// Only check the rows, nothing is sent to Klaviyo. The report lists the rows which would fail.
+	ValidateOnly bool
+}
+
+// A row of the CSV file which could not be imported. Row is the line number, the header being line 1.
+type CSVRowError struct {
+	Row int
+	Err error
+}
+
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+type CSVImportReport struct {
+	// Rows read, not counting the header.
+	Rows int
+
+	// In row order.
+	Failed []CSVRowError
+
+	// The import jobs Klaviyo is processing, see IdentifyBatch.
+	JobIds []string
+}
+
+// Reads people from CSV with a header line, see CSVImportOptions for how columns are mapped. Empty cells are left out.
+// Rows which cannot be read are returned as errors instead of people and do not stop the rest. The returned people and
+// rows line up by index, rows holds the line number of each person.
+func ReadPeopleCSV(r io.Reader, opts *CSVImportOptions) (people []Person, rows []int, failed []CSVRowError, err error) {
+	if opts == nil {
+		opts = &CSVImportOptions{}
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	targets := make([]string, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		targets[i] = h
+		if t, ok := opts.Columns[h]; ok {
+			targets[i] = t
+		}
+	}
+	sep := opts.ConsentSeparator
+	if sep == "" {
+		sep = ","
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return people, rows, failed, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return people, rows, failed, err
+			}
+			failed = append(failed, CSVRowError{Row: line, Err: err})
+			continue
+		}
+		p, err := personFromCSV(targets, record, sep)
+		if err != nil {
+			failed = append(failed, CSVRowError{Row: line, Err: err})
+			continue
+		}
+		people = append(people, *p)
+		rows = append(rows, line)
+	}
+}
+
+func personFromCSV(targets, record []string, consentSep string) (*Person, error) {
+	p := &Person{}
+	for i, value := range record {
+		if i >= len(targets) || targets[i] == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		target := targets[i]
+		if !strings.HasPrefix(target, "$") {
+			if p.Attributes == nil {
+				p.Attributes = Attributes{}
+			}
+			p.Attributes[target] = value
+			continue
+		}
+		var data []byte
+		if target == "$consent" {
+			var channels []string
+			for _, ch := range strings.Split(value, consentSep) {
+				if ch = strings.TrimSpace(ch); ch != "" {
+					channels = append(channels, ch)
+				}
+			}
+			data, _ = json.Marshal(channels)
+		} else {
+			data, _ = json.Marshal(value)
+		}
+		ok, err := p.unmarshalSpecialField(target, data)
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownSpecialField, target)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+	}
+	return p, nil
+}
+
+// https://developers.klaviyo.com/en/reference/spawn_bulk_profile_import_job
+// POST https://a.klaviyo.com/api/profile-bulk-import-jobs
+// Creates or updates everyone in a CSV file through IdentifyBatch. Rows which cannot be read or fail the same checks
+// as Identify are reported and skipped, as are rows in import jobs Klaviyo rejected. Set ValidateOnly to get the report
+// without sending anything, e.g. before importing a file someone uploaded. The error is only set when the file itself
+// cannot be read.
+func (c *Client) ImportCSV(r io.Reader, opts *CSVImportOptions) (*CSVImportReport, error) {
+	people, rows, failed, err := ReadPeopleCSV(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	report := &CSVImportReport{Rows: len(people) + len(failed), Failed: failed}
+	if opts != nil && opts.ValidateOnly {
+		for i := range people {
+			if _, err := c.importProfile(&people[i]); err != nil {
+				report.Failed = append(report.Failed, CSVRowError{Row: rows[i], Err: err})
+			}
+		}
+	} else {
+		jobIds, err := c.IdentifyBatch(people)
+		report.JobIds = jobIds
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) {
+			for i, err := range batchErr.Errors {
+				report.Failed = append(report.Failed, CSVRowError{Row: rows[i], Err: err})
+			}
+		} else if err != nil {
+			return report, err
+		}
+	}
+	sort.Slice(report.Failed, func(i, j int) bool {
+		return report.Failed[i].Row < report.Failed[j].Row
+	})
+	return report, nil
+}
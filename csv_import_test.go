@@ -0,0 +1,95 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testCSV = `Email,First Name,$consent,plan,notes
+kitty@monstercat.com,Kitty,"email, sms",gold,skip me
+,Nobody,,silver,
+dog@monstercat.com,Dog,,,
+`
+
+var testCSVColumns = map[string]string{
+	"Email":      "$email",
+	"First Name": "$first_name",
+	"notes":      "",
+}
+
+func TestReadPeopleCSV(t *testing.T) {
+	people, rows, failed, err := ReadPeopleCSV(strings.NewReader(testCSV), &CSVImportOptions{Columns: testCSVColumns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 3 || len(failed) != 0 || rows[2] != 4 {
+		t.Fatalf("Unexpected people %+v, rows %v, failed %v", people, rows, failed)
+	}
+	p := people[0]
+	if p.Email != "kitty@monstercat.com" || p.FirstName != "Kitty" || p.Attributes["plan"] != "gold" {
+		t.Errorf("Unexpected person %+v", p)
+	}
+	if len(p.Consent) != 2 || p.Consent[1] != ConsentSMS {
+		t.Errorf("Unexpected consent %v", p.Consent)
+	}
+	if _, ok := p.Attributes["notes"]; ok {
+		t.Error("Expected skipped columns to be left out")
+	}
+	if people[2].Attributes != nil {
+		t.Errorf("Expected empty cells to be left out, got %v", people[2].Attributes)
+	}
+
+	_, _, failed, err = ReadPeopleCSV(strings.NewReader("$email,$nope\nkitty@monstercat.com,1\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Row != 2 || !errors.Is(&failed[0], ErrUnknownSpecialField) {
+		t.Errorf("Unexpected failures %v", failed)
+	}
+}
+
+func TestClient_ImportCSV(t *testing.T) {
+	var sent int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Data struct {
+				Attributes struct {
+					Profiles struct {
+						Data []Resource `json:"data"`
+					} `json:"profiles"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		sent += len(doc.Data.Attributes.Profiles.Data)
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"data": {"type": "profile-bulk-import-job", "id": "JOB1"}}`))
+	})
+
+	opts := &CSVImportOptions{Columns: testCSVColumns, ValidateOnly: true}
+	report, err := client.ImportCSV(strings.NewReader(testCSV), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 {
+		t.Error("Did not expect anything to be sent when validating")
+	}
+	if report.Rows != 3 || len(report.Failed) != 1 || report.Failed[0].Row != 3 || report.Failed[0].Err != ErrNoProfileIdentifier {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	opts.ValidateOnly = false
+	report, err = client.ImportCSV(strings.NewReader(testCSV), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 || len(report.JobIds) != 1 || len(report.Failed) != 1 || report.Failed[0].Row != 3 {
+		t.Errorf("Unexpected report %+v after sending %d profiles", report, sent)
+	}
+}
@@ -0,0 +1,19 @@
+package klaviyo
+
+// Where an iteration through paginated results is, so long running iterations such as exports can be saved and
+// resumed after a restart instead of starting over. Iterators taking a *Cursor start from it and keep it up to date:
+// while fn is called it points at the page being handled and once the page is done it moves on to the next one.
+// Resuming from a saved cursor therefore handles at most the page it was saved in again. It is JSON encodable.
+type Cursor struct {
+	// Used by v1 endpoints, e.g. EachPersonFrom.
+	Page int `json:"page,omitempty"`
+
+	// Used by v2 endpoints, e.g. EachGroupMemberFrom.
+	Marker int `json:"marker,omitempty"`
+
+	// Used by v3 endpoints, e.g. EachProfileFrom. The page[cursor] of the page.
+	Next string `json:"next,omitempty"`
+
+	// Set once the last page was handled, iterating from a done cursor does nothing.
+	Done bool `json:"done,omitempty"`
+}
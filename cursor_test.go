@@ -0,0 +1,122 @@
+package klaviyo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_EachGroupMemberFrom(t *testing.T) {
+	var markers []string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		marker := r.URL.Query().Get("marker")
+		markers = append(markers, marker)
+		w.Header().Set("Content-Type", ContentJSON)
+		switch marker {
+		case "":
+			w.Write([]byte(`{"records": [{"id": "abc"}], "marker": 123}`))
+		case "123":
+			w.Write([]byte(`{"records": [{"id": "def"}, {"id": "ghi"}]}`))
+		}
+	})
+
+	// Stop in the middle of the second page as if the process was restarted.
+	stop := errors.New("deploy")
+	var cursor Cursor
+	err := client.EachGroupMemberFrom(context.Background(), "LIST1", &cursor, func(p ListPerson) error {
+		if p.Id == "ghi" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || cursor.Marker != 123 || cursor.Done {
+		t.Fatalf("Unexpected cursor %+v after %v", cursor, err)
+	}
+
+	saved, _ := json.Marshal(&cursor)
+	var resumed Cursor
+	if err := json.Unmarshal(saved, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	err = client.EachGroupMemberFrom(context.Background(), "LIST1", &resumed, func(p ListPerson) error {
+		ids = append(ids, p.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "def" || !resumed.Done {
+		t.Errorf("Expected to resume from the second page, got %v and %+v", ids, resumed)
+	}
+	if len(markers) != 3 || markers[2] != "123" {
+		t.Errorf("Unexpected markers %v", markers)
+	}
+
+	if err := client.EachGroupMemberFrom(context.Background(), "LIST1", &resumed, nil); err != nil || len(markers) != 3 {
+		t.Errorf("Expected nothing to be fetched from a done cursor, got %v", err)
+	}
+}
+
+func TestClient_EachPersonFrom(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("page") {
+		case "0":
+			w.Write([]byte(`{"object": "$list", "data": [{"object": "person", "id": "P1"}], "page": 0, "start": 0, "end": 0, "total": 2}`))
+		case "1":
+			w.Write([]byte(`{"object": "$list", "data": [{"object": "person", "id": "P2"}], "page": 1, "start": 1, "end": 1, "total": 2}`))
+		default:
+			t.Errorf("Unexpected page %s", r.URL.Query().Get("page"))
+		}
+	})
+
+	cursor := &Cursor{Page: 1}
+	var ids []string
+	err := client.EachPersonFrom(cursor, 1, func(p *Person) error {
+		ids = append(ids, p.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "P2" || !cursor.Done {
+		t.Errorf("Expected to resume from the second page, got %v and %+v", ids, cursor)
+	}
+}
+
+func TestClient_EachProfileFrom(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch r.URL.Query().Get("page[cursor]") {
+		case "":
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "P1"}], "links": {"next": "https://a.klaviyo.com/api/profiles?page%5Bcursor%5D=NEXT"}}`))
+		case "NEXT":
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "P2"}], "links": {}}`))
+		}
+	})
+
+	stop := errors.New("deploy")
+	var cursor Cursor
+	err := client.EachProfileFrom(nil, &cursor, func(p *Profile) error {
+		if p.Id == "P2" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || cursor.Next != "NEXT" {
+		t.Fatalf("Unexpected cursor %+v after %v", cursor, err)
+	}
+	var ids []string
+	if err := client.EachProfileFrom(nil, &cursor, func(p *Profile) error {
+		ids = append(ids, p.Id)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "P2" || !cursor.Done {
+		t.Errorf("Expected to resume from the second page, got %v and %+v", ids, cursor)
+	}
+}
@@ -0,0 +1,22 @@
+package klaviyo
+
+import (
+	"net/http"
+)
+
+// https://developers.klaviyo.com/en/reference/request_profile_deletion
+// POST https://a.klaviyo.com/api/data-privacy-deletion-jobs
+// Permanently deletes the profile and all of its data. Klaviyo answers with a 202 and no body, deletion happens
+// asynchronously and there is no endpoint to follow its progress, GetPerson fails with a 404 once it is done.
+func (c *Client) DeletePerson(profileId string) error {
+	doc := &document{
+		Data: map[string]interface{}{
+			"type": "data-privacy-deletion-job",
+			"attributes": map[string]interface{}{
+				"profile": relationshipOne{Data: resourceIdentifier{Type: "profile", Id: profileId}},
+			},
+		},
+	}
+	c.uncache(personCacheKey(profileId))
+	return c.sendV3(http.MethodPost, newEndpoint(Endpoint, "data-privacy-deletion-jobs"), doc, nil)
+}
@@ -0,0 +1,38 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_DeletePerson(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Method != http.MethodPost || r.URL.Path != "/api/data-privacy-deletion-jobs" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var doc struct {
+			Data struct {
+				Type       string `json:"type"`
+				Attributes struct {
+					Profile relationshipOne `json:"profile"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Data.Type != "data-privacy-deletion-job" || doc.Data.Attributes.Profile.Data.Id != testPersonId {
+			t.Errorf("Unexpected job %+v", doc.Data)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	if err := client.DeletePerson(testPersonId); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request, got %d", hits)
+	}
+}
@@ -0,0 +1,94 @@
+package klaviyo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Custom profile properties used for e-commerce segmentation, kept up to date through RecordOrder.
+const (
+	PropertyLastOrderDate     = "Last Order Date"
+	PropertyFirstOrderDate    = "First Order Date"
+	PropertyTotalSpend        = "Total Spend"
+	PropertyOrderCount        = "Order Count"
+	PropertyAverageOrderValue = "Average Order Value"
+)
+
+func (p *Person) attributes() Attributes {
+	if p.Attributes == nil {
+		p.Attributes = Attributes{}
+	}
+	return p.Attributes
+}
+
+func (p *Person) LastOrderDate() time.Time {
+	return p.Attributes.ParseTime(PropertyLastOrderDate)
+}
+
+func (p *Person) FirstOrderDate() time.Time {
+	return p.Attributes.ParseTime(PropertyFirstOrderDate)
+}
+
+func (p *Person) TotalSpend() float64 {
+	return p.Attributes.ParseFloat(PropertyTotalSpend)
+}
+
+func (p *Person) OrderCount() int {
+	return p.Attributes.ParseInt(PropertyOrderCount)
+}
+
+func (p *Person) AverageOrderValue() float64 {
+	return p.Attributes.ParseFloat(PropertyAverageOrderValue)
+}
+
+// Updates the e-commerce properties with an order of the given total placed at the given time. Orders older than the
+// last order date do not move it back. Call Identify or UpdatePerson afterwards to save the changes.
+func (p *Person) RecordOrder(total float64, at time.Time) *Person {
+	a := p.attributes()
+	count := p.OrderCount() + 1
+	spend := p.TotalSpend() + total
+	a.Set(PropertyOrderCount, count)
+	a.Set(PropertyTotalSpend, spend)
+	a.Set(PropertyAverageOrderValue, spend/float64(count))
+	if last := p.LastOrderDate(); last.IsZero() || at.After(last) {
+		a.Set(PropertyLastOrderDate, at)
+	}
+	if first := p.FirstOrderDate(); first.IsZero() || at.Before(first) {
+		a.Set(PropertyFirstOrderDate, at)
+	}
+	return p
+}
+
+// Predictions Klaviyo computes for profiles with enough order history. Values are 0 until Klaviyo has made a
+// prediction.
+type PredictiveAnalytics struct {
+	HistoricCLV              float64 `json:"historic_clv"`
+	PredictedCLV             float64 `json:"predicted_clv"`
+	TotalCLV                 float64 `json:"total_clv"`
+	HistoricNumberOfOrders   float64 `json:"historic_number_of_orders"`
+	PredictedNumberOfOrders  float64 `json:"predicted_number_of_orders"`
+	AverageDaysBetweenOrders float64 `json:"average_days_between_orders"`
+	AverageOrderValue        float64 `json:"average_order_value"`
+	ChurnProbability         float64 `json:"churn_probability"`
+	ExpectedDateOfNextOrder  KTime   `json:"expected_date_of_next_order"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_profile
+// GET https://a.klaviyo.com/api/profiles/profile_id?additional-fields[profile]=predictive_analytics
+func (c *Client) GetPredictiveAnalytics(profileId string) (*PredictiveAnalytics, error) {
+	u := newEndpoint(Endpoint, fmt.Sprintf("profiles/%s", profileId))
+	values := u.Query()
+	values.Set("additional-fields[profile]", "predictive_analytics")
+	values.Set("fields[profile]", "predictive_analytics")
+	u.RawQuery = values.Encode()
+	var res struct {
+		Data struct {
+			Attributes struct {
+				PredictiveAnalytics PredictiveAnalytics `json:"predictive_analytics"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	err := c.sendV3(http.MethodGet, u, nil, &res)
+	return &res.Data.Attributes.PredictiveAnalytics, err
+}
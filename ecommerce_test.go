@@ -0,0 +1,42 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPerson_RecordOrder(t *testing.T) {
+	p := &Person{Email: "kitty@monstercat.com"}
+	jan := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.RecordOrder(10, jan.AddDate(0, 1, 0)).RecordOrder(20, jan)
+	if p.OrderCount() != 2 || p.TotalSpend() != 30 || p.AverageOrderValue() != 15 {
+		t.Errorf("Unexpected order totals %v", p.Attributes)
+	}
+	if !p.LastOrderDate().Equal(jan.AddDate(0, 1, 0)) {
+		t.Errorf("An older order should not move the last order date back, got %s", p.LastOrderDate())
+	}
+	if !p.FirstOrderDate().Equal(jan) {
+		t.Errorf("Unexpected first order date %s", p.FirstOrderDate())
+	}
+}
+
+func TestClient_GetPredictiveAnalytics(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/profiles/abc" || r.URL.Query().Get("additional-fields[profile]") != "predictive_analytics" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": {"type": "profile", "id": "abc", "attributes": {"predictive_analytics": {
+			"historic_clv": 93.87, "predicted_clv": 27.24, "total_clv": 121.11, "churn_probability": 0.89,
+			"expected_date_of_next_order": "2022-11-08T00:00:00+00:00"
+		}}}}`))
+	})
+	pa, err := client.GetPredictiveAnalytics("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pa.PredictedCLV != 27.24 || pa.TotalCLV != 121.11 || pa.ExpectedDateOfNextOrder.IsZero() {
+		t.Errorf("Unexpected analytics %+v", pa)
+	}
+}
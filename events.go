@@ -0,0 +1,185 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNoMetricName = errors.New("missing metric name")
+)
+
+// Event as returned by the v3 events endpoints.
+type Event struct {
+	Id        string
+	MetricId  string
+	ProfileId string
+
+	// Unix timestamp of when the event happened.
+	Timestamp int64
+	Datetime  time.Time
+	UUID      string
+
+	// Custom properties sent along with the event.
+	Properties map[string]interface{}
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Timestamp       int64                  `json:"timestamp"`
+			Datetime        time.Time              `json:"datetime"`
+			UUID            string                 `json:"uuid"`
+			EventProperties map[string]interface{} `json:"event_properties"`
+		} `json:"attributes"`
+		Relationships struct {
+			Metric  relationshipOne `json:"metric"`
+			Profile relationshipOne `json:"profile"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	*e = Event{
+		Id:         res.Id,
+		MetricId:   res.Relationships.Metric.Data.Id,
+		ProfileId:  res.Relationships.Profile.Data.Id,
+		Timestamp:  res.Attributes.Timestamp,
+		Datetime:   res.Attributes.Datetime,
+		UUID:       res.Attributes.UUID,
+		Properties: res.Attributes.EventProperties,
+		Extra:      extra,
+	}
+	return nil
+}
+
+// NewEvent holds everything needed to create (track) an event.
+type NewEvent struct {
+	// Name of the metric, e.g. MetricPlacedOrder. Klaviyo will create the metric if it does not exist.
+	Metric string
+
+	// The person who did the event, must have a profile identifier.
+	Person *Person
+
+	Properties map[string]interface{}
+
+	// When the event happened, leave empty to use the current time.
+	Time time.Time
+
+	// Monetary value associated with the event, e.g. order total.
+	Value float64
+
+	// Used by Klaviyo to deduplicate events, leave empty to have one generated.
+	UniqueId string
+}
+
+func (e *NewEvent) resource() map[string]interface{} {
+	attrs := map[string]interface{}{
+		"properties": e.Properties,
+		"metric": map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "metric",
+				"attributes": map[string]interface{}{
+					"name": e.Metric,
+				},
+			},
+		},
+		"profile": map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":       "profile",
+				"attributes": e.Person.profileAttributes(),
+			},
+		},
+	}
+	if e.Properties == nil {
+		attrs["properties"] = map[string]interface{}{}
+	}
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.UTC().Format(time.RFC3339)
+	}
+	if e.Value != 0 {
+		attrs["value"] = e.Value
+	}
+	if e.UniqueId != "" {
+		attrs["unique_id"] = e.UniqueId
+	}
+	return map[string]interface{}{
+		"type":       "event",
+		"attributes": attrs,
+	}
+}
+
+// https://developers.klaviyo.com/en/reference/create_event
+// POST https://a.klaviyo.com/api/events
+func (c *Client) CreateEvent(e *NewEvent) error {
+	e, err := c.checkEvent(e)
+	if err != nil {
+		return err
+	}
+	return c.sendV3(http.MethodPost, newEndpoint(Endpoint, "events"), &document{Data: e.resource()}, nil)
+}
+
+// Validates e and returns a copy with the default attributes added to the person when there are any.
+func (c *Client) checkEvent(e *NewEvent) (*NewEvent, error) {
+	if e.Metric == "" {
+		return nil, ErrNoMetricName
+	}
+	if e.Person == nil || !e.Person.HasProfileIdentifier() {
+		return nil, ErrNoProfileIdentifier
+	}
+	person, err := c.checkAttributes(e.Person)
+	if err != nil {
+		return nil, err
+	}
+	if person = c.withDefaultAttributes(person); person != e.Person {
+		ev := *e
+		ev.Person = person
+		e = &ev
+	}
+	return e, nil
+}
+
+type EventPage struct {
+	Data []Event `json:"data"`
+
+	// Filled with the related resources requested through Query.Include, e.g. metric and profile.
+	Included []Resource `json:"included"`
+	Links    Links      `json:"links"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_events
+// GET https://a.klaviyo.com/api/events
+// Returns a page of events, use Links.NextCursor() to get the cursor for the next page which is empty on the last page.
+// Supported filters are metric_id, profile_id, profile, datetime and timestamp.
+func (c *Client) GetEvents(q *Query) (*EventPage, error) {
+	u := newEndpoint(Endpoint, "events")
+	q.apply(u)
+	var res EventPage
+	err := c.sendV3(http.MethodGet, u, nil, &res)
+	return &res, err
+}
+
+// Copies e along with its person and properties, which a plain copy would share with the caller.
+func copyEvent(e *NewEvent) *NewEvent {
+	cp := *e
+	if e.Person != nil {
+		cp.Person = copyPerson(e.Person)
+	}
+	if e.Properties != nil {
+		cp.Properties = make(map[string]interface{}, len(e.Properties))
+		for k, v := range e.Properties {
+			cp.Properties[k] = v
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,138 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testEventJSON = `{
+	"type": "event",
+	"id": "4vRpBT",
+	"attributes": {
+		"timestamp": 1667865600,
+		"event_properties": {"Total": 9.99},
+		"datetime": "2022-11-08T00:00:00+00:00",
+		"uuid": "01GH3M1BZ0Q6KT6XVQ0DQZ7QZB"
+	},
+	"relationships": {
+		"metric": {"data": {"type": "metric", "id": "Y6Hmxn"}},
+		"profile": {"data": {"type": "profile", "id": "01GDDKASAP8TKDDA2GRZDSVP4H"}}
+	}
+}`
+
+func TestEvent_UnmarshalJSON(t *testing.T) {
+	var e Event
+	if err := json.Unmarshal([]byte(testEventJSON), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Id != "4vRpBT" {
+		t.Error("Id did not match")
+	}
+	if e.MetricId != "Y6Hmxn" {
+		t.Error("MetricId did not match")
+	}
+	if e.ProfileId != "01GDDKASAP8TKDDA2GRZDSVP4H" {
+		t.Error("ProfileId did not match")
+	}
+	if e.Timestamp != 1667865600 || e.Datetime.Unix() != e.Timestamp {
+		t.Error("Time did not match")
+	}
+	if e.Properties["Total"] != 9.99 {
+		t.Error("Properties did not match")
+	}
+}
+
+func TestNewEvent_Resource(t *testing.T) {
+	p := newTestPerson()
+	e := NewEvent{
+		Metric: MetricPlacedOrder,
+		Person: &p,
+		Time:   time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC),
+		Value:  9.99,
+	}
+	xs, err := json.Marshal(e.resource())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Type       string `json:"type"`
+		Attributes struct {
+			Time   string  `json:"time"`
+			Value  float64 `json:"value"`
+			Metric struct {
+				Data struct {
+					Attributes struct {
+						Name string `json:"name"`
+					} `json:"attributes"`
+				} `json:"data"`
+			} `json:"metric"`
+			Profile struct {
+				Data struct {
+					Attributes struct {
+						Email string `json:"email"`
+					} `json:"attributes"`
+				} `json:"data"`
+			} `json:"profile"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(xs, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != "event" {
+		t.Error("Expected type event")
+	}
+	if res.Attributes.Time != "2022-11-08T00:00:00Z" {
+		t.Errorf("Unexpected time %s", res.Attributes.Time)
+	}
+	if res.Attributes.Value != e.Value {
+		t.Error("Value did not match")
+	}
+	if res.Attributes.Metric.Data.Attributes.Name != e.Metric {
+		t.Error("Metric name did not match")
+	}
+	if res.Attributes.Profile.Data.Attributes.Email != p.Email {
+		t.Error("Profile email did not match")
+	}
+}
+
+func TestQuery_Apply(t *testing.T) {
+	u := newEndpoint(Endpoint, "events")
+	q := &Query{
+		Filter:   `equals(metric_id,"Y6Hmxn")`,
+		Sort:     "-datetime",
+		Cursor:   "abc",
+		PageSize: 10,
+		Fields:   map[string][]string{"profile": {"email", "first_name"}},
+		Include:  []string{"metric", "profile"},
+	}
+	q.apply(u)
+	values := u.Query()
+	if values.Get("filter") != q.Filter {
+		t.Error("filter did not match")
+	}
+	if values.Get("sort") != q.Sort {
+		t.Error("sort did not match")
+	}
+	if values.Get("page[cursor]") != q.Cursor {
+		t.Error("page[cursor] did not match")
+	}
+	if values.Get("page[size]") != "10" {
+		t.Error("page[size] did not match")
+	}
+	if values.Get("fields[profile]") != "email,first_name" {
+		t.Error("fields[profile] did not match")
+	}
+	if values.Get("include") != "metric,profile" {
+		t.Error("include did not match")
+	}
+
+	links := Links{Next: "https://a.klaviyo.com/api/events/?page%5Bcursor%5D=" + url.QueryEscape("bmV4dA==")}
+	if links.NextCursor() != "bmV4dA==" {
+		t.Errorf("Unexpected next cursor %s", links.NextCursor())
+	}
+	if (Links{}).NextCursor() != "" {
+		t.Error("Expected empty cursor without a next link")
+	}
+}
@@ -0,0 +1,105 @@
+package klaviyo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+var (
+	ErrUnknownExportFormat = errors.New("unknown export format")
+
+	exportCSVHeader = []string{"id", "email", "phone_number", "push_token", "created"}
+)
+
+// memberWriter writes ListPerson records one at a time in the requested format.
+type memberWriter interface {
+	Write(p ListPerson) error
+	Flush() error
+}
+
+func newMemberWriter(w io.Writer, format ExportFormat) (memberWriter, error) {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportCSVHeader); err != nil {
+			return nil, err
+		}
+		return &csvMemberWriter{w: cw}, nil
+	case ExportNDJSON:
+		return &ndjsonMemberWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, ErrUnknownExportFormat
+}
+
+type csvMemberWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvMemberWriter) Write(p ListPerson) error {
+	return w.w.Write([]string{p.Id, p.Email, p.PhoneNumber, p.PushToken, p.Created})
+}
+
+func (w *csvMemberWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonMemberWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonMemberWriter) Write(p ListPerson) error {
+	// Encode already terminates every value with a newline.
+	return w.enc.Encode(&p)
+}
+
+func (w *ndjsonMemberWriter) Flush() error {
+	return nil
+}
+
+// Streams every member of a list or segment into w. All pages are fetched and paced by the rate limit, see
+// EachGroupMember, so this can take a while for large groups.
+func (c *Client) ExportGroupMembers(w io.Writer, groupId string, format ExportFormat) error {
+	mw, err := newMemberWriter(w, format)
+	if err != nil {
+		return err
+	}
+	if err := c.EachGroupMember(c.context(), groupId, mw.Write); err != nil {
+		return err
+	}
+	return mw.Flush()
+}
+
+// Same as ExportGroupMembers but returns a reader that is filled as pages come in. Any error that happens during
+// the export is returned from Read. Make sure to close the reader if you stop reading early.
+func (c *Client) ExportGroupMembersReader(groupId string, format ExportFormat) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(c.ExportGroupMembers(w, groupId, format))
+	}()
+	return r
+}
+
+// Writes the export to the given file, creating or truncating it. The file is left in place on failure so partial
+// exports can be inspected.
+func (c *Client) ExportGroupMembersToFile(filename, groupId string, format ExportFormat) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := c.ExportGroupMembers(f, groupId, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,108 @@
+package klaviyo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func testListPeople() []ListPerson {
+	return []ListPerson{
+		{Id: "abc", Email: "kitty@monstercat.com", Created: "2021-01-01 00:00:00"},
+		{Id: "def", PhoneNumber: "+1234567890"},
+	}
+}
+
+func TestMemberWriter_CSV(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	w, err := newMemberWriter(buf, ExportCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range testListPeople() {
+		if err := w.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d lines", len(lines))
+	}
+	if lines[0] != "id,email,phone_number,push_token,created" {
+		t.Errorf("Unexpected header %s", lines[0])
+	}
+	if lines[1] != "abc,kitty@monstercat.com,,,2021-01-01 00:00:00" {
+		t.Errorf("Unexpected row %s", lines[1])
+	}
+}
+
+func TestMemberWriter_NDJSON(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	w, err := newMemberWriter(buf, ExportNDJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	people := testListPeople()
+	for _, p := range people {
+		if err := w.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dec := json.NewDecoder(buf)
+	for _, p := range people {
+		var b ListPerson
+		if err := dec.Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+		if b != p {
+			t.Errorf("Decoded %+v, expected %+v", b, p)
+		}
+	}
+}
+
+func TestMemberWriter_UnknownFormat(t *testing.T) {
+	if _, err := newMemberWriter(bytes.NewBuffer(nil), "xml"); err != ErrUnknownExportFormat {
+		t.Errorf("Expected ErrUnknownExportFormat, got %v", err)
+	}
+}
+
+func TestClient_ExportGroupMembers(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("marker") {
+		case "":
+			if hits == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"detail": "throttled"}`))
+				return
+			}
+			w.Write([]byte(`{"records": [{"id": "abc", "email": "kitty@monstercat.com"}], "marker": 123}`))
+		case "123":
+			w.Write([]byte(`{"records": [{"id": "def", "phone_number": "+1234567890"}]}`))
+		default:
+			t.Errorf("Unexpected marker %s", r.URL.Query().Get("marker"))
+		}
+	})
+	buf := bytes.NewBuffer([]byte{})
+	if err := client.ExportGroupMembers(buf, testListId, ExportNDJSON); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 requests, got %d", hits)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 members, got %d", len(lines))
+	}
+	if !strings.Contains(lines[1], `"id":"def"`) {
+		t.Errorf("Unexpected line %s", lines[1])
+	}
+}
@@ -0,0 +1,42 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Returns the members of the JSON object data which do not decode into a field of known, a pointer to a struct. nil
+// when there are none, so responses the SDK fully understands do not allocate.
+func extraFields(data []byte, known interface{}) (Attributes, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil || len(m) == 0 {
+		return nil, err
+	}
+	t := reflect.TypeOf(known).Elem()
+	var extra Attributes
+	for k, raw := range m {
+		if _, ok := jsonField(t, k); ok {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		if extra == nil {
+			extra = Attributes{}
+		}
+		extra[k] = v
+	}
+	return extra, nil
+}
+
+// Same as extraFields for the attributes of a v3 resource.
+func extraAttributes(data []byte, known interface{}) (Attributes, error) {
+	var res struct {
+		Attributes json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil || len(res.Attributes) == 0 {
+		return nil, err
+	}
+	return extraFields(res.Attributes, known)
+}
@@ -0,0 +1,46 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtra(t *testing.T) {
+	var form Form
+	if err := json.Unmarshal([]byte(`{"type": "form", "id": "F1", "attributes": {"name": "Newsletter", "ab_test": true, "goal": "signups", "steps": 2}}`), &form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Name != "Newsletter" || len(form.Extra) != 2 || form.Extra["goal"] != "signups" || form.Extra.ParseInt("steps") != 2 {
+		t.Errorf("Unexpected form %+v", form)
+	}
+
+	var metric Metric
+	if err := json.Unmarshal([]byte(`{"type": "metric", "id": "M1", "attributes": {"name": "Placed Order"}}`), &metric); err != nil {
+		t.Fatal(err)
+	}
+	if metric.Extra != nil {
+		t.Errorf("Expected no extra attributes, got %v", metric.Extra)
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(`{"type": "event", "id": "E1", "attributes": {"timestamp": 1672531200, "event_properties": {"$value": 10}, "source": "api"}}`), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Extra["source"] != "api" || event.Extra["event_properties"] != nil {
+		t.Errorf("Unexpected extra attributes %v", event.Extra)
+	}
+
+	var list Group
+	if err := json.Unmarshal([]byte(`{"list_id": "LIST1", "list_name": "Fans", "folder": "Music"}`), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Id != "LIST1" || list.Extra["folder"] != "Music" || len(list.Extra) != 1 {
+		t.Errorf("Unexpected list %+v", list)
+	}
+	if err := json.Unmarshal([]byte(`{"type": "list", "id": "LIST1", "attributes": {"name": "Fans", "opt_in_process": "double_opt_in", "folder": "Music"}, "links": {}}`), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.OptInProcess != OptInDouble || list.Extra["folder"] != "Music" || len(list.Extra) != 1 {
+		t.Errorf("Unexpected list %+v", list)
+	}
+}
@@ -0,0 +1,143 @@
+package klaviyo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterBuilder composes expressions of the v3 filter grammar, e.g. equals(email,"kitty@monstercat.com"). Values are
+// encoded for you so strings are quoted and escaped, times are sent as ISO 8601 and slices become lists.
+// https://developers.klaviyo.com/en/docs/filtering_
+//
+// Use String() to get the value for Query.Filter:
+//
+//	q := &Query{Filter: Equals("metric_id", "Y6Hmxn").And(GreaterThan("datetime", since)).String()}
+type FilterBuilder struct {
+	expr string
+}
+
+func (f FilterBuilder) String() string {
+	return f.expr
+}
+
+// Returns a filter that matches when both f and all of the others match.
+func (f FilterBuilder) And(others ...FilterBuilder) FilterBuilder {
+	return And(append([]FilterBuilder{f}, others...)...)
+}
+
+// Returns a filter that matches when f or any of the others match.
+func (f FilterBuilder) Or(others ...FilterBuilder) FilterBuilder {
+	return Or(append([]FilterBuilder{f}, others...)...)
+}
+
+func And(filters ...FilterBuilder) FilterBuilder {
+	return combineFilters("and", filters)
+}
+
+func Or(filters ...FilterBuilder) FilterBuilder {
+	return combineFilters("or", filters)
+}
+
+func Not(f FilterBuilder) FilterBuilder {
+	return FilterBuilder{expr: fmt.Sprintf("not(%s)", f.expr)}
+}
+
+func Equals(field string, value interface{}) FilterBuilder {
+	return newFilter("equals", field, value)
+}
+
+func LessThan(field string, value interface{}) FilterBuilder {
+	return newFilter("less-than", field, value)
+}
+
+func LessOrEqual(field string, value interface{}) FilterBuilder {
+	return newFilter("less-or-equal", field, value)
+}
+
+func GreaterThan(field string, value interface{}) FilterBuilder {
+	return newFilter("greater-than", field, value)
+}
+
+func GreaterOrEqual(field string, value interface{}) FilterBuilder {
+	return newFilter("greater-or-equal", field, value)
+}
+
+func Contains(field string, value interface{}) FilterBuilder {
+	return newFilter("contains", field, value)
+}
+
+func StartsWith(field string, value string) FilterBuilder {
+	return newFilter("starts-with", field, value)
+}
+
+func EndsWith(field string, value string) FilterBuilder {
+	return newFilter("ends-with", field, value)
+}
+
+// Matches when the field is equal to any of the values.
+func Any(field string, values ...interface{}) FilterBuilder {
+	return newFilter("any", field, values)
+}
+
+func newFilter(op, field string, value interface{}) FilterBuilder {
+	return FilterBuilder{expr: fmt.Sprintf("%s(%s,%s)", op, field, encodeFilterValue(value))}
+}
+
+// Empty filters are skipped so that filters can be built up conditionally. Combining a single filter returns it as is.
+func combineFilters(op string, filters []FilterBuilder) FilterBuilder {
+	exprs := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if f.expr != "" {
+			exprs = append(exprs, f.expr)
+		}
+	}
+	switch len(exprs) {
+	case 0:
+		return FilterBuilder{}
+	case 1:
+		return FilterBuilder{expr: exprs[0]}
+	}
+	return FilterBuilder{expr: fmt.Sprintf("%s(%s)", op, strings.Join(exprs, ","))}
+}
+
+func encodeFilterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteFilterString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []string:
+		xs := make([]string, len(v))
+		for i, s := range v {
+			xs[i] = quoteFilterString(s)
+		}
+		return "[" + strings.Join(xs, ",") + "]"
+	case []interface{}:
+		xs := make([]string, len(v))
+		for i, x := range v {
+			xs[i] = encodeFilterValue(x)
+		}
+		return "[" + strings.Join(xs, ",") + "]"
+	case fmt.Stringer:
+		return quoteFilterString(v.String())
+	}
+	return quoteFilterString(fmt.Sprintf("%v", value))
+}
+
+func quoteFilterString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
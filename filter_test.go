@@ -0,0 +1,38 @@
+package klaviyo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	since := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		filter   FilterBuilder
+		expected string
+	}{
+		{Equals("email", "kitty@monstercat.com"), `equals(email,"kitty@monstercat.com")`},
+		{Equals("email", `say "hi"`), `equals(email,"say \"hi\"")`},
+		{GreaterThan("datetime", since), `greater-than(datetime,2023-01-02T03:04:05Z)`},
+		{LessOrEqual("timestamp", 1667865600), `less-or-equal(timestamp,1667865600)`},
+		{Contains("properties.Tags", "gold"), `contains(properties.Tags,"gold")`},
+		{Any("id", "a", "b"), `any(id,["a","b"])`},
+		{Equals("properties.IsTest", true), `equals(properties.IsTest,true)`},
+		{
+			Equals("metric_id", "Y6Hmxn").And(GreaterThan("datetime", since)),
+			`and(equals(metric_id,"Y6Hmxn"),greater-than(datetime,2023-01-02T03:04:05Z))`,
+		},
+		{
+			Or(Equals("email", "a@b.c"), Equals("phone_number", "+1234567890")),
+			`or(equals(email,"a@b.c"),equals(phone_number,"+1234567890"))`,
+		},
+		{Not(Equals("email", "a@b.c")), `not(equals(email,"a@b.c"))`},
+		{And(FilterBuilder{}, Equals("email", "a@b.c")), `equals(email,"a@b.c")`},
+		{And(), ``},
+	}
+	for _, test := range tests {
+		if test.filter.String() != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, test.filter.String())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Flow struct {
+	Id          string
+	Name        string
+	Status      string
+	Archived    bool
+	TriggerType string
+	Created     KTime
+	Updated     KTime
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes
+}
+
+func (f *Flow) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Name        string `json:"name"`
+			Status      string `json:"status"`
+			Archived    bool   `json:"archived"`
+			TriggerType string `json:"trigger_type"`
+			Created     KTime  `json:"created"`
+			Updated     KTime  `json:"updated"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	*f = Flow{
+		Id:          res.Id,
+		Name:        res.Attributes.Name,
+		Status:      res.Attributes.Status,
+		Archived:    res.Attributes.Archived,
+		TriggerType: res.Attributes.TriggerType,
+		Created:     res.Attributes.Created,
+		Updated:     res.Attributes.Updated,
+		Extra:       extra,
+	}
+	return nil
+}
+
+// A step in a flow, e.g. a time delay, a conditional split or sending a message.
+type FlowAction struct {
+	Id string
+
+	// e.g. SEND_EMAIL, TIME_DELAY or CONDITIONAL_SPLIT
+	ActionType string
+	Status     string
+	Created    KTime
+	Updated    KTime
+
+	// Depends on the action type, e.g. the delay of a TIME_DELAY action.
+	Settings map[string]interface{}
+}
+
+func (a *FlowAction) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			ActionType string                 `json:"action_type"`
+			Status     string                 `json:"status"`
+			Created    KTime                  `json:"created"`
+			Updated    KTime                  `json:"updated"`
+			Settings   map[string]interface{} `json:"settings"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*a = FlowAction{
+		Id:         res.Id,
+		ActionType: res.Attributes.ActionType,
+		Status:     res.Attributes.Status,
+		Created:    res.Attributes.Created,
+		Updated:    res.Attributes.Updated,
+		Settings:   res.Attributes.Settings,
+	}
+	return nil
+}
+
+// A message sent by a flow action.
+type FlowMessage struct {
+	Id      string
+	Name    string
+	Channel MessageChannel
+	Created KTime
+	Updated KTime
+
+	// Subject, sender and so on, depends on the channel.
+	Content map[string]interface{}
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes
+}
+
+func (m *FlowMessage) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Name    string                 `json:"name"`
+			Channel MessageChannel         `json:"channel"`
+			Created KTime                  `json:"created"`
+			Updated KTime                  `json:"updated"`
+			Content map[string]interface{} `json:"content"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	*m = FlowMessage{
+		Id:      res.Id,
+		Name:    res.Attributes.Name,
+		Channel: res.Attributes.Channel,
+		Created: res.Attributes.Created,
+		Updated: res.Attributes.Updated,
+		Content: res.Attributes.Content,
+		Extra:   extra,
+	}
+	return nil
+}
+
+type FlowPage struct {
+	Data  []Flow `json:"data"`
+	Links Links  `json:"links"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_flows
+// GET https://a.klaviyo.com/api/flows
+// Returns a page of flows, use Links.NextCursor() to get the next one.
+func (c *Client) GetFlows(q *Query) (*FlowPage, error) {
+	u := newEndpoint(Endpoint, "flows")
+	q.apply(u)
+	var res FlowPage
+	err := c.sendV3(http.MethodGet, u, nil, &res)
+	return &res, err
+}
+
+// https://developers.klaviyo.com/en/reference/get_flow_flow_actions
+// GET https://a.klaviyo.com/api/flows/flow_id/flow-actions
+// Returns every action of the flow, going through all the pages.
+func (c *Client) GetFlowActions(flowId string) ([]FlowAction, error) {
+	var actions []FlowAction
+	u := newEndpoint(Endpoint, fmt.Sprintf("flows/%s/flow-actions", flowId))
+	err := c.eachV3Page(u, func(data json.RawMessage) error {
+		var page []FlowAction
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		actions = append(actions, page...)
+		return nil
+	})
+	return actions, err
+}
+
+// https://developers.klaviyo.com/en/reference/get_flow_action_messages
+// GET https://a.klaviyo.com/api/flow-actions/action_id/flow-messages
+// Returns every message of the flow action, going through all the pages.
+func (c *Client) GetFlowMessages(actionId string) ([]FlowMessage, error) {
+	var messages []FlowMessage
+	u := newEndpoint(Endpoint, fmt.Sprintf("flow-actions/%s/flow-messages", actionId))
+	err := c.eachV3Page(u, func(data json.RawMessage) error {
+		var page []FlowMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		messages = append(messages, page...)
+		return nil
+	})
+	return messages, err
+}
+
+// Calls fn with the data of every page of the collection at u, following the next links.
+func (c *Client) eachV3Page(u *url.URL, fn func(data json.RawMessage) error) error {
+	for {
+		var res struct {
+			Data  json.RawMessage `json:"data"`
+			Links Links           `json:"links"`
+		}
+		if err := c.sendV3(http.MethodGet, u, nil, &res); err != nil {
+			return err
+		}
+		if err := fn(res.Data); err != nil {
+			return err
+		}
+		cursor := res.Links.NextCursor()
+		if cursor == "" {
+			return nil
+		}
+		(&Query{Cursor: cursor}).apply(u)
+	}
+}
@@ -0,0 +1,51 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetFlowActions(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/flows/FLOW1/flow-actions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch r.URL.Query().Get("page[cursor]") {
+		case "":
+			w.Write([]byte(`{"data": [{"type": "flow-action", "id": "A1", "attributes": {"action_type": "TIME_DELAY", "settings": {"delay_seconds": 3600}}}],
+				"links": {"next": "https://a.klaviyo.com/api/flows/FLOW1/flow-actions?page%5Bcursor%5D=next"}}`))
+		case "next":
+			w.Write([]byte(`{"data": [{"type": "flow-action", "id": "A2", "attributes": {"action_type": "SEND_EMAIL", "status": "live"}}], "links": {}}`))
+		default:
+			t.Errorf("Unexpected cursor %s", r.URL.Query().Get("page[cursor]"))
+		}
+	})
+	actions, err := client.GetFlowActions("FLOW1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(actions))
+	}
+	if actions[0].Settings["delay_seconds"] != 3600.0 || actions[1].ActionType != "SEND_EMAIL" {
+		t.Errorf("Unexpected actions %+v", actions)
+	}
+}
+
+func TestClient_GetFlowMessages(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/flow-actions/A2/flow-messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": [{"type": "flow-message", "id": "M1", "attributes": {"name": "Welcome", "channel": "email", "content": {"subject": "Hi"}}}], "links": {}}`))
+	})
+	messages, err := client.GetFlowMessages("A2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Name != "Welcome" || messages[0].Channel != ChannelEmail || messages[0].Content["subject"] != "Hi" {
+		t.Errorf("Unexpected messages %+v", messages)
+	}
+}
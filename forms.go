@@ -0,0 +1,129 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// The forms endpoints were added after APIRevision.
+const formsRevision = "2024-10-15"
+
+// Name of the metric TrackFormSubmission records events under.
+const MetricSubmittedForm = "Submitted Form"
+
+var (
+	ErrNoFormId = errors.New("missing form id")
+)
+
+// Sign-up form as returned by the v3 forms endpoints.
+type Form struct {
+	Id     string
+	Name   string
+	Status string
+
+	// Whether the form is running an A/B test.
+	ABTest  bool
+	Created KTime
+	Updated KTime
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes
+}
+
+func (f *Form) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Name      string `json:"name"`
+			Status    string `json:"status"`
+			ABTest    bool   `json:"ab_test"`
+			CreatedAt KTime  `json:"created_at"`
+			UpdatedAt KTime  `json:"updated_at"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	*f = Form{
+		Id:      res.Id,
+		Name:    res.Attributes.Name,
+		Status:  res.Attributes.Status,
+		ABTest:  res.Attributes.ABTest,
+		Created: res.Attributes.CreatedAt,
+		Updated: res.Attributes.UpdatedAt,
+		Extra:   extra,
+	}
+	return nil
+}
+
+type FormPage struct {
+	Data  []Form `json:"data"`
+	Links Links  `json:"links"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_forms
+// GET https://a.klaviyo.com/api/forms
+// Returns a page of sign-up forms, use Links.NextCursor() to get the next one. Supported filters are id, name, ab_test,
+// updated_at, created_at and status.
+func (c *Client) GetForms(q *Query) (*FormPage, error) {
+	u := newEndpoint(Endpoint, "forms")
+	q.apply(u)
+	var res FormPage
+	err := c.sendV3Revision(formsRevision, http.MethodGet, u, nil, &res)
+	return &res, err
+}
+
+// https://developers.klaviyo.com/en/reference/get_form
+// GET https://a.klaviyo.com/api/forms/form_id
+func (c *Client) GetForm(formId string) (*Form, error) {
+	var res struct {
+		Data Form `json:"data"`
+	}
+	err := c.sendV3Revision(formsRevision, http.MethodGet, newEndpoint(Endpoint, fmt.Sprintf("forms/%s", formId)), nil, &res)
+	return &res.Data, err
+}
+
+// A submission of a sign-up form which was not hosted by Klaviyo, e.g. a form on our own site that mirrors a Klaviyo
+// one.
+type FormSubmission struct {
+	FormId string
+
+	// Optional, makes the events easier to read in Klaviyo.
+	FormName string
+
+	// The list the form subscribes people to, if any. Used to reconcile which forms feed which lists.
+	ListId string
+
+	Person     *Person
+	Properties map[string]interface{}
+}
+
+// Records a form submission as an event under the MetricSubmittedForm metric. This does not subscribe the person to
+// ListId, use Subscribe for that.
+func (c *Client) TrackFormSubmission(s *FormSubmission) error {
+	if s.FormId == "" {
+		return ErrNoFormId
+	}
+	props := map[string]interface{}{}
+	for k, v := range s.Properties {
+		props[k] = v
+	}
+	props["form_id"] = s.FormId
+	if s.FormName != "" {
+		props["form_name"] = s.FormName
+	}
+	if s.ListId != "" {
+		props["list_id"] = s.ListId
+	}
+	return c.CreateEvent(&NewEvent{
+		Metric:     MetricSubmittedForm,
+		Person:     s.Person,
+		Properties: props,
+	})
+}
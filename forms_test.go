@@ -0,0 +1,83 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetForms(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Revision") != formsRevision {
+			t.Errorf("Unexpected revision %s", r.Header.Get("Revision"))
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch r.URL.Path {
+		case "/api/forms":
+			if r.URL.Query().Get("filter") != `equals(status,"live")` {
+				t.Errorf("Unexpected filter %s", r.URL.Query().Get("filter"))
+			}
+			w.Write([]byte(`{"data": [{"type": "form", "id": "F1", "attributes": {"name": "Newsletter", "status": "live", "created_at": "2023-01-01T00:00:00+00:00"}}], "links": {}}`))
+		case "/api/forms/F1":
+			w.Write([]byte(`{"data": {"type": "form", "id": "F1", "attributes": {"name": "Newsletter", "status": "live", "ab_test": true}}}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	page, err := client.GetForms(&Query{Filter: Equals("status", "live").String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 || page.Data[0].Name != "Newsletter" || page.Data[0].Created.IsZero() {
+		t.Errorf("Unexpected forms %+v", page.Data)
+	}
+	form, err := client.GetForm("F1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Id != "F1" || !form.ABTest {
+		t.Errorf("Unexpected form %+v", form)
+	}
+}
+
+func TestClient_TrackFormSubmission(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Data struct {
+				Attributes struct {
+					Properties map[string]interface{} `json:"properties"`
+					Metric     struct {
+						Data struct {
+							Attributes struct {
+								Name string `json:"name"`
+							} `json:"attributes"`
+						} `json:"data"`
+					} `json:"metric"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		attrs := doc.Data.Attributes
+		if attrs.Metric.Data.Attributes.Name != MetricSubmittedForm {
+			t.Errorf("Unexpected metric %s", attrs.Metric.Data.Attributes.Name)
+		}
+		if attrs.Properties["form_id"] != "F1" || attrs.Properties["list_id"] != "LIST1" || attrs.Properties["source"] != "footer" {
+			t.Errorf("Unexpected properties %v", attrs.Properties)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	if err := client.TrackFormSubmission(&FormSubmission{FormId: ""}); err != ErrNoFormId {
+		t.Errorf("Expected ErrNoFormId, got %v", err)
+	}
+	err := client.TrackFormSubmission(&FormSubmission{
+		FormId:     "F1",
+		ListId:     "LIST1",
+		Person:     &Person{Email: "kitty@monstercat.com"},
+		Properties: map[string]interface{}{"source": "footer"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The size of a list or segment at a point in time.
+type GroupSample struct {
+	Time  time.Time
+	Count int
+}
+
+// Counts the members of a list or segment in a single request. Ids are tried as a list first and as a segment when
+// there is no such list.
+func (c *Client) countGroupMembers(groupId string) (int, error) {
+	count, err := c.CountListMembers(groupId)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return count, err
+	}
+	u := newEndpoint(Endpoint, fmt.Sprintf("segments/%s", groupId))
+	values := u.Query()
+	values.Set("additional-fields[segment]", "profile_count")
+	u.RawQuery = values.Encode()
+	var res struct {
+		Data Segment `json:"data"`
+	}
+	err = c.sendV3(http.MethodGet, u, nil, &res)
+	return int(res.Data.PersonCount), err
+}
+
+// Returns the current size of each list or segment keyed by its id, taking one request per list and two per segment.
+func (c *Client) GroupSizes(groupIds ...string) (map[string]GroupSample, error) {
+	res := map[string]GroupSample{}
+	for _, id := range groupIds {
+		count, err := c.countGroupMembers(id)
+		if err != nil {
+			return nil, err
+		}
+		res[id] = GroupSample{Time: c.clock().Now(), Count: count}
+	}
+	return res, nil
+}
+
+// Samples the size of each list or segment samples times, interval apart, and returns the time series of each keyed
+// by its id, e.g. to chart list growth. Blocks until all samples were taken or ctx is done, in which case the samples
+// taken so far are returned along with the context's error.
+func (c *Client) SampleGroupGrowth(ctx context.Context, groupIds []string, interval time.Duration, samples int) (map[string][]GroupSample, error) {
+	cc := c.WithContext(ctx)
+	res := map[string][]GroupSample{}
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			if err := cc.sleep(interval); err != nil {
+				return res, err
+			}
+		}
+		sizes, err := cc.GroupSizes(groupIds...)
+		if err != nil {
+			return res, err
+		}
+		for _, id := range groupIds {
+			res[id] = append(res[id], sizes[id])
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_SampleGroupGrowth(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch r.URL.Path {
+		case "/api/lists/LIST1":
+			if r.URL.Query().Get("additional-fields[list]") != "profile_count" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"data": {"type": "list", "id": "LIST1", "attributes": {"name": "Newsletter", "profile_count": 3}}}`))
+		case "/api/lists/SEG1":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"status": 404, "code": "not_found", "detail": "List not found"}]}`))
+		case "/api/segments/SEG1":
+			if r.URL.Query().Get("additional-fields[segment]") != "profile_count" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"data": {"type": "segment", "id": "SEG1", "attributes": {"name": "VIPs", "profile_count": 1}}}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client.Clock = clock
+
+	res, err := client.SampleGroupGrowth(context.Background(), []string{"LIST1", "SEG1"}, time.Minute, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res["LIST1"]) != 2 || len(res["SEG1"]) != 2 {
+		t.Fatalf("Expected 2 samples each, got %v", res)
+	}
+	if res["LIST1"][0].Count != 3 || res["SEG1"][1].Count != 1 {
+		t.Errorf("Unexpected samples %v", res)
+	}
+	if res["LIST1"][1].Time.Sub(res["LIST1"][0].Time) != time.Minute {
+		t.Errorf("Expected samples to be taken a minute apart, got %v", res["LIST1"])
+	}
+	if hits != 6 {
+		t.Errorf("Expected one request per list and two per segment for each sample, got %d", hits)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = client.SampleGroupGrowth(ctx, []string{"SEG1"}, time.Hour, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(res["SEG1"]) != 0 {
+		t.Errorf("Expected no samples with a canceled context, got %v", res)
+	}
+}
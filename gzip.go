@@ -0,0 +1,52 @@
+package klaviyo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Smaller bodies are not worth compressing.
+const minCompressSize = 1024
+
+// Creates a request with body, gzipped when Client.CompressRequests is set and the body is large enough.
+func (c *Client) newBodyRequest(method, url string, body []byte) (*http.Request, error) {
+	if !c.CompressRequests || len(body) < minCompressSize {
+		return http.NewRequest(method, url, bytes.NewReader(body))
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	return req, nil
+}
+
+// http.DefaultTransport asks for gzip and decompresses transparently, but custom transports (or ones with
+// DisableCompression and an explicit Accept-Encoding) can hand us the compressed body.
+func decompressResponse(res *http.Response) error {
+	if !strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	zr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		return err
+	}
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{zr, res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	return nil
+}
@@ -0,0 +1,65 @@
+package klaviyo
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestClient_CompressRequests(t *testing.T) {
+	emails := make([]string, 100)
+	for i := range emails {
+		emails[i] = "kitty@monstercat.com"
+	}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if r.Header.Get("Content-Encoding") != "gzip" {
+				t.Error("Expected a gzipped request body")
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var doc map[string]interface{}
+			if err := json.NewDecoder(zr).Decode(&doc); err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Respond with gzip regardless of what was asked, the client must handle it.
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, `{"list_name": "Test"}`)
+		zw.Close()
+	})
+	client.CompressRequests = true
+	if err := client.SuppressProfiles(emails); err != nil {
+		t.Fatal(err)
+	}
+	l, err := client.GetList(testListId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name != "Test" {
+		t.Errorf("Unexpected list %+v", l)
+	}
+
+	// Small bodies are sent as is.
+	req, err := client.newBodyRequest(http.MethodPost, Endpoint, []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Content-Encoding") != "" {
+		t.Error("Did not expect small bodies to be compressed")
+	}
+	buf, _ := io.ReadAll(req.Body)
+	if string(buf) != `{}` {
+		t.Errorf("Unexpected body %s", buf)
+	}
+}
@@ -0,0 +1,87 @@
+package klaviyo
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// Timings of a HealthCheck request. The connection phases are 0 when an idle connection was reused.
+type HealthStatus struct {
+	// The status Klaviyo answered with. Anything below 500 means the API is up, the probe is not authenticated so 401
+	// and 405 are expected.
+	StatusCode int
+
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Total     time.Duration
+
+	ReusedConn bool
+}
+
+// Sends an unauthenticated HEAD request to the Klaviyo API and reports how long each phase took, for readiness probes
+// and latency dashboards. No data is read or changed so it does not count against the account's rate limits. Returns
+// an *APIError along with the status when Klaviyo answers with a 5XX error. The request goes through the client's
+// Transport and Middleware but is never retried.
+func (c *Client) HealthCheck(ctx context.Context) (*HealthStatus, error) {
+	var status HealthStatus
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			status.ReusedConn = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			status.DNS = time.Since(dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			status.Connect = time.Since(connectStart)
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			status.TLS = time.Since(tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			status.FirstByte = time.Since(start)
+		},
+	}
+
+	u := newEndpoint(Endpoint, "")
+	c.regionURL(u)
+	r, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodHead, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setRequestId(r)
+	res, err := c.httpClient().Do(r)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	status.Total = time.Since(start)
+	status.StatusCode = res.StatusCode
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return &status, &APIError{
+			StatusCode: res.StatusCode,
+			Message:    res.Status,
+			RetryAfter: ParseRetryAfter(res.Header, time.Now()),
+			RequestId:  RequestIdFromContext(ctx),
+		}
+	}
+	return &status, nil
+}
@@ -0,0 +1,51 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_HealthCheck(t *testing.T) {
+	code := http.StatusMethodNotAllowed
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD, got %s", r.Method)
+		}
+		if r.URL.Query().Get("api_key") != "" || r.Header.Get("Authorization") != "" {
+			t.Error("Expected the probe to be unauthenticated")
+		}
+		w.WriteHeader(code)
+	})
+
+	status, err := client.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status %d", status.StatusCode)
+	}
+	if status.Total <= 0 || status.FirstByte <= 0 || status.FirstByte > status.Total {
+		t.Errorf("Unexpected timings %+v", status)
+	}
+
+	code = http.StatusServiceUnavailable
+	status, err = client.HealthCheck(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 APIError, got %v", err)
+	}
+	if status == nil || status.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the status along with the error, got %+v", status)
+	}
+}
+
+func TestClient_HealthCheckCanceled(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.HealthCheck(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Command fieldgen generates the code mapping the special fields of a struct to their $ keys from its json tags, so
+// the mapping cannot drift from the struct. Run through go generate:
+//
+//	//go:generate go run ./internal/fieldgen -type Person -output person_fields.go person.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type field struct {
+	Name string
+	Key  string
+
+	// Condition on the field, with %s for the field, which has to hold for it to be written. Empty to always write
+	// it, set from the omitempty tag option.
+	NotEmpty string
+}
+
+func main() {
+	typeName := flag.String("type", "", "name of the struct")
+	output := flag.String("output", "", "file to write, defaults to <type>_fields.go")
+	flag.Parse()
+	if *typeName == "" || flag.NArg() != 1 {
+		log.Fatal("usage: fieldgen -type Name [-output file] file.go")
+	}
+	src, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	code, err := generate(src, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "_fields.go"
+	}
+	if err := os.WriteFile(*output, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Returns the fields of typeName in src which have a json tag starting with $.
+func specialFields(src []byte, typeName string) (string, []field, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	var st *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == typeName {
+			st, _ = spec.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	if st == nil {
+		return "", nil, fmt.Errorf("struct %s not found", typeName)
+	}
+	var fields []field
+	for _, x := range st.Fields.List {
+		if x.Tag == nil || len(x.Names) == 0 {
+			continue
+		}
+		tag, err := strconv.Unquote(x.Tag.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		opts := strings.Split(reflect.StructTag(tag).Get("json"), ",")
+		key := opts[0]
+		if !strings.HasPrefix(key, "$") {
+			continue
+		}
+		var notEmpty string
+		for _, opt := range opts[1:] {
+			if opt == "omitempty" {
+				if notEmpty, err = notEmptyCondition(x.Type); err != nil {
+					return "", nil, fmt.Errorf("field %s: %w", x.Names[0].Name, err)
+				}
+			}
+		}
+		for _, name := range x.Names {
+			fields = append(fields, field{Name: name.Name, Key: key, NotEmpty: notEmpty})
+		}
+	}
+	if len(fields) == 0 {
+		return "", nil, errors.New("no special fields found")
+	}
+	return f.Name.Name, fields, nil
+}
+
+// Returns the condition a field of type expr is not empty under, only strings, slices and maps are supported.
+func notEmptyCondition(expr ast.Expr) (string, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.Name == "string" {
+			return `%s != ""`, nil
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "len(%s) > 0", nil
+		}
+	case *ast.MapType:
+		return "len(%s) > 0", nil
+	}
+	return "", errors.New("omitempty is only supported on strings, slices and maps")
+}
+
+func generate(src []byte, typeName string) ([]byte, error) {
+	pkg, fields, err := specialFields(src, typeName)
+	if err != nil {
+		return nil, err
+	}
+	recv := strings.ToLower(typeName[:1])
+	var b bytes.Buffer
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+	p("// Code generated by fieldgen -type %s; DO NOT EDIT.", typeName)
+	p("")
+	p("package %s", pkg)
+	p("")
+	p(`import "encoding/json"`)
+	p("")
+	p("// Keys of the special fields of %s in the order they are declared.", typeName)
+	p("var %sSpecialKeys = []string{", strings.ToLower(typeName[:1])+typeName[1:])
+	for _, f := range fields {
+		p("%q,", f.Key)
+	}
+	p("}")
+	p("")
+	p("// Writes every special field into m keyed by its $ key, leaving out empty omitempty ones.")
+	p("func (%s *%s) writeSpecialFields(m map[string]interface{}) {", recv, typeName)
+	for _, f := range fields {
+		value := recv + "." + f.Name
+		if f.NotEmpty == "" {
+			p("m[%q] = %s", f.Key, value)
+			continue
+		}
+		p("if "+f.NotEmpty+" {", value)
+		p("m[%q] = %s", f.Key, value)
+		p("}")
+	}
+	p("}")
+	p("")
+	p("// Decodes data into the special field stored under key. Returns false if there is no such field.")
+	p("func (%s *%s) unmarshalSpecialField(key string, data []byte) (bool, error) {", recv, typeName)
+	p("switch key {")
+	for _, f := range fields {
+		p("case %q:", f.Key)
+		p("return true, json.Unmarshal(data, &%s.%s)", recv, f.Name)
+	}
+	p("}")
+	p("return false, nil")
+	p("}")
+	p("")
+	p("// Returns the value of the special field stored under key, e.g. %q.", fields[0].Key)
+	p("func (%s *%s) SpecialField(key string) (interface{}, bool) {", recv, typeName)
+	p("switch key {")
+	for _, f := range fields {
+		p("case %q:", f.Key)
+		p("return %s.%s, true", recv, f.Name)
+	}
+	p("}")
+	p("return nil, false")
+	p("}")
+	return format.Source(b.Bytes())
+}
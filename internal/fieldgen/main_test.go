@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src := []byte(`package example
+
+type Thing struct {
+	Id    string
+	Email string   ` + "`json:\"$email\"`" + `
+	Tags  []string ` + "`json:\"$tags,omitempty\"`" + `
+	Other string   ` + "`json:\"other\"`" + `
+}
+`)
+	code, err := generate(src, "Thing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package example",
+		`var thingSpecialKeys = []string{
+	"$email",
+	"$tags",
+}`,
+		`m["$email"] = t.Email`,
+		`if len(t.Tags) > 0 {
+		m["$tags"] = t.Tags
+	}`,
+		`return true, json.Unmarshal(data, &t.Email)`,
+	} {
+		if !bytes.Contains(code, []byte(s)) {
+			t.Errorf("Expected generated code to contain %s, got\n%s", s, code)
+		}
+	}
+	if bytes.Contains(code, []byte("other")) {
+		t.Error("Expected fields without a $ key to be skipped")
+	}
+	bad := bytes.Replace(src, []byte("Tags  []string"), []byte("Tags  int     "), 1)
+	if _, err := generate(bad, "Thing"); err == nil {
+		t.Error("Expected an error for omitempty on an unsupported type")
+	}
+	if _, err := generate(src, "Missing"); err == nil {
+		t.Error("Expected an error for a missing type")
+	}
+}
+
+// Fails when person_fields.go was not regenerated after changing Person.
+func TestPersonFieldsUpToDate(t *testing.T) {
+	src, err := os.ReadFile("../../person.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := generate(src, "Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile("../../person_fields.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Error("person_fields.go is out of date, run go generate")
+	}
+}
@@ -5,16 +5,22 @@ package klaviyo
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -29,17 +35,31 @@ const (
 	ContentHTML     = "text/html"
 	ContentHTMLUTF8 = "text/html; charset=utf-8"
 	ContentJSON     = "application/json"
+	ContentJSONAPI  = "application/vnd.api+json"
+	ContentForm     = "application/x-www-form-urlencoded"
 
 	// They have multiple endpoints unfortunately.
 	Endpoint   = "https://a.klaviyo.com/api"
 	EndpointV1 = "https://a.klaviyo.com/api/v1"
 	EndpointV2 = "https://a.klaviyo.com/api/v2"
+
+	// v3 endpoints meant to be called from browsers and apps, authenticated with the public key only.
+	EndpointClient = "https://a.klaviyo.com/client"
+
+	defaultInListChunkSize = 100
+
+	// Most profiles Klaviyo takes in a single subscribe or unsubscribe request.
+	maxListProfiles = 100
+
+	// Klaviyo's CDN can answer with whole HTML pages, which should not end up in logs.
+	defaultMaxErrorBodySize = 16 * 1024
 )
 
 var (
 	ErrNoPublicKey         = errors.New("missing public key")
+	ErrNoTestKeys          = errors.New("test mode is on but the test account keys are missing")
 	ErrNoPrivateKey        = errors.New("missing private key")
-	ErrNoProfileIdentifier = errors.New("there is no unique profile identifier, must have email or phone number")
+	ErrNoProfileIdentifier = errors.New("there is no unique profile identifier, must have email, phone number or custom id")
 	ErrFailed              = errors.New("request successful, call failed")
 	ErrInvalidOutArg       = errors.New("out arg provided does not match datatype of response")
 )
@@ -56,19 +76,59 @@ func newEndpoint(endpoint, uri string) *url.URL {
 type BadResponseError struct {
 	Body      []byte
 	JSONError error
+
+	// Set from the context, see ContextWithRequestId.
+	RequestId string
 }
 
 func (e *BadResponseError) Error() string {
 	return "bad response"
 }
 
+func (c *Client) maxErrorBodySize() int {
+	if c.MaxErrorBodySize == 0 {
+		return defaultMaxErrorBodySize
+	}
+	return c.MaxErrorBodySize
+}
+
+// Cuts data down to at most max bytes and appends a marker saying how much was left out. The cut is moved back to the
+// start of a UTF-8 sequence so the result stays valid text. A negative max keeps everything.
+func truncateBody(data []byte, max int) []byte {
+	if max < 0 || len(data) <= max {
+		return data
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	res := make([]byte, n, n+32)
+	copy(res, data[:n])
+	return append(res, fmt.Sprintf("... [truncated %d bytes]", len(data)-n)...)
+}
+
 type APIError struct {
 	// Use this to store the raw error response if the response is not parseable.
 	Raw string
 
+	// The HTTP status code returned by Klaviyo.
+	StatusCode int `json:"-"`
+
+	// Parsed from the Retry-After header which Klaviyo sends along with 429 (rate limited) responses.
+	RetryAfter time.Duration `json:"-"`
+
+	// Parsed from the rate limit headers of the response.
+	RateLimit RateLimit `json:"-"`
+
+	// Set from the context, see ContextWithRequestId.
+	RequestId string `json:"-"`
+
 	// Klaviyo's documentation details the usage of "message", but returns "detail" in some instances.
 	Detail  string `json:"detail"`
 	Message string `json:"message"`
+
+	// v3 endpoints return a list of JSON:API error objects instead.
+	Errors []ErrorObject `json:"errors"`
 }
 
 func (e *APIError) Error() string {
@@ -76,6 +136,8 @@ func (e *APIError) Error() string {
 		return e.Message
 	} else if e.Detail != "" {
 		return e.Detail
+	} else if len(e.Errors) > 0 {
+		return e.Errors[0].Error()
 	}
 	return e.Raw
 }
@@ -86,6 +148,7 @@ type Object struct {
 	Object string `json:"object"` // e.g. person, $list
 }
 
+// Client is safe for concurrent use once configured. Do not change its fields while calls are in progress.
 type Client struct {
 	// Sometimes called "token"
 	PublicKey string
@@ -93,53 +156,390 @@ type Client struct {
 	// Sometimes seen as "api_key"
 	PrivateKey string
 
-	// The amount of time an HTTP API call should run for before it times out.
+	// Optional, authenticates v3 calls another way than PrivateKey, e.g. OAuthAuth for Klaviyo apps. The legacy
+	// endpoints still need PrivateKey.
+	Auth Authenticator
+
+	// When set, calls that would change data in Klaviyo are sent to the test account with TestPublicKey and
+	// TestPrivateKey instead, while read calls still go to the main account. Calls fail with ErrNoTestKeys when the
+	// test keys are missing so staging traffic never falls back to production.
+	TestMode       bool
+	TestPublicKey  string
+	TestPrivateKey string
+
+	// Optional, in TestMode this custom attribute is set to true on every Identify and CreateEvent profile, e.g.
+	// "is_test", so test profiles are easy to spot and clean up.
+	TestAttribute string
+
+	// The amount of time an HTTP API call should run for before it times out. Defaults to DefaultRequestTimeout so a
+	// stalled connection cannot hang a call forever, set to a negative value to disable the timeout and rely on the
+	// context alone. See TransportOptions for separate connect and response timeouts.
 	DefaultTimeout time.Duration
+
+	// How many times a request is retried after Klaviyo responds with a 5XX error. Leave as 0 to disable retries. Only
+	// GET, HEAD, OPTIONS, PUT and DELETE requests are retried, see WithUnsafeRetries for the others.
+	MaxRetries int
+
+	// How long to wait before the first retry, doubled for every retry after that. Defaults to 500ms.
+	RetryBackoff time.Duration
+
+	// The longest a call may spend retrying, counted from its first attempt. A retry which would start after that, or
+	// after the deadline of the call's context, is not made and a *RetryBudgetError is returned right away instead of
+	// waiting for nothing. Leave as 0 to only respect the context.
+	RetryBudget time.Duration
+
+	// Optional, stops sending requests for a while after too many consecutive 5XX errors or failed connections. Can be
+	// shared between clients talking to the same account.
+	CircuitBreaker *CircuitBreaker
+
+	// When set, calls that would change data in Klaviyo (Identify, UpdatePerson, Subscribe, CreateEvent, etc.) are not
+	// sent and succeed right away. Read calls still go through. Use this to run sync jobs in staging against production
+	// keys safely.
+	DryRun bool
+
+	// Called with every request skipped because of DryRun. Leave nil to drop them silently.
+	OnDryRun func(DryRunRequest)
+
+	// Used to make the HTTP calls, leave nil to use a transport shared by all clients. See NewTransport for tuning
+	// keep-alives and the vcr package for recording and replaying responses in tests.
+	Transport http.RoundTripper
+
+	// Optional, caches the responses of GetPerson, GetList and GetMetrics for CacheTTL, which defaults to a minute.
+	// UpdatePerson and DeletePerson clear the cached person but changes made any other way, e.g. Identify, are only
+	// seen once the entry expires. Can be shared between clients of the same account.
+	Cache    Cache
+	CacheTTL time.Duration
+
+	// Optional, called with every request and its canonical form before it is sent, see CanonicalRequest and
+	// HMACSigner.
+	Signer RequestSigner
+
+	// Wraps every HTTP request the client sends, in order, the first one being the outermost. Retries go through the
+	// middleware again. See Middleware.
+	Middleware []Middleware
+
+	// Custom attributes added to every Identify and CreateEvent profile, e.g. {"source": "backend"}. A value set in
+	// Person.Attributes takes precedence over the default.
+	DefaultAttributes Attributes
+
+	// Custom attribute names are validated before Identify, UpdatePerson and CreateEvent, returning an
+	// InvalidAttributeError. Set this to fix the names with SanitizeAttributeName instead.
+	SanitizeAttributes bool
+
+	// Optional, the expected type of custom attributes. Identify, UpdatePerson, CreateEvent and the other calls which
+	// send attributes return an *AttributeTypeError for values of another type, or convert them when CoerceAttributes
+	// is set and the value can be converted.
+	Schema           AttributeSchema
+	CoerceAttributes bool
+
+	// Extra values IdentifySafe leaves out when omitting empty values.
+	TrimOptions TrimOptions
+
+	// The most bytes of an error response body kept in APIError and BadResponseError, longer bodies are cut and end
+	// with a truncation marker. Defaults to 16KB, set to a negative value to keep the whole body.
+	MaxErrorBodySize int
+
+	// How many emails, phone numbers and push tokens InList checks per request, defaults to 100 which keeps URLs well
+	// within length limits. Up to InListConcurrency of the requests run at the same time, defaults to 1.
+	InListChunkSize   int
+	InListConcurrency int
+
+	// Optional, used when Klaviyo rejects PrivateKey so keys can be rotated without downtime: create the new key, set it
+	// here, deploy, then swap it into PrivateKey and revoke the old one. OnKeyRotation is called every time the
+	// secondary key is used. Not used in TestMode or with Auth on v3 endpoints.
+	SecondaryPrivateKey string
+	OnKeyRotation       func(KeyRotationEvent)
+
+	// The header request ids set with ContextWithRequestId are sent in, defaults to X-Request-ID.
+	RequestIdHeader string
+
+	// Optional, called after Identify and UpdatePerson succeed with the values they changed on the profile, e.g. to
+	// write an audit log of what the integration changed. The previous values are only known to UpdatePersonDiff,
+	// set FetchBeforeChange to look them up with GetPerson before every other change, at the cost of one more request.
+	// Identify can only fetch them when the person has an Id.
+	OnProfileChange   func(ProfileChange)
+	FetchBeforeChange bool
+
+	// Optional, counts every call by operation and outcome and records its latency, see RequestMetrics.
+	RequestMetrics RequestMetrics
+
+	// Tells the time and waits between retries, leave nil to use the real time. See Clock.
+	Clock Clock
+
+	// Where the account is hosted, e.g. RegionEU. Defaults to RegionUS. Applies to TrackURL and HealthCheck too.
+	Region Region
+
+	// Gzip JSON request bodies larger than 1KB, such as bulk jobs. Responses are always decompressed.
+	CompressRequests bool
+
+	// Return ErrUnknownResponseField when a response has fields the SDK would drop, to notice when Klaviyo changes the
+	// shape of its responses, e.g. in CI against a test account. Not meant for production.
+	StrictDecoding bool
+
+	// Set through WithResponseMeta.
+	meta *ResponseMeta
+
+	// Set through WithRawResponse.
+	raw *RawResponse
+
+	// Set through WithContext and WithTimeout.
+	ctx     context.Context
+	timeout time.Duration
+
+	// Set through WithUnsafeRetries.
+	unsafeRetries bool
+}
+
+// Returns a copy of the client which sends its requests with ctx, so calls are cancelled along with ctx and respect
+// its deadline. Waiting between retries is cut short too.
+//
+//	err := client.WithContext(r.Context()).Identify(&person)
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+// Returns a copy of the client which uses timeout instead of DefaultTimeout, e.g. minutes for bulk calls and a couple
+// of seconds for Identify on a request path. Like DefaultTimeout it applies to each attempt when retrying and a
+// negative timeout disables it.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	cc := *c
+	cc.timeout = timeout
+	return &cc
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// The public key to send with calls that change data, which is the test account's in TestMode.
+func (c *Client) publicKey() (string, error) {
+	if c.TestMode {
+		if c.TestPublicKey == "" {
+			return "", ErrNoTestKeys
+		}
+		return c.TestPublicKey, nil
+	}
+	if c.PublicKey == "" {
+		return "", ErrNoPublicKey
+	}
+	return c.PublicKey, nil
+}
+
+// Waits for d or until the context is done, whichever is first.
+func (c *Client) sleep(d time.Duration) error {
+	return c.clock().Sleep(c.context(), d)
+}
+
+// The request that would have been sent if DryRun was off. The api_key is not included in the URL.
+type DryRunRequest struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+// Legacy identify and track calls change data even though they are sent with GET, while rendering a template and
+// querying metric aggregates are POSTs which do not change anything.
+func isMutation(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return strings.HasSuffix(r.URL.Path, "/identify") || strings.HasSuffix(r.URL.Path, "/track")
+	}
+	return !strings.HasSuffix(r.URL.Path, "/template-render") && !strings.HasSuffix(r.URL.Path, "/metric-aggregates")
+}
+
+func (c *Client) dryRun(r *http.Request) error {
+	if c.OnDryRun == nil {
+		return nil
+	}
+	req := DryRunRequest{
+		Method: r.Method,
+		URL:    r.URL.String(),
+	}
+	if r.Body != nil {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				return err
+			}
+			body = zr
+		}
+		buf, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		req.Body = buf
+	}
+	c.OnDryRun(req)
+	return nil
 }
 
 func (c *Client) doReq(r *http.Request, out interface{}) error {
-	// We are adding the private key on all requests because it is easier to do.
-	if c.PrivateKey == "" {
+	// v3 endpoints authenticate through the Authorization header, everything else uses api_key. Client-side endpoints
+	// only take the public key, which is already in the URL.
+	v3 := r.Header.Get("Revision") != ""
+	clientSide := isClientSide(r)
+	mutation := isMutation(r)
+	test := c.TestMode && mutation
+	privateKey := c.PrivateKey
+	if test && !clientSide {
+		if c.TestPrivateKey == "" {
+			return ErrNoTestKeys
+		}
+		privateKey = c.TestPrivateKey
+	}
+	if privateKey == "" && !clientSide && (!v3 || c.Auth == nil) {
 		return ErrNoPrivateKey
 	}
-	values := r.URL.Query()
-	values.Add("api_key", c.PrivateKey)
-	r.URL.RawQuery = values.Encode()
+	c.regionRequest(r)
+	if c.DryRun && mutation {
+		if err := checkRequestSize(r); err != nil {
+			return err
+		}
+		return c.dryRun(r)
+	}
+	switch {
+	case clientSide:
+	case v3:
+		if err := c.authorize(r, test); err != nil {
+			return err
+		}
+	default:
+		values := r.URL.Query()
+		values.Add("api_key", privateKey)
+		r.URL.RawQuery = values.Encode()
+	}
+	if err := c.sign(r); err != nil {
+		return err
+	}
+	// Only now that api_key is in the URL, it counts towards the limit too.
+	if err := checkRequestSize(r); err != nil {
+		return err
+	}
+	if c.ctx != nil {
+		r = r.WithContext(c.ctx)
+	}
+	if c.Clock != nil {
+		r = r.WithContext(context.WithValue(r.Context(), clockKey{}, c.Clock))
+	}
+	c.setRequestId(r)
+
+	start := c.clock().Now()
+	err := c.retryRoundTrip(r, out)
+	// Test mode and custom authenticators do not use PrivateKey, so there is nothing to rotate.
+	if c.SecondaryPrivateKey != "" && !test && !clientSide && (!v3 || c.Auth == nil) && isAuthError(err) {
+		err = c.retryWithSecondaryKey(r, out, v3, err)
+	}
+	c.measure(r, start, err)
+	return err
+}
+
+// Sends the request, retrying server errors up to MaxRetries times within RetryBudget if it is safe to repeat.
+func (c *Client) retryRoundTrip(r *http.Request, out interface{}) error {
+	start := c.clock().Now()
+	for attempt := 0; ; attempt++ {
+		if c.CircuitBreaker != nil && !c.CircuitBreaker.allow(c.clock()) {
+			return ErrCircuitOpen
+		}
+		err := c.roundTrip(r, out)
+		if c.CircuitBreaker != nil {
+			c.CircuitBreaker.record(!isOutage(err), c.clock())
+		}
+		if attempt >= c.MaxRetries || !isServerError(err) || !c.canRetry(r) {
+			return err
+		}
+		wait := c.backoff(attempt)
+		if err := c.checkRetryBudget(start, attempt+1, wait, err); err != nil {
+			return err
+		}
+		if err := c.sleep(wait); err != nil {
+			return err
+		}
+		if err := resetBody(r); err != nil {
+			return err
+		}
+	}
+}
+
+// Rewinds the body of a request which was already sent so it can be sent again.
+func resetBody(r *http.Request) error {
+	if r.GetBody == nil {
+		return nil
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+	r.Body = body
+	return nil
+}
 
-	client := http.Client{Timeout: c.DefaultTimeout}
-	res, err := client.Do(r)
+// Sends the request once and decodes the response into out.
+func (c *Client) roundTrip(r *http.Request, out interface{}) error {
+	res, err := c.httpClient().Do(r)
 	if err != nil {
 		return err
 	}
-	contentType := res.Header.Get("Content-Type")
+	defer res.Body.Close()
+	if err := decompressResponse(res); err != nil {
+		return err
+	}
+	meta := newResponseMeta(res, c.clock().Now())
+	if c.meta != nil {
+		*c.meta = meta
+	}
+	contentType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	success := res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices
+	// Large responses are decoded straight from the body instead of being buffered first, see stream.go.
+	if s, ok := out.(streamDecoder); ok && success && c.raw == nil && (contentType == ContentJSON || contentType == ContentJSONAPI) {
+		return s.decodeStream(res.Body)
+	}
 	var data []byte
 	if buf, err := io.ReadAll(res.Body); err != nil {
 		return err
 	} else {
 		data = buf
 	}
-	// All of Klaviyo's calls should return 200 otherwise it's an error.
+	if c.raw != nil {
+		*c.raw = newRawResponse(r, res, data)
+	}
+	// All of Klaviyo's calls should return 2XX otherwise it's an error. The legacy endpoints only use 200 but v3
+	// also returns 201, 202 and 204 for creates and jobs.
 	// See more here: https://apidocs.klaviyo.com/reference/api-overview#errors
-	if res.StatusCode != http.StatusOK {
+	if !success {
 		var err APIError
-		if contentType != ContentJSON {
-			err.Message = string(data)
+		body := truncateBody(data, c.maxErrorBodySize())
+		if contentType != ContentJSON && contentType != ContentJSONAPI {
+			err.Message = string(body)
 		} else {
 			if jsonErr := json.NewDecoder(bytes.NewBuffer(data)).Decode(&err); jsonErr != nil {
 				return &BadResponseError{
-					Body:      data,
+					Body:      body,
 					JSONError: jsonErr,
+					RequestId: RequestIdFromContext(r.Context()),
 				}
 			}
 		}
-		err.Raw = string(data)
+		err.Raw = string(body)
+		err.StatusCode = res.StatusCode
+		err.RetryAfter = meta.RetryAfter
+		err.RateLimit = meta.RateLimit
+		err.RequestId = RequestIdFromContext(r.Context())
 		return &err
 	}
-	if out != nil {
+	if out != nil && len(data) > 0 {
 		switch contentType {
-		case ContentJSON:
-			return json.NewDecoder(bytes.NewBuffer(data)).Decode(out)
-		case ContentHTML, ContentHTMLUTF8:
+		case ContentJSON, ContentJSONAPI:
+			if s, ok := out.(streamDecoder); ok {
+				return s.decodeStream(bytes.NewReader(data))
+			}
+			return c.decodeJSON(data, out)
+		case ContentHTML:
 			k, ok := out.(*string)
 			if !ok {
 				return ErrInvalidOutArg
@@ -164,7 +564,7 @@ func (c *Client) sendJSON(method, accept string, url *url.URL, in interface{}, o
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest(method, url.String(), bytes.NewReader(xs))
+	req, err := c.newBodyRequest(method, url.String(), xs)
 	if err != nil {
 		return err
 	}
@@ -175,117 +575,513 @@ func (c *Client) sendJSON(method, accept string, url *url.URL, in interface{}, o
 	return c.doReq(req, out)
 }
 
+// Some v1 endpoints only accept form encoded bodies.
+func (c *Client) sendForm(method, accept string, url *url.URL, values url.Values, out interface{}) error {
+	req, err := http.NewRequest(method, url.String(), strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", accept)
+	req.Header.Add("Content-Type", ContentForm)
+	return c.doReq(req, out)
+}
+
 // https://apidocs.klaviyo.com/reference/track-identify#identify
 // GET https://a.klaviyo.com/api/identify
 // TODO Update Identify to use POST method version as GET is outdated
-func (c *Client) Identify(person *Person) error {
-	return c.IdentifySafe(person, false)
+func (c *Client) Identify(person *Person, opts ...IdentifyOption) error {
+	return c.IdentifySafe(person, false, opts...)
+}
+
+type IdentifyOption func(o *identifyOptions)
+
+type identifyOptions struct {
+	fields []string
+	mask   bool
+	patch  *ProfilePatch
+}
+
+// Only sends the given keys of the person, named the same way as in GetMap, e.g. WithFields("$first_name",
+// "LikesGold"). Listed values are sent even when empty, so this is how to clear a field. The profile identifiers are
+// always sent so Klaviyo can find the person, calling this without keys sends nothing else.
+func WithFields(keys ...string) IdentifyOption {
+	return func(o *identifyOptions) {
+		o.fields = append(o.fields, keys...)
+		o.mask = true
+	}
+}
+
+// Keys which identify a profile in the legacy API.
+var identifierKeys = []string{"$email", "$phone_number", "$id"}
+
+func maskFields(props map[string]interface{}, fields []string) map[string]interface{} {
+	masked := map[string]interface{}{}
+	for _, k := range identifierKeys {
+		if v, ok := props[k]; ok && v != "" {
+			masked[k] = v
+		}
+	}
+	for _, k := range fields {
+		if v, ok := props[k]; ok {
+			masked[k] = v
+		}
+	}
+	return masked
 }
 
 // Use this if you do not want to send values that are not set. This is great for when you want to update a Person
 // without first fetching their information. This will happen if you only have thier email and no Klaviyo Id to utilize.
-func (c *Client) IdentifySafe(person *Person, omit bool) error {
-	if c.PublicKey == "" {
-		return ErrNoPublicKey
+// Fields given through WithFields are sent even when empty.
+func (c *Client) IdentifySafe(person *Person, omit bool, opts ...IdentifyOption) error {
+	var o identifyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	publicKey, err := c.publicKey()
+	if err != nil {
+		return err
 	}
 	if !person.HasProfileIdentifier() {
 		return ErrNoProfileIdentifier
 	}
+	person, err = c.checkAttributes(person)
+	if err != nil {
+		return err
+	}
 
-	props := person.GetMap()
-	if omit {
-		trimEmptyValues(props)
+	props := c.withDefaultAttributes(person).GetMap()
+	if o.mask {
+		props = maskFields(props, o.fields)
+	} else if omit {
+		trimEmptyValues(props, c.TrimOptions)
+	}
+	if o.patch != nil {
+		if err := o.patch.Validate(); err != nil {
+			return err
+		}
+		o.patch.apply(props)
 	}
 
 	payload := struct {
 		Token      string      `json:"token"`
 		Properties interface{} `json:"properties"`
 	}{
-		Token:      c.PublicKey,
+		Token:      publicKey,
 		Properties: props,
 	}
-	buf := bytes.NewBuffer([]byte{})
-	if err := json.NewEncoder(buf).Encode(&payload); err != nil {
+	u, err := newLegacyURL("identify", &payload)
+	if err != nil {
 		return err
 	}
-	u := newEndpoint(Endpoint, "identify")
-	values := u.Query()
-	values.Add("data", base64.StdEncoding.EncodeToString(buf.Bytes()))
-	u.RawQuery = values.Encode()
+	before := c.valuesBefore(person.Id, nil, props)
 	var res string
 	if err := c.send(http.MethodGet, ContentHTML, u, &res); err != nil {
 		return err
 	}
-	if res != "1" {
+	// Nothing was sent so there is no response to check.
+	if res != "1" && !c.DryRun {
 		return ErrFailed
 	}
+	c.auditChange("Identify", person.Id, person, before, props)
 	return nil
 }
 
+// The legacy identify and track endpoints take their payload as base64 encoded JSON in the data parameter.
+func newLegacyURL(uri string, payload interface{}) (*url.URL, error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		return nil, err
+	}
+	u := newEndpoint(Endpoint, uri)
+	values := u.Query()
+	values.Add("data", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	u.RawQuery = values.Encode()
+	return u, nil
+}
+
 // https://apidocs.klaviyo.com/reference/profiles#get-profile
 // GET https://a.klaviyo.com/api/v1/person/person_id
 func (c *Client) GetPerson(personId string) (*Person, error) {
 	var p Person
-	err := c.send(http.MethodGet, ContentJSON, newEndpoint(EndpointV1, fmt.Sprintf("person/%s", personId)), &p)
+	err := c.cached(personCacheKey(personId), &p, func(out interface{}) error {
+		return c.send(http.MethodGet, ContentJSON, newEndpoint(EndpointV1, fmt.Sprintf("person/%s", personId)), out)
+	})
 	return &p, err
 }
 
+// A page of results from a v1 list endpoint.
+type PeoplePage struct {
+	Object
+	Data     []Person `json:"data"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"page_size"`
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+	Total    int      `json:"total"`
+}
+
+// Returns true when there are more pages after this one.
+func (p *PeoplePage) HasMore() bool {
+	return p.End+1 < p.Total
+}
+
+// GET https://a.klaviyo.com/api/v1/people
+// Returns a page of every person in the account, starting at page 0. Count is the page size, leave as 0 to use
+// Klaviyo's default.
+func (c *Client) GetPeople(page, count int) (*PeoplePage, error) {
+	u := newEndpoint(EndpointV1, "people")
+	values := u.Query()
+	values.Add("page", strconv.Itoa(page))
+	if count > 0 {
+		values.Add("count", strconv.Itoa(count))
+	}
+	u.RawQuery = values.Encode()
+	var res PeoplePage
+	err := c.send(http.MethodGet, ContentJSON, u, &res)
+	return &res, err
+}
+
+// Calls fn for every person in the account, going through all the pages of GetPeople. Returning an error from fn
+// stops the iteration and the error is returned.
+func (c *Client) EachPerson(count int, fn func(*Person) error) error {
+	return c.EachPersonFrom(&Cursor{}, count, fn)
+}
+
+// Same as EachPerson starting from cursor.Page, cursor is kept up to date so the iteration can be resumed. Keep count
+// the same when resuming since pages are numbered by their size.
+func (c *Client) EachPersonFrom(cursor *Cursor, count int, fn func(*Person) error) error {
+	for ; !cursor.Done; cursor.Page++ {
+		res, err := c.GetPeople(cursor.Page, count)
+		if err != nil {
+			return err
+		}
+		for i := range res.Data {
+			if err := fn(&res.Data[i]); err != nil {
+				return err
+			}
+		}
+		if !res.HasMore() || len(res.Data) == 0 {
+			cursor.Done = true
+		}
+	}
+	return nil
+}
+
 // https://apidocs.klaviyo.com/reference/profiles#update-profile
 // PUT https://a.klaviyo.com/api/v1/person/person_id
 // Only works to update a persons attributes after they have been identified.
 func (c *Client) UpdatePerson(person *Person) error {
-	u := newEndpoint(EndpointV1, fmt.Sprintf("person/%s", person.Id))
+	p, err := c.checkAttributes(person)
+	if err != nil {
+		return err
+	}
+	return c.updatePerson(person.Id, p.GetMap(), nil, person)
+}
+
+// Same as UpdatePerson but only sends the fields and attributes which changed between old and new, see Person.Diff.
+// Nothing is sent when there are no changes. The id of old is used if new does not have one.
+func (c *Client) UpdatePersonDiff(old, new *Person) error {
+	id := new.Id
+	if id == "" {
+		id = old.Id
+	}
+	checked, err := c.checkAttributes(new)
+	if err != nil {
+		return err
+	}
+	diff := old.Diff(checked)
+	if len(diff) == 0 {
+		return nil
+	}
+	return c.updatePerson(id, diff, old, new)
+}
+
+// Sends m as the new values of the profile and decodes the updated profile into out. old is the profile before the
+// change when the caller has it, for OnProfileChange.
+func (c *Client) updatePerson(id string, m map[string]interface{}, old, out *Person) error {
+	before := c.valuesBefore(id, old, m)
+	c.uncache(personCacheKey(id))
+	u := newEndpoint(EndpointV1, fmt.Sprintf("person/%s", id))
 	values := u.Query()
-	for k, v := range person.GetMap() {
+	for k, v := range m {
 		values.Add(k, fmt.Sprintf("%v", v))
 	}
 	u.RawQuery = values.Encode()
-	return c.send(http.MethodPut, ContentJSON, u, person)
+	if err := c.send(http.MethodPut, ContentJSON, u, out); err != nil {
+		return err
+	}
+	c.auditChange("UpdatePerson", id, out, before, m)
+	return nil
 }
 
 // https://apidocs.klaviyo.com/reference/lists-segments#subscribe
 // POST https://a.klaviyo.com/api/v2/list/list_id/subscribe
+// Lists using double opt-in do not return people until they confirm, so an empty result is not a failure. Use
+// SubscribeWithStatus to tell who was already subscribed and who is pending.
 func (c *Client) Subscribe(listId string, emails, phoneNumbers []string) ([]ListPerson, error) {
+	profiles := []SubscribeProfile{}
+	for _, email := range emails {
+		profiles = append(profiles, SubscribeProfile{Email: email})
+	}
+	for _, num := range phoneNumbers {
+		profiles = append(profiles, SubscribeProfile{PhoneNumber: num})
+	}
+	return c.SubscribeProfiles(listId, profiles)
+}
+
+// Subscribes a phone number to a list with the consent properties Klaviyo requires for SMS. Method describes how
+// consent was collected, e.g. "Website Form", and consentTimestamp is when it was given.
+// Lists using double opt-in do not return the person until they confirm by text, in that case the returned
+// ListPerson is nil.
+func (c *Client) SubscribeSMS(listId, phone string, consentTimestamp time.Time, method string) (*ListPerson, error) {
+	res, err := c.SubscribeProfiles(listId, []SubscribeProfile{{
+		PhoneNumber:      phone,
+		Consent:          []string{ConsentSMS},
+		ConsentMethod:    method,
+		ConsentTimestamp: consentTimestamp,
+	}})
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+	return &res[0], nil
+}
+
+// A profile to subscribe to a list along with where their consent came from.
+type SubscribeProfile struct {
+	Email       string
+	PhoneNumber string
+
+	// Channels consented to, e.g. ConsentEmail and ConsentSMS.
+	Consent []string
+
+	// How consent was collected, e.g. "Website Form".
+	ConsentMethod string
+
+	// When consent was given, leave empty if unknown.
+	ConsentTimestamp time.Time
+
+	// Id of the sign-up form consent was given through.
+	ConsentFormId string
+
+	// Custom attributes to set on the profile.
+	Attributes Attributes
+}
+
+func (p *SubscribeProfile) profile() map[string]interface{} {
+	m := map[string]interface{}{}
+	for k, v := range p.Attributes {
+		m[k] = v
+	}
+	if p.Email != "" {
+		m["email"] = p.Email
+	}
+	if p.PhoneNumber != "" {
+		m["phone_number"] = p.PhoneNumber
+		m["sms_consent"] = true
+	}
+	if len(p.Consent) > 0 {
+		m["$consent"] = p.Consent
+	}
+	if p.ConsentMethod != "" {
+		m["$consent_method"] = p.ConsentMethod
+	}
+	if !p.ConsentTimestamp.IsZero() {
+		m["$consent_timestamp"] = p.ConsentTimestamp.UTC().Format(time.RFC3339)
+	}
+	if p.ConsentFormId != "" {
+		m["$consent_form_id"] = p.ConsentFormId
+	}
+	return m
+}
+
+// https://apidocs.klaviyo.com/reference/lists-segments#subscribe
+// POST https://a.klaviyo.com/api/v2/list/list_id/subscribe
+// Same as Subscribe but records how and when each profile consented, which auditors need to see. Klaviyo takes up to
+// 100 profiles per request so longer inputs are sent in several requests. When some of them fail the members from the
+// others are returned along with a *BatchError keyed by the index of each profile which was not subscribed.
+func (c *Client) SubscribeProfiles(listId string, profiles []SubscribeProfile) ([]ListPerson, error) {
+	xs := make([]map[string]interface{}, len(profiles))
+	for i := range profiles {
+		p := profiles[i]
+		checked, err := c.checkAttributes(&Person{Attributes: p.Attributes})
+		if err != nil {
+			return nil, err
+		}
+		p.Attributes = checked.Attributes
+		xs[i] = p.profile()
+	}
+	if len(xs) <= maxListProfiles {
+		return c.subscribe(listId, xs)
+	}
+	var res []ListPerson
+	batchErr := &BatchError{Errors: map[int]error{}, Total: len(xs)}
+	runChunks(len(xs), maxListProfiles, 1, func(_, start, end int) error {
+		members, err := c.subscribe(listId, xs[start:end])
+		if err != nil {
+			for i := start; i < end; i++ {
+				batchErr.Errors[i] = err
+			}
+		}
+		res = append(res, members...)
+		return err
+	})
+	if len(batchErr.Errors) > 0 {
+		return res, batchErr
+	}
+	return res, nil
+}
+
+func (c *Client) subscribe(listId string, profiles []map[string]interface{}) ([]ListPerson, error) {
 	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/subscribe", listId))
 	var res []ListPerson
 	type payload struct {
 		Profiles []map[string]interface{} `json:"profiles"`
 	}
 	p := payload{
-		Profiles: []map[string]interface{}{},
+		Profiles: profiles,
 	}
-	for _, email := range emails {
-		p.Profiles = append(p.Profiles, map[string]interface{}{
-			"email": email,
-		})
+	err := c.sendJSON(http.MethodPost, ContentJSON, u, &p, &res)
+	return res, err
+}
+
+type SubscribeStatus string
+
+const (
+	// The profile was added to the list by this call.
+	SubscribeNew SubscribeStatus = "subscribed"
+
+	// The profile was already a member of the list.
+	SubscribeExisting SubscribeStatus = "already_subscribed"
+
+	// The list uses double opt-in and the profile was sent a confirmation, they become a member once they confirm.
+	SubscribePending SubscribeStatus = "pending"
+)
+
+// What happened to one of the profiles passed to SubscribeWithStatus.
+type SubscribeResult struct {
+	Profile SubscribeProfile
+	Status  SubscribeStatus
+
+	// The list member, nil while pending.
+	Member *ListPerson
+}
+
+// Whether the profile is a member of the list now, false while waiting on the double opt-in confirmation.
+func (r SubscribeResult) Confirmed() bool {
+	return r.Status != SubscribePending
+}
+
+type SubscribeResponse struct {
+	// In the same order as the profiles passed in. Profiles in batches which failed are left out.
+	Results []SubscribeResult
+
+	// Whether the list uses double opt-in, see Client.DoubleOptIn.
+	DoubleOptIn bool
+}
+
+// Returns the results with the given status.
+func (r *SubscribeResponse) WithStatus(status SubscribeStatus) []SubscribeResult {
+	var res []SubscribeResult
+	for _, x := range r.Results {
+		if x.Status == status {
+			res = append(res, x)
+		}
 	}
-	for _, num := range phoneNumbers {
-		p.Profiles = append(p.Profiles, map[string]interface{}{
-			"phone_number": num,
-			"sms_consent":  true,
-		})
+	return res
+}
+
+// Same as SubscribeProfiles but tells apart profiles which were newly subscribed, were already subscribed or are
+// waiting on double opt-in, e.g. to show the right message after a sign-up form. Klaviyo's subscribe response does
+// not say so itself, so the list's opt-in process is looked up with DoubleOptIn and its members are checked with
+// InList first, which costs two extra requests. Profiles missing from the response are only pending on double opt-in
+// lists. Errors are the same as SubscribeProfiles, on a *BatchError the response holds the profiles which went
+// through.
+func (c *Client) SubscribeWithStatus(listId string, profiles []SubscribeProfile) (*SubscribeResponse, error) {
+	doubleOptIn, err := c.DoubleOptIn(listId)
+	if err != nil {
+		return nil, err
+	}
+	var emails, phoneNumbers []string
+	for _, p := range profiles {
+		if p.Email != "" {
+			emails = append(emails, p.Email)
+		} else if p.PhoneNumber != "" {
+			phoneNumbers = append(phoneNumbers, p.PhoneNumber)
+		}
+	}
+	existing, err := c.InList(listId, emails, phoneNumbers, nil)
+	if err != nil {
+		return nil, err
+	}
+	members, err := c.SubscribeProfiles(listId, profiles)
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+
+	before := map[string]bool{}
+	for _, m := range existing {
+		before[memberKey(m.Email, m.PhoneNumber)] = true
+	}
+	after := map[string]*ListPerson{}
+	for i := range members {
+		after[memberKey(members[i].Email, members[i].PhoneNumber)] = &members[i]
+	}
+	res := &SubscribeResponse{DoubleOptIn: doubleOptIn}
+	for i, p := range profiles {
+		if batchErr != nil && batchErr.Errors[i] != nil {
+			continue
+		}
+		key := memberKey(p.Email, p.PhoneNumber)
+		result := SubscribeResult{Profile: p, Member: after[key], Status: SubscribeNew}
+		if before[key] {
+			result.Status = SubscribeExisting
+		} else if result.Member == nil && doubleOptIn {
+			result.Status = SubscribePending
+		}
+		res.Results = append(res.Results, result)
 	}
-	err := c.sendJSON(http.MethodPost, ContentJSON, u, &p, &res)
 	return res, err
 }
 
+// Matches list members by email, which Klaviyo treats case insensitively, or by phone number when there is none.
+func memberKey(email, phoneNumber string) string {
+	if email != "" {
+		return "email:" + strings.ToLower(email)
+	}
+	return "phone:" + phoneNumber
+}
+
 // https://apidocs.klaviyo.com/reference/lists-segments#unsubscribe
 // DELETE https://a.klaviyo.com/api/v2/list/list_id/subscribe
+// Sends up to 100 identifiers per request like SubscribeProfiles. Failures are returned as a *BatchError keyed by the
+// index of the identifier in emails, phoneNumbers and pushTokens put one after the other.
 func (c *Client) Unsubscribe(listId string, emails, phoneNumbers, pushTokens []string) error {
-	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/subscribe", listId))
-	toc := map[string][]string{
-		"emails":        emails,
-		"phone_numbers": phoneNumbers,
-		"push_tokens":   pushTokens,
+	ids := listIdentifiers(emails, phoneNumbers, pushTokens)
+	if len(ids) <= maxListProfiles {
+		return c.unsubscribe(listId, ids)
 	}
-	m := map[string][]string{}
-	for k, arr := range toc {
-		if len(arr) > 0 {
-			m[k] = make([]string, 0)
-		}
-		for _, x := range arr {
-			m[k] = append(m[k], x)
+	batchErr := &BatchError{Errors: map[int]error{}, Total: len(ids)}
+	runChunks(len(ids), maxListProfiles, 1, func(_, start, end int) error {
+		err := c.unsubscribe(listId, ids[start:end])
+		if err != nil {
+			for i := start; i < end; i++ {
+				batchErr.Errors[i] = err
+			}
 		}
+		return err
+	})
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+func (c *Client) unsubscribe(listId string, ids []listIdentifier) error {
+	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/subscribe", listId))
+	m := map[string][]string{}
+	for _, id := range ids {
+		m[id.param] = append(m[id.param], id.value)
 	}
 	return c.sendJSON(http.MethodDelete, ContentNone, u, m, nil)
 }
@@ -294,25 +1090,68 @@ type ListPerson struct {
 	Id          string `json:"id"`
 	Email       string `json:"email"`
 	PhoneNumber string `json:"phone_number"`
+	PushToken   string `json:"push_token"`
 	Created     string `json:"created"`
 }
 
 // https://apidocs.klaviyo.com/reference/lists-segments#list-membership
 // GET https://a.klaviyo.com/api/v2/list/list_id/members
+// Long inputs are split into several requests of InListChunkSize identifiers, see Client.InListChunkSize. The members
+// are returned in the order of the requests and the first error stops the whole call.
 func (c *Client) InList(listId string, emails, phoneNumbers, pushTokens []string) ([]ListPerson, error) {
-	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/members", listId))
-	if len(emails) == 0 && len(phoneNumbers) == 0 && len(pushTokens) == 0 {
+	ids := listIdentifiers(emails, phoneNumbers, pushTokens)
+	if len(ids) == 0 {
 		return nil, nil
 	}
-	values := u.Query()
-	if len(emails) > 0 {
-		values.Add("emails", strings.Join(emails, ","))
+	size := c.InListChunkSize
+	if size <= 0 {
+		size = defaultInListChunkSize
+	}
+	results := make([][]ListPerson, (len(ids)+size-1)/size)
+	errs := runChunks(len(ids), size, c.InListConcurrency, func(i, start, end int) error {
+		var err error
+		results[i], err = c.inList(listId, ids[start:end])
+		return err
+	})
+	var res []ListPerson
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, results[i]...)
+	}
+	return res, nil
+}
+
+// An email, phone number or push token along with the parameter it is sent in.
+type listIdentifier struct {
+	param string
+	value string
+}
+
+func listIdentifiers(emails, phoneNumbers, pushTokens []string) []listIdentifier {
+	var ids []listIdentifier
+	for _, x := range emails {
+		ids = append(ids, listIdentifier{"emails", x})
+	}
+	for _, x := range phoneNumbers {
+		ids = append(ids, listIdentifier{"phone_numbers", x})
+	}
+	for _, x := range pushTokens {
+		ids = append(ids, listIdentifier{"push_tokens", x})
 	}
-	if len(phoneNumbers) > 0 {
-		values.Add("phone_numbers", strings.Join(phoneNumbers, ","))
+	return ids
+}
+
+func (c *Client) inList(listId string, ids []listIdentifier) ([]ListPerson, error) {
+	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/members", listId))
+	params := map[string][]string{}
+	for _, id := range ids {
+		params[id.param] = append(params[id.param], id.value)
 	}
-	if len(pushTokens) > 0 {
-		values.Add("push_tokens", strings.Join(pushTokens, ","))
+	values := u.Query()
+	for k, xs := range params {
+		values.Add(k, strings.Join(xs, ","))
 	}
 	u.RawQuery = values.Encode()
 	var res []ListPerson
@@ -320,20 +1159,92 @@ func (c *Client) InList(listId string, emails, phoneNumbers, pushTokens []string
 	return res, err
 }
 
-func trimEmptyValues(m map[string]interface{}) map[string]interface{} {
+// https://apidocs.klaviyo.com/reference/lists-segments#members-all
+// GET https://a.klaviyo.com/api/v2/group/group_id/members/all
+// Works for both lists and segments. Pass the returned marker into the next call to get the next page of members, a
+// marker of 0 means there are no more pages.
+func (c *Client) GetGroupMembers(groupId string, marker int) ([]ListPerson, int, error) {
+	u := newEndpoint(EndpointV2, fmt.Sprintf("group/%s/members/all", groupId))
+	if marker != 0 {
+		values := u.Query()
+		values.Add("marker", strconv.Itoa(marker))
+		u.RawQuery = values.Encode()
+	}
+	var res struct {
+		Records []ListPerson `json:"records"`
+		Marker  int          `json:"marker"`
+	}
+	err := c.send(http.MethodGet, ContentJSON, u, &res)
+	return res.Records, res.Marker, err
+}
+
+// Which values are left out of IdentifySafe payloads when omit is set, on top of nil and empty strings which are always
+// left out. Nested maps, e.g. custom attributes holding objects, are trimmed the same way.
+type TrimOptions struct {
+	// Slices without elements, e.g. an unset $consent.
+	EmptySlices bool
+
+	// Maps left without keys, after trimming their contents.
+	EmptyMaps bool
+
+	// Zero time.Time and KTime values.
+	ZeroTime bool
+
+	// Numbers equal to 0, such as unset $latitude and $longitude. Only use this when 0 is never a meaningful value.
+	ZeroNumbers bool
+}
+
+// Trims m in place. Nested maps are trimmed as copies since GetMap only copies the top level, the nested ones still
+// belong to the caller's Attributes.
+func trimEmptyValues(m map[string]interface{}, opts TrimOptions) map[string]interface{} {
 	for key, val := range m {
-		var kill bool
-		switch val.(type) {
-		case nil:
-			kill = true
-		case string:
-			if val.(string) == "" {
-				kill = true
-			}
+		switch v := val.(type) {
+		case map[string]interface{}:
+			val = trimEmptyValues(copyValues(v), opts)
+		case Attributes:
+			val = Attributes(trimEmptyValues(copyValues(v), opts))
 		}
-		if kill {
+		if isEmptyValue(val, opts) {
 			delete(m, key)
+		} else {
+			m[key] = val
 		}
 	}
 	return m
-}
\ No newline at end of file
+}
+
+func copyValues(m map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+// Nested maps count as empty without keys, trimEmptyValues trims them first.
+func isEmptyValue(val interface{}, opts TrimOptions) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0 && opts.EmptyMaps
+	case Attributes:
+		return len(v) == 0 && opts.EmptyMaps
+	case time.Time:
+		return opts.ZeroTime && v.IsZero()
+	case KTime:
+		return opts.ZeroTime && v.IsZero()
+	case KFloat, KInt, int, int64, float64:
+		return opts.ZeroNumbers && reflect.ValueOf(v).IsZero()
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return opts.EmptySlices && rv.Len() == 0
+	case reflect.Ptr:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -1,7 +1,17 @@
 package klaviyo
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -24,6 +34,30 @@ func newTestClient() *Client {
 	}
 }
 
+// Sends every request to the test server instead of Klaviyo.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Returns a client which sends every request to handler instead of Klaviyo, for tests which do not need live data.
+func newMockClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &Client{
+		PublicKey:      "public",
+		PrivateKey:     "private",
+		DefaultTimeout: time.Second,
+		Transport:      &rewriteTransport{target: target},
+	}
+}
+
 func TestClient_Identify(t *testing.T) {
 	client := newTestClient()
 	p := newTestPerson()
@@ -127,3 +161,614 @@ func TestClient_Unsubscribe(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestClient_DryRun(t *testing.T) {
+	var reqs []DryRunRequest
+	client := &Client{
+		PublicKey:  "public",
+		PrivateKey: "private",
+		DryRun:     true,
+		OnDryRun: func(r DryRunRequest) {
+			reqs = append(reqs, r)
+		},
+	}
+	p := newTestPerson()
+	if err := client.Identify(&p); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Unsubscribe("ABC123", []string{p.Email}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("Expected 2 captured requests, got %d", len(reqs))
+	}
+	if reqs[0].Method != http.MethodGet || !strings.HasPrefix(reqs[0].URL, Endpoint+"/identify") {
+		t.Errorf("Unexpected identify request %s %s", reqs[0].Method, reqs[0].URL)
+	}
+	if reqs[1].Method != http.MethodDelete || !strings.Contains(string(reqs[1].Body), p.Email) {
+		t.Errorf("Unexpected unsubscribe request %s %s", reqs[1].Method, reqs[1].Body)
+	}
+	for _, r := range reqs {
+		if strings.Contains(r.URL, client.PrivateKey) {
+			t.Error("Private key should not be captured")
+		}
+	}
+}
+
+func TestClient_SubscribeSMS(t *testing.T) {
+	consentAt := time.Date(2022, 11, 8, 13, 14, 15, 0, time.UTC)
+	doubleOptIn := false
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var p struct {
+			Profiles []map[string]interface{} `json:"profiles"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Profiles) != 1 {
+			t.Fatalf("Expected 1 profile, got %d", len(p.Profiles))
+		}
+		profile := p.Profiles[0]
+		if profile["sms_consent"] != true || profile["$consent_method"] != "Website Form" {
+			t.Errorf("Unexpected profile %v", profile)
+		}
+		if profile["$consent_timestamp"] != "2022-11-08T13:14:15Z" {
+			t.Errorf("Unexpected consent timestamp %v", profile["$consent_timestamp"])
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		if doubleOptIn {
+			w.Write([]byte(`[]`))
+		} else {
+			w.Write([]byte(`[{"id": "abc", "phone_number": "+1234567890"}]`))
+		}
+	})
+	lp, err := client.SubscribeSMS(testListId, "+1234567890", consentAt, "Website Form")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp == nil || lp.Id != "abc" {
+		t.Errorf("Unexpected ListPerson %+v", lp)
+	}
+
+	doubleOptIn = true
+	lp, err = client.SubscribeSMS(testListId, "+1234567890", consentAt, "Website Form")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp != nil {
+		t.Error("Expected nil ListPerson for double opt-in lists")
+	}
+}
+
+func TestClient_SubscribeProfiles(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var p struct {
+			Profiles []map[string]interface{} `json:"profiles"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Profiles) != 1 {
+			t.Fatalf("Expected 1 profile, got %d", len(p.Profiles))
+		}
+		profile := p.Profiles[0]
+		if profile["email"] != "kitty@monstercat.com" || profile["$consent_form_id"] != "F1" || profile["Plan"] != "gold" {
+			t.Errorf("Unexpected profile %v", profile)
+		}
+		if _, ok := profile["sms_consent"]; ok {
+			t.Error("Did not expect sms_consent without a phone number")
+		}
+		if _, ok := profile["$consent_timestamp"]; ok {
+			t.Error("Did not expect an empty consent timestamp")
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`[{"id": "abc", "email": "kitty@monstercat.com"}]`))
+	})
+	res, err := client.SubscribeProfiles("LIST1", []SubscribeProfile{{
+		Email:         "kitty@monstercat.com",
+		Consent:       []string{ConsentEmail},
+		ConsentMethod: "Website Form",
+		ConsentFormId: "F1",
+		Attributes:    Attributes{"Plan": "gold"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Id != "abc" {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	_, err = client.SubscribeProfiles("LIST1", []SubscribeProfile{{Email: "kitty@monstercat.com", Attributes: Attributes{"$bad": 1}}})
+	if _, ok := err.(*InvalidAttributeError); !ok {
+		t.Errorf("Expected InvalidAttributeError, got %v", err)
+	}
+}
+
+func TestClient_TestMode(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/identify":
+			var payload struct {
+				Token      string                 `json:"token"`
+				Properties map[string]interface{} `json:"properties"`
+			}
+			data, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.Token != "test-public" || payload.Properties["is_test"] != true {
+				t.Errorf("Unexpected payload %+v", payload)
+			}
+			if r.URL.Query().Get("api_key") != "test-private" {
+				t.Errorf("Unexpected api_key %s", r.URL.Query().Get("api_key"))
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("1"))
+		case "/api/events":
+			if r.Header.Get("Authorization") != "Klaviyo-API-Key test-private" {
+				t.Errorf("Unexpected authorization %s", r.Header.Get("Authorization"))
+			}
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/metrics":
+			// Reads still go to the main account.
+			if r.Header.Get("Authorization") != "Klaviyo-API-Key private" {
+				t.Errorf("Unexpected authorization %s", r.Header.Get("Authorization"))
+			}
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": [], "links": {}}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	client.TestMode = true
+	client.TestAttribute = "is_test"
+	person := &Person{Email: "kitty@monstercat.com"}
+	if err := client.Identify(person); err != ErrNoTestKeys {
+		t.Errorf("Expected ErrNoTestKeys, got %v", err)
+	}
+	if err := client.CreateEvent(&NewEvent{Metric: "Viewed Release", Person: person}); err != ErrNoTestKeys {
+		t.Errorf("Expected ErrNoTestKeys, got %v", err)
+	}
+
+	client.TestPublicKey = "test-public"
+	client.TestPrivateKey = "test-private"
+	if err := client.Identify(person); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateEvent(&NewEvent{Metric: "Viewed Release", Person: person}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMetrics(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := person.Attributes["is_test"]; ok {
+		t.Error("The test attribute should not be added to the caller's person")
+	}
+}
+
+func TestClient_IdentifyWithFields(t *testing.T) {
+	var props map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("data"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload struct {
+			Properties map[string]interface{} `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatal(err)
+		}
+		props = payload.Properties
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("1"))
+	})
+	p := &Person{
+		Email:      "kitty@monstercat.com",
+		FirstName:  "Kitty",
+		Attributes: Attributes{"LikesGold": true, "Plan": "gold"},
+	}
+	if err := client.IdentifySafe(p, true, WithFields("$last_name", "LikesGold")); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"$email":     "kitty@monstercat.com",
+		"$last_name": "",
+		"LikesGold":  true,
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("Expected %v, got %v", expected, props)
+	}
+}
+
+func TestClient_DefaultAttributes(t *testing.T) {
+	var props map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/identify":
+			data, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var payload struct {
+				Properties map[string]interface{} `json:"properties"`
+			}
+			if err := json.Unmarshal(data, &payload); err != nil {
+				t.Fatal(err)
+			}
+			props = payload.Properties
+			w.Header().Set("Content-Type", ContentHTML)
+			w.Write([]byte("1"))
+		case "/api/events":
+			var doc struct {
+				Data struct {
+					Attributes struct {
+						Profile struct {
+							Data struct {
+								Attributes struct {
+									Properties map[string]interface{} `json:"properties"`
+								} `json:"attributes"`
+							} `json:"data"`
+						} `json:"profile"`
+					} `json:"attributes"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			props = doc.Data.Attributes.Profile.Data.Attributes.Properties
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+	client.DefaultAttributes = Attributes{"source": "backend", attrIsTest: false}
+
+	p := newTestPerson()
+	if err := client.Identify(&p); err != nil {
+		t.Fatal(err)
+	}
+	if props["source"] != "backend" {
+		t.Error("Expected the default source attribute")
+	}
+	if props[attrIsTest] != true {
+		t.Error("Person attributes should override defaults")
+	}
+
+	props = nil
+	if err := client.CreateEvent(&NewEvent{Metric: "Test", Person: &p}); err != nil {
+		t.Fatal(err)
+	}
+	if props["source"] != "backend" || props[attrIsTest] != true {
+		t.Errorf("Unexpected event profile properties %v", props)
+	}
+	if _, ok := p.Attributes["source"]; ok {
+		t.Error("Default attributes should not be added to the person")
+	}
+}
+
+func TestClient_EachPerson(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/people" || r.URL.Query().Get("count") != "2" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("page") {
+		case "0":
+			w.Write([]byte(`{"object": "$list", "page": 0, "page_size": 2, "start": 0, "end": 1, "total": 3, "data": [
+				{"object": "person", "id": "a", "$email": "a@monstercat.com"},
+				{"object": "person", "id": "b", "$email": "b@monstercat.com", "LikesGold": true}
+			]}`))
+		case "1":
+			w.Write([]byte(`{"object": "$list", "page": 1, "page_size": 2, "start": 2, "end": 2, "total": 3, "data": [
+				{"object": "person", "id": "c", "$email": "c@monstercat.com"}
+			]}`))
+		default:
+			t.Errorf("Unexpected page %s", r.URL.Query().Get("page"))
+		}
+	})
+	var ids []string
+	err := client.EachPerson(2, func(p *Person) error {
+		ids = append(ids, p.Id)
+		if p.Id == "b" && !p.Attributes.ParseBool("LikesGold") {
+			t.Error("Expected attributes to be decoded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Errorf("Unexpected people %v", ids)
+	}
+}
+
+func TestTrimEmptyValues(t *testing.T) {
+	newProps := func() map[string]interface{} {
+		return map[string]interface{}{
+			"$email":    "kitty@monstercat.com",
+			"$city":     "",
+			"$consent":  []string(nil),
+			"$latitude": KFloat(0),
+			"$source":   KInt(0),
+			"Count":     0,
+			"Signup":    time.Time{},
+			"Last":      KTime{},
+			"Nested":    map[string]interface{}{"a": "", "b": nil},
+			"Kept":      map[string]interface{}{"a": "", "b": 1},
+		}
+	}
+
+	props := trimEmptyValues(newProps(), TrimOptions{})
+	if _, ok := props["$city"]; ok {
+		t.Error("Empty strings should always be trimmed")
+	}
+	if len(props) != 9 {
+		t.Errorf("Expected only the empty string to be trimmed by default, got %v", props)
+	}
+	if len(props["Nested"].(map[string]interface{})) != 0 {
+		t.Error("Nested maps should be trimmed")
+	}
+
+	props = trimEmptyValues(newProps(), TrimOptions{EmptySlices: true, EmptyMaps: true, ZeroTime: true, ZeroNumbers: true})
+	expected := map[string]interface{}{
+		"$email": "kitty@monstercat.com",
+		"Kept":   map[string]interface{}{"b": 1},
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("Expected %v, got %v", expected, props)
+	}
+}
+
+func TestClient_IdentifySafeKeepsInput(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentHTML)
+		w.Write([]byte("1"))
+	})
+	client.TrimOptions = TrimOptions{EmptyMaps: true}
+	p := &Person{Email: "kitty@monstercat.com", Attributes: Attributes{
+		"Nested": map[string]interface{}{"a": "", "b": 1},
+		"Empty":  Attributes{"a": nil},
+	}}
+	if err := client.IdentifySafe(p, true); err != nil {
+		t.Fatal(err)
+	}
+	expected := Attributes{
+		"Nested": map[string]interface{}{"a": "", "b": 1},
+		"Empty":  Attributes{"a": nil},
+	}
+	if !reflect.DeepEqual(p.Attributes, expected) {
+		t.Errorf("Expected the person's attributes not to change, got %v", p.Attributes)
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	if s := string(truncateBody([]byte("short"), 10)); s != "short" {
+		t.Errorf("Expected body to be kept, got %s", s)
+	}
+	if s := string(truncateBody([]byte("abcdef"), 3)); s != "abc... [truncated 3 bytes]" {
+		t.Errorf("Unexpected truncated body %s", s)
+	}
+	if s := string(truncateBody([]byte("aé"), 2)); s != "a... [truncated 2 bytes]" {
+		t.Errorf("Expected the cut to keep runes whole, got %s", s)
+	}
+	if s := string(truncateBody([]byte("abcdef"), -1)); s != "abcdef" {
+		t.Errorf("Expected a negative limit to keep everything, got %s", s)
+	}
+}
+
+func TestClient_ErrorBodySize(t *testing.T) {
+	page := strings.Repeat("<p>down</p>", 10000)
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("bad") != "" {
+			w.Header().Set("Content-Type", ContentJSON)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("{" + page))
+			return
+		}
+		w.Header().Set("Content-Type", ContentHTML)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	})
+	client.MaxErrorBodySize = 100
+
+	u := newEndpoint(EndpointV2, "lists")
+	err := client.send(http.MethodGet, ContentJSON, u, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if !strings.HasSuffix(apiErr.Raw, "[truncated 109900 bytes]") || !strings.HasPrefix(apiErr.Raw, page[:100]) {
+		t.Errorf("Unexpected Raw %s", apiErr.Raw)
+	}
+	if apiErr.Message != apiErr.Raw {
+		t.Errorf("Expected Message to be truncated too, got %s", apiErr.Message)
+	}
+
+	u.RawQuery = "bad=1"
+	err = client.send(http.MethodGet, ContentJSON, u, nil)
+	var badErr *BadResponseError
+	if !errors.As(err, &badErr) {
+		t.Fatalf("Expected a BadResponseError, got %v", err)
+	}
+	if len(badErr.Body) > 200 {
+		t.Errorf("Expected Body to be truncated, got %d bytes", len(badErr.Body))
+	}
+}
+
+func TestClient_InListChunks(t *testing.T) {
+	var mu sync.Mutex
+	var queries []url.Values
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+		var res []ListPerson
+		for _, email := range strings.Split(r.URL.Query().Get("emails"), ",") {
+			if email != "" {
+				res = append(res, ListPerson{Email: email})
+			}
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		json.NewEncoder(w).Encode(res)
+	})
+	client.InListChunkSize = 2
+	client.InListConcurrency = 2
+
+	emails := []string{"a@monstercat.com", "b@monstercat.com", "c@monstercat.com"}
+	xs, err := client.InList("LIST1", emails, []string{"+15555555555"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(queries))
+	}
+	if len(xs) != 3 || xs[0].Email != emails[0] || xs[2].Email != emails[2] {
+		t.Errorf("Expected members in input order, got %+v", xs)
+	}
+	for _, q := range queries {
+		if n := len(strings.Split(q.Get("emails"), ",")); q.Get("phone_numbers") != "" && n != 1 {
+			t.Errorf("Expected the phone number to share a chunk with the last email, got %v", q)
+		}
+	}
+
+	if xs, err := client.InList("LIST1", nil, nil, nil); err != nil || xs != nil || len(queries) != 2 {
+		t.Errorf("Expected nothing to be sent for empty input")
+	}
+}
+
+func TestClient_SubscribeBatches(t *testing.T) {
+	var sizes []int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Profiles []map[string]interface{} `json:"profiles"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sizes = append(sizes, len(body.Profiles))
+		w.Header().Set("Content-Type", ContentJSON)
+		if len(sizes) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail": "invalid email"}`))
+			return
+		}
+		var res []ListPerson
+		for _, p := range body.Profiles {
+			res = append(res, ListPerson{Email: p["email"].(string)})
+		}
+		json.NewEncoder(w).Encode(res)
+	})
+
+	var emails []string
+	for i := 0; i < 250; i++ {
+		emails = append(emails, fmt.Sprintf("user%d@monstercat.com", i))
+	}
+	res, err := client.Subscribe("LIST1", emails, nil)
+	if !reflect.DeepEqual(sizes, []int{100, 100, 50}) {
+		t.Errorf("Unexpected batches %v", sizes)
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError, got %v", err)
+	}
+	if len(batchErr.Errors) != 100 || batchErr.Errors[100] == nil || batchErr.Errors[199] == nil || batchErr.Errors[99] != nil {
+		t.Errorf("Expected the second batch to fail, got %d errors", len(batchErr.Errors))
+	}
+	if len(res) != 150 || res[100].Email != emails[200] {
+		t.Errorf("Expected members of the other batches, got %d", len(res))
+	}
+}
+
+func TestClient_UnsubscribeBatches(t *testing.T) {
+	var batches []map[string][]string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]string
+		json.NewDecoder(r.Body).Decode(&body)
+		batches = append(batches, body)
+	})
+
+	var emails []string
+	for i := 0; i < 150; i++ {
+		emails = append(emails, fmt.Sprintf("user%d@monstercat.com", i))
+	}
+	if err := client.Unsubscribe("LIST1", emails, []string{"+15555555555"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || len(batches[0]["emails"]) != 100 || len(batches[1]["emails"]) != 50 {
+		t.Fatalf("Unexpected batches %d", len(batches))
+	}
+	if batches[1]["phone_numbers"][0] != "+15555555555" {
+		t.Errorf("Expected the phone number in the last batch, got %v", batches[1])
+	}
+}
+
+func TestClient_SubscribeWithStatus(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSON)
+		switch {
+		case r.URL.Path == "/api/lists/LIST1":
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": {"type": "list", "id": "LIST1", "attributes": {"name": "Newsletter", "opt_in_process": "double_opt_in"}}}`))
+		case r.Method == http.MethodGet:
+			if r.URL.Query().Get("emails") != "old@monstercat.com,new@monstercat.com" {
+				t.Errorf("Unexpected emails %s", r.URL.Query().Get("emails"))
+			}
+			w.Write([]byte(`[{"id": "OLD", "email": "Old@monstercat.com"}]`))
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`[{"id": "OLD", "email": "old@monstercat.com"}, {"id": "NEW", "email": "new@monstercat.com"}]`))
+		}
+	})
+
+	res, err := client.SubscribeWithStatus("LIST1", []SubscribeProfile{
+		{Email: "old@monstercat.com"},
+		{Email: "new@monstercat.com"},
+		{PhoneNumber: "+15555555555"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(res.Results))
+	}
+	expected := []SubscribeStatus{SubscribeExisting, SubscribeNew, SubscribePending}
+	for i, r := range res.Results {
+		if r.Status != expected[i] {
+			t.Errorf("Expected %s for %d, got %s", expected[i], i, r.Status)
+		}
+	}
+	if m := res.Results[1].Member; m == nil || m.Id != "NEW" {
+		t.Errorf("Unexpected member %+v", m)
+	}
+	if res.Results[2].Member != nil {
+		t.Error("Expected no member while pending")
+	}
+	if xs := res.WithStatus(SubscribePending); len(xs) != 1 || xs[0].Profile.PhoneNumber != "+15555555555" {
+		t.Errorf("Unexpected pending results %+v", xs)
+	}
+	if !res.DoubleOptIn || res.Results[2].Confirmed() || !res.Results[1].Confirmed() {
+		t.Errorf("Unexpected confirmation %+v", res)
+	}
+}
+
+func TestClient_SubscribeWithStatus_SingleOptIn(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/lists/LIST1":
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": {"type": "list", "id": "LIST1", "attributes": {"opt_in_process": "single_opt_in"}}}`))
+		default:
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`[]`))
+		}
+	})
+
+	res, err := client.SubscribeWithStatus("LIST1", []SubscribeProfile{
+		{Email: "new@monstercat.com", Attributes: Attributes{"source": "footer"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.DoubleOptIn || len(res.Results) != 1 || res.Results[0].Status != SubscribeNew {
+		t.Errorf("Expected an empty response to mean subscribed on a single opt-in list, got %+v", res)
+	}
+}
@@ -0,0 +1,45 @@
+package klaviyo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// Longest URL Klaviyo accepts. Legacy identify and track calls carry their whole payload in the URL so large
+	// profiles can run into this.
+	maxRequestURLLength = 8 * 1024
+
+	// Largest request body Klaviyo accepts, which is the limit for bulk jobs. Other endpoints may reject smaller ones.
+	maxRequestBodySize = 5 * 1024 * 1024
+)
+
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// Returned before sending a request which Klaviyo would reject for its size, so callers can split the payload up.
+// Matches ErrPayloadTooLarge with errors.Is.
+type PayloadTooLargeError struct {
+	// "url" or "body".
+	Part  string
+	Size  int
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("request %s is %d bytes, over the limit of %d bytes", e.Part, e.Size, e.Limit)
+}
+
+func (e *PayloadTooLargeError) Is(target error) bool {
+	return target == ErrPayloadTooLarge
+}
+
+func checkRequestSize(r *http.Request) error {
+	if n := len(r.URL.String()); n > maxRequestURLLength {
+		return &PayloadTooLargeError{Part: "url", Size: n, Limit: maxRequestURLLength}
+	}
+	if r.ContentLength > maxRequestBodySize {
+		return &PayloadTooLargeError{Part: "body", Size: int(r.ContentLength), Limit: maxRequestBodySize}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_PayloadTooLarge(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	})
+
+	p := Person{Email: "kitty@monstercat.com", Attributes: Attributes{"Bio": strings.Repeat("a", maxRequestURLLength)}}
+	err := client.Identify(&p)
+	var sizeErr *PayloadTooLargeError
+	if !errors.As(err, &sizeErr) {
+		t.Fatalf("Expected a PayloadTooLargeError, got %v", err)
+	}
+	if sizeErr.Part != "url" || sizeErr.Size <= maxRequestURLLength || sizeErr.Limit != maxRequestURLLength {
+		t.Errorf("Unexpected error %+v", sizeErr)
+	}
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Error("Expected the error to match ErrPayloadTooLarge")
+	}
+
+	e := &NewEvent{
+		Metric:     "Uploaded",
+		Person:     &Person{Email: "kitty@monstercat.com"},
+		Properties: map[string]interface{}{"Data": strings.Repeat("a", maxRequestBodySize)},
+	}
+	if err := client.CreateEvent(e); !errors.As(err, &sizeErr) || sizeErr.Part != "body" {
+		t.Errorf("Expected the body to be too large, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected nothing to be sent, got %d requests", hits)
+	}
+
+	// Only fits without the api_key.
+	u := "https://a.klaviyo.com/api/v2/lists?q="
+	r, _ := http.NewRequest(http.MethodGet, u+strings.Repeat("a", maxRequestURLLength-len(u)), nil)
+	if err := client.doReq(r, nil); !errors.As(err, &sizeErr) || sizeErr.Part != "url" {
+		t.Errorf("Expected the URL with the api_key to be too large, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected nothing to be sent, got %d requests", hits)
+	}
+}
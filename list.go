@@ -0,0 +1,257 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	GroupTypeList    = "list"
+	GroupTypeSegment = "segment"
+
+	// How people join a list, see Group.OptInProcess.
+	OptInSingle = "single_opt_in"
+	OptInDouble = "double_opt_in"
+
+	// Most profiles Klaviyo adds to or removes from a list in a single relationships request.
+	maxListRelationshipProfiles = 1000
+)
+
+// Group is what Klaviyo calls both lists and segments. Each API version names the fields differently (list_id vs id,
+// list_name vs name, v3 attributes) so decoding accepts all of them. Not every endpoint returns every field, e.g.
+// PersonCount is only filled where Klaviyo includes it.
+type Group struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	ListType    string `json:"list_type"` // list or segment
+	Created     KTime  `json:"created"`
+	Updated     KTime  `json:"updated"`
+	PersonCount KInt   `json:"person_count"`
+
+	// OptInSingle or OptInDouble, only returned for lists by the v3 endpoints.
+	OptInProcess string `json:"opt_in_process,omitempty"`
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes `json:"-"`
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id           string `json:"id"`
+		ListId       string `json:"list_id"`
+		Name         string `json:"name"`
+		ListName     string `json:"list_name"`
+		ListType     string `json:"list_type"`
+		Created      KTime  `json:"created"`
+		Updated      KTime  `json:"updated"`
+		PersonCount  KInt   `json:"person_count"`
+		OptInProcess string `json:"opt_in_process"`
+
+		// v3 resources
+		Type       string `json:"type"`
+		Attributes *struct {
+			Name         string `json:"name"`
+			Created      KTime  `json:"created"`
+			Updated      KTime  `json:"updated"`
+			OptInProcess string `json:"opt_in_process"`
+			ProfileCount KInt   `json:"profile_count"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*g = Group{
+		Id:           res.Id,
+		Name:         res.Name,
+		ListType:     res.ListType,
+		Created:      res.Created,
+		Updated:      res.Updated,
+		PersonCount:  res.PersonCount,
+		OptInProcess: res.OptInProcess,
+	}
+	if res.ListId != "" {
+		g.Id = res.ListId
+	}
+	if res.ListName != "" {
+		g.Name = res.ListName
+	}
+	var err error
+	if res.Attributes != nil {
+		g.Extra, err = extraAttributes(data, res.Attributes)
+	} else {
+		g.Extra, err = extraFields(data, &res)
+	}
+	if err != nil {
+		return err
+	}
+	if res.Attributes != nil {
+		g.Name = res.Attributes.Name
+		g.Created = res.Attributes.Created
+		g.Updated = res.Attributes.Updated
+		g.OptInProcess = res.Attributes.OptInProcess
+		g.PersonCount = res.Attributes.ProfileCount
+		g.ListType = res.Type
+	}
+	return nil
+}
+
+type List struct {
+	Group
+}
+
+type Segment struct {
+	Group
+}
+
+// https://apidocs.klaviyo.com/reference/lists-segments#get-lists
+// GET https://a.klaviyo.com/api/v2/lists
+// Only the Id and Name are returned for each list, use GetList for the rest.
+func (c *Client) GetLists() ([]List, error) {
+	var res []List
+	err := c.send(http.MethodGet, ContentJSON, newEndpoint(EndpointV2, "lists"), &res)
+	for i := range res {
+		res[i].ListType = GroupTypeList
+	}
+	return res, err
+}
+
+// https://apidocs.klaviyo.com/reference/lists-segments#get-list
+// GET https://a.klaviyo.com/api/v2/list/list_id
+func (c *Client) GetList(listId string) (*List, error) {
+	var l List
+	err := c.cached("list:"+listId, &l, func(out interface{}) error {
+		return c.send(http.MethodGet, ContentJSON, newEndpoint(EndpointV2, fmt.Sprintf("list/%s", listId)), out)
+	})
+	// The id is not part of the response.
+	l.Id = listId
+	if l.ListType == "" {
+		l.ListType = GroupTypeList
+	}
+	return &l, err
+}
+
+// https://developers.klaviyo.com/en/reference/get_list
+// GET https://a.klaviyo.com/api/lists/list_id
+// Reports whether people subscribed to the list have to confirm before they become members. The v2 endpoints do not
+// return the opt-in process so this uses v3. The answer is kept for CacheTTL when the client has a Cache.
+func (c *Client) DoubleOptIn(listId string) (bool, error) {
+	var res struct {
+		Data List `json:"data"`
+	}
+	err := c.cached("list-opt-in:"+listId, &res, func(out interface{}) error {
+		return c.sendV3(http.MethodGet, newEndpoint(Endpoint, fmt.Sprintf("lists/%s", listId)), nil, out)
+	})
+	return res.Data.OptInProcess == OptInDouble, err
+}
+
+// https://developers.klaviyo.com/en/reference/get_list
+// GET https://a.klaviyo.com/api/lists/list_id?additional-fields[list]=profile_count
+// Returns how many people are on the list in a single request, where counting through GetGroupMembers needs one
+// request per page of members. Meant for dashboards showing list sizes, the count is never cached.
+func (c *Client) CountListMembers(listId string) (int, error) {
+	u := newEndpoint(Endpoint, fmt.Sprintf("lists/%s", listId))
+	values := u.Query()
+	values.Set("additional-fields[list]", "profile_count")
+	u.RawQuery = values.Encode()
+	var res struct {
+		Data List `json:"data"`
+	}
+	err := c.sendV3(http.MethodGet, u, nil, &res)
+	return int(res.Data.PersonCount), err
+}
+
+// https://developers.klaviyo.com/en/reference/create_list_relationships
+// POST https://a.klaviyo.com/api/lists/list_id/relationships/profiles
+// Adds existing profiles to the list by id, the v3 replacement for the v2 members endpoint. This does not subscribe
+// them or give consent, use SubscribeProfiles or BulkSubscribeProfiles for that. Sends up to 1000 profiles per request,
+// failures are returned as a *BatchError keyed by the index of the profile id.
+func (c *Client) AddProfilesToList(listId string, profileIds []string) error {
+	return c.listRelationships(http.MethodPost, listId, profileIds)
+}
+
+// https://developers.klaviyo.com/en/reference/delete_list_relationships
+// DELETE https://a.klaviyo.com/api/lists/list_id/relationships/profiles
+// Removes profiles from the list by id without unsubscribing them, so they can still be added again. Use Unsubscribe
+// or BulkUnsubscribeProfiles to remove consent. Chunked like AddProfilesToList.
+func (c *Client) RemoveProfilesFromList(listId string, profileIds []string) error {
+	return c.listRelationships(http.MethodDelete, listId, profileIds)
+}
+
+func (c *Client) listRelationships(method, listId string, profileIds []string) error {
+	u := newEndpoint(Endpoint, fmt.Sprintf("lists/%s/relationships/profiles", listId))
+	send := func(ids []string) error {
+		data := make([]resourceIdentifier, len(ids))
+		for i, id := range ids {
+			data[i] = resourceIdentifier{Type: "profile", Id: id}
+		}
+		return c.sendV3(method, u, &document{Data: data}, nil)
+	}
+	if len(profileIds) <= maxListRelationshipProfiles {
+		return send(profileIds)
+	}
+	batchErr := &BatchError{Errors: map[int]error{}, Total: len(profileIds)}
+	runChunks(len(profileIds), maxListRelationshipProfiles, 1, func(_, start, end int) error {
+		err := send(profileIds[start:end])
+		if err != nil {
+			for i := start; i < end; i++ {
+				batchErr.Errors[i] = err
+			}
+		}
+		return err
+	})
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// https://developers.klaviyo.com/en/reference/get_segment
+// GET https://a.klaviyo.com/api/segments/segment_id
+// There is no v2 endpoint for segment information so this uses v3.
+func (c *Client) GetSegment(segmentId string) (*Segment, error) {
+	var res struct {
+		Data Segment `json:"data"`
+	}
+	err := c.sendV3(http.MethodGet, newEndpoint(Endpoint, fmt.Sprintf("segments/%s", segmentId)), nil, &res)
+	return &res.Data, err
+}
+
+// GET https://a.klaviyo.com/api/v2/people/person_id/groups
+// Returns every list and segment the person is a member of, use ListType to tell them apart.
+func (c *Client) GetPersonGroups(personId string) ([]Group, error) {
+	var res []Group
+	err := c.send(http.MethodGet, ContentJSON, newEndpoint(EndpointV2, fmt.Sprintf("people/%s/groups", personId)), &res)
+	return res, err
+}
+
+// Someone who was removed from a list, e.g. because they unsubscribed.
+type ListExclusion struct {
+	Id          string `json:"id"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+
+	// e.g. unsubscribed, bounced, manually_excluded
+	Reason    string `json:"reason"`
+	Timestamp KTime  `json:"timestamp"`
+}
+
+// https://apidocs.klaviyo.com/reference/lists-segments#get-list-exclusions
+// GET https://a.klaviyo.com/api/v2/list/list_id/exclusions/all
+// Returns a page of the people excluded from the list. Pass the returned marker to get the next page, it is 0 on the
+// last page.
+func (c *Client) GetListExclusions(listId string, marker int) ([]ListExclusion, int, error) {
+	u := newEndpoint(EndpointV2, fmt.Sprintf("list/%s/exclusions/all", listId))
+	if marker != 0 {
+		values := u.Query()
+		values.Add("marker", strconv.Itoa(marker))
+		u.RawQuery = values.Encode()
+	}
+	var res struct {
+		Records []ListExclusion `json:"records"`
+		Marker  int             `json:"marker"`
+	}
+	err := c.send(http.MethodGet, ContentJSON, u, &res)
+	return res.Records, res.Marker, err
+}
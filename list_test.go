@@ -0,0 +1,145 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGroup_UnmarshalJSON(t *testing.T) {
+	created := time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC)
+	tests := []struct {
+		in       string
+		expected Group
+	}{
+		{
+			`{"list_id": "ABC123", "list_name": "Newsletter"}`,
+			Group{Id: "ABC123", Name: "Newsletter"},
+		},
+		{
+			`{"list_name": "Newsletter", "list_type": "list", "created": "2021-05-06 07:08:09", "person_count": "42"}`,
+			Group{Name: "Newsletter", ListType: GroupTypeList, Created: KTime{created}, PersonCount: 42},
+		},
+		{
+			`{"object": "$list", "id": "ABC123", "name": "Newsletter", "list_type": "segment", "person_count": 42}`,
+			Group{Id: "ABC123", Name: "Newsletter", ListType: GroupTypeSegment, PersonCount: 42},
+		},
+		{
+			`{"type": "segment", "id": "ABC123", "attributes": {"name": "VIPs", "created": "2021-05-06T07:08:09+00:00"}}`,
+			Group{Id: "ABC123", Name: "VIPs", ListType: GroupTypeSegment, Created: KTime{created}},
+		},
+		{
+			`{"type": "list", "id": "ABC123", "attributes": {"name": "Newsletter", "profile_count": 42}}`,
+			Group{Id: "ABC123", Name: "Newsletter", ListType: GroupTypeList, PersonCount: 42},
+		},
+	}
+	for _, test := range tests {
+		var g Group
+		if err := json.Unmarshal([]byte(test.in), &g); err != nil {
+			t.Errorf("Failed to parse %s: %s", test.in, err)
+			continue
+		}
+		if g.Id != test.expected.Id || g.Name != test.expected.Name || g.ListType != test.expected.ListType {
+			t.Errorf("Parsed %s as %+v", test.in, g)
+		}
+		if !g.Created.Equal(test.expected.Created.Time) {
+			t.Errorf("Created did not match for %s", test.in)
+		}
+		if g.PersonCount != test.expected.PersonCount {
+			t.Errorf("PersonCount did not match for %s", test.in)
+		}
+	}
+
+	var l List
+	if err := json.Unmarshal([]byte(tests[0].in), &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.Id != "ABC123" {
+		t.Error("List should decode through Group")
+	}
+}
+
+func TestClient_CountListMembers(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/lists/LIST1" || r.URL.Query().Get("additional-fields[list]") != "profile_count" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": {"type": "list", "id": "LIST1", "attributes": {"name": "Newsletter", "profile_count": 1234}}}`))
+	})
+	count, err := client.CountListMembers("LIST1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1234 {
+		t.Errorf("Unexpected count %d", count)
+	}
+}
+
+func TestClient_AddProfilesToList(t *testing.T) {
+	var requests []string
+	var sent int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/lists/LIST1/relationships/profiles" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var doc struct {
+			Data []resourceIdentifier `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Data) == 0 || doc.Data[0].Type != "profile" {
+			t.Errorf("Unexpected profiles %+v", doc.Data)
+		}
+		requests = append(requests, r.Method)
+		sent += len(doc.Data)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ids := make([]string, 1500)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("PROFILE%d", i)
+	}
+	if err := client.AddProfilesToList("LIST1", ids); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RemoveProfilesFromList("LIST1", ids[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || requests[0] != http.MethodPost || requests[2] != http.MethodDelete || sent != 1501 {
+		t.Errorf("Expected 2 POSTs and a DELETE for 1501 profiles, got %v for %d", requests, sent)
+	}
+}
+
+func TestClient_GetListExclusions(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/list/LIST1/exclusions/all" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("marker") {
+		case "":
+			w.Write([]byte(`{"records": [{"email": "kitty@monstercat.com", "reason": "unsubscribed", "timestamp": "2022-11-08 13:14:15"}], "marker": 42}`))
+		case "42":
+			w.Write([]byte(`{"records": [{"email": "cat@monstercat.com", "reason": "bounced"}]}`))
+		default:
+			t.Errorf("Unexpected marker %s", r.URL.Query().Get("marker"))
+		}
+	})
+	exclusions, marker, err := client.GetListExclusions("LIST1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exclusions) != 1 || marker != 42 || exclusions[0].Reason != "unsubscribed" || exclusions[0].Timestamp.IsZero() {
+		t.Errorf("Unexpected first page %+v %d", exclusions, marker)
+	}
+	exclusions, marker, err = client.GetListExclusions("LIST1", marker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exclusions) != 1 || marker != 0 || exclusions[0].Email != "cat@monstercat.com" {
+		t.Errorf("Unexpected last page %+v %d", exclusions, marker)
+	}
+}
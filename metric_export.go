@@ -0,0 +1,157 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Only supported by the v1 metric export, the sum of the $value property of the events.
+const MeasurementValue Measurement = "value"
+
+// A condition on an event property in MetricExportQuery.Where, e.g. {"$attributed_flow", "=", flowId}.
+type MetricExportCondition struct {
+	Property string
+	Operator string
+	Value    interface{}
+}
+
+// Klaviyo takes conditions as [property, operator, value] arrays.
+func (c MetricExportCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{c.Property, c.Operator, c.Value})
+}
+
+type MetricExportQuery struct {
+	// Leave both empty to use Klaviyo's default of the last 30 days. Only the date is used.
+	Start time.Time
+	End   time.Time
+
+	// One of IntervalDay, IntervalWeek or IntervalMonth. Leave empty to use Klaviyo's default of day.
+	Unit Interval
+
+	// One of MeasurementCount, MeasurementUnique or MeasurementValue. Leave empty to count events. Ignored when
+	// SumProperty is set.
+	Measurement Measurement
+
+	// Sums this event property instead, e.g. "ItemCount".
+	SumProperty string
+
+	// Only events matching all of the conditions are included.
+	Where []MetricExportCondition
+
+	// Event property to split the results by, e.g. DimensionAttributedFlow.
+	By string
+
+	// How many segments are returned when splitting with By, leave as 0 to use Klaviyo's default of 25.
+	Count int
+}
+
+func (q *MetricExportQuery) values() (map[string]string, error) {
+	values := map[string]string{}
+	if !q.Start.IsZero() {
+		values["start_date"] = q.Start.Format("2006-01-02")
+	}
+	if !q.End.IsZero() {
+		values["end_date"] = q.End.Format("2006-01-02")
+	}
+	if q.Unit != "" {
+		values["unit"] = string(q.Unit)
+	}
+	if q.SumProperty != "" {
+		xs, err := json.Marshal([]string{"sum", q.SumProperty})
+		if err != nil {
+			return nil, err
+		}
+		values["measurement"] = string(xs)
+	} else if q.Measurement != "" {
+		values["measurement"] = string(q.Measurement)
+	}
+	if len(q.Where) > 0 {
+		xs, err := json.Marshal(q.Where)
+		if err != nil {
+			return nil, err
+		}
+		values["where"] = string(xs)
+	}
+	if q.By != "" {
+		values["by"] = q.By
+	}
+	if q.Count > 0 {
+		values["count"] = strconv.Itoa(q.Count)
+	}
+	return values, nil
+}
+
+type MetricExport struct {
+	Metric  Object          `json:"metric"`
+	Unit    Interval        `json:"unit"`
+	By      string          `json:"by"`
+	Results []MetricSegment `json:"results"`
+}
+
+// The values of one segment of a metric export. Segment is the value of the By property, or the name of the metric
+// when the export is not split.
+type MetricSegment struct {
+	Segment string        `json:"segment"`
+	Data    []MetricValue `json:"data"`
+}
+
+type MetricValue struct {
+	Date KTime `json:"date"`
+
+	// One value per measurement, which is always one for now.
+	Values []KFloat `json:"values"`
+}
+
+// Sum of the first value of every date.
+func (s *MetricSegment) Total() float64 {
+	var total float64
+	for _, d := range s.Data {
+		if len(d.Values) > 0 {
+			total += float64(d.Values[0])
+		}
+	}
+	return total
+}
+
+// Totals of every segment keyed by their name.
+func (e *MetricExport) Totals() map[string]float64 {
+	totals := map[string]float64{}
+	for i := range e.Results {
+		totals[e.Results[i].Segment] += e.Results[i].Total()
+	}
+	return totals
+}
+
+// https://apidocs.klaviyo.com/reference/metrics#metric-export
+// GET https://a.klaviyo.com/api/v1/metric/metric_id/export
+// Exports the values of a metric over time, e.g. the weekly revenue attributed to each flow:
+//
+//	export, err := client.GetMetricExport(placedOrderId, &klaviyo.MetricExportQuery{
+//		Unit:        klaviyo.IntervalWeek,
+//		Measurement: klaviyo.MeasurementValue,
+//		By:          klaviyo.DimensionAttributedFlow,
+//	})
+func (c *Client) GetMetricExport(metricId string, q *MetricExportQuery) (*MetricExport, error) {
+	if metricId == "" {
+		return nil, ErrNoMetricId
+	}
+	if q == nil {
+		q = &MetricExportQuery{}
+	}
+	params, err := q.values()
+	if err != nil {
+		return nil, err
+	}
+	u := newEndpoint(EndpointV1, fmt.Sprintf("metric/%s/export", metricId))
+	values := u.Query()
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	u.RawQuery = values.Encode()
+	var res MetricExport
+	err = c.send(http.MethodGet, ContentJSON, u, &res)
+	return &res, err
+}
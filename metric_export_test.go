@@ -0,0 +1,68 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_GetMetricExport(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/metric/METRIC1/export" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		expected := map[string]string{
+			"start_date":  "2021-01-01",
+			"end_date":    "2021-01-31",
+			"unit":        "week",
+			"measurement": `["sum","ItemCount"]`,
+			"where":       `[["$attributed_flow","=","FLOW1"]]`,
+			"by":          "$attributed_message",
+			"count":       "10",
+		}
+		for k, v := range expected {
+			if q.Get(k) != v {
+				t.Errorf("Expected %s to be %s, got %s", k, v, q.Get(k))
+			}
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{
+			"object": "metric-export",
+			"metric": {"object": "metric", "id": "METRIC1"},
+			"unit": "week",
+			"by": "$attributed_message",
+			"results": [
+				{"segment": "Welcome 1", "data": [{"date": "2021-01-04 00:00:00", "values": [3.0]}, {"date": "2021-01-11 00:00:00", "values": [2.5]}]},
+				{"segment": "Welcome 2", "data": [{"date": "2021-01-04 00:00:00", "values": [1.0]}]}
+			]
+		}`))
+	})
+
+	res, err := client.GetMetricExport("METRIC1", &MetricExportQuery{
+		Start:       time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC),
+		Unit:        IntervalWeek,
+		SumProperty: "ItemCount",
+		Where:       []MetricExportCondition{{DimensionAttributedFlow, "=", "FLOW1"}},
+		By:          "$attributed_message",
+		Count:       10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Metric.Id != "METRIC1" || res.Unit != IntervalWeek || len(res.Results) != 2 {
+		t.Fatalf("Unexpected export %+v", res)
+	}
+	if d := res.Results[0].Data[1].Date; !d.Equal(time.Date(2021, 1, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %v", d)
+	}
+	totals := res.Totals()
+	if totals["Welcome 1"] != 5.5 || totals["Welcome 2"] != 1 {
+		t.Errorf("Unexpected totals %v", totals)
+	}
+
+	if _, err := client.GetMetricExport("", nil); err != ErrNoMetricId {
+		t.Errorf("Expected ErrNoMetricId, got %v", err)
+	}
+}
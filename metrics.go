@@ -0,0 +1,315 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoMetricId     = errors.New("missing metric id")
+	ErrNoMeasurements = errors.New("at least one measurement is required")
+	ErrNoTimeframe    = errors.New("metric aggregates need a start and end time")
+)
+
+// Names of the metrics Klaviyo records for email campaigns and flows.
+const (
+	MetricReceivedEmail     = "Received Email"
+	MetricOpenedEmail       = "Opened Email"
+	MetricClickedEmail      = "Clicked Email"
+	MetricBouncedEmail      = "Bounced Email"
+	MetricDroppedEmail      = "Dropped Email"
+	MetricMarkedEmailAsSpam = "Marked Email as Spam"
+	MetricUnsubscribed      = "Unsubscribed"
+	MetricSubscribedToList  = "Subscribed to List"
+)
+
+// Names of the metrics Klaviyo records for SMS and push messages.
+const (
+	MetricReceivedSMS         = "Received SMS"
+	MetricClickedSMS          = "Clicked SMS"
+	MetricSentSMS             = "Sent SMS"
+	MetricFailedToDeliverSMS  = "Failed to Deliver SMS"
+	MetricUnsubscribedFromSMS = "Unsubscribed from SMS"
+	MetricReceivedPush        = "Received Push"
+	MetricOpenedPush          = "Opened Push"
+	MetricBouncedPush         = "Bounced Push"
+)
+
+// Names of the standard ecommerce metrics, use these with CreateEvent so Klaviyo's built-in flows and reports pick
+// the events up.
+const (
+	MetricActiveOnSite    = "Active on Site"
+	MetricViewedProduct   = "Viewed Product"
+	MetricAddedToCart     = "Added to Cart"
+	MetricStartedCheckout = "Started Checkout"
+	MetricPlacedOrder     = "Placed Order"
+	MetricOrderedProduct  = "Ordered Product"
+	MetricFulfilledOrder  = "Fulfilled Order"
+	MetricCancelledOrder  = "Cancelled Order"
+	MetricRefundedOrder   = "Refunded Order"
+)
+
+type Metric struct {
+	Id          string
+	Name        string
+	Integration string
+	Created     KTime
+	Updated     KTime
+
+	// Attributes Klaviyo returned which the SDK has no field for yet.
+	Extra Attributes
+}
+
+func (m *Metric) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Name        string `json:"name"`
+			Created     KTime  `json:"created"`
+			Updated     KTime  `json:"updated"`
+			Integration struct {
+				Name string `json:"name"`
+			} `json:"integration"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	*m = Metric{
+		Id:          res.Id,
+		Name:        res.Attributes.Name,
+		Integration: res.Attributes.Integration.Name,
+		Created:     res.Attributes.Created,
+		Updated:     res.Attributes.Updated,
+		Extra:       extra,
+	}
+	return nil
+}
+
+type MetricPage struct {
+	Data  []Metric `json:"data"`
+	Links Links    `json:"links"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_metrics
+// GET https://a.klaviyo.com/api/metrics
+// Returns a page of metrics, use Links.NextCursor() to get the next one. The only supported filter is
+// integration.name.
+func (c *Client) GetMetrics(q *Query) (*MetricPage, error) {
+	u := newEndpoint(Endpoint, "metrics")
+	q.apply(u)
+	var res MetricPage
+	err := c.cached("metrics:"+u.RawQuery, &res, func(out interface{}) error {
+		return c.sendV3(http.MethodGet, u, nil, out)
+	})
+	return &res, err
+}
+
+// Goes through every metric and returns the ids of the ones named in names. Names which do not exist are left out.
+// Klaviyo allows the same name for metrics of different integrations, the Klaviyo integration wins in that case.
+func (c *Client) getMetricIds(names ...string) (map[string]string, error) {
+	want := map[string]bool{}
+	for _, n := range names {
+		want[n] = true
+	}
+	ids := map[string]string{}
+	q := &Query{}
+	for {
+		page, err := c.GetMetrics(q)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			if !want[m.Name] {
+				continue
+			}
+			if _, ok := ids[m.Name]; !ok || m.Integration == "Klaviyo" {
+				ids[m.Name] = m.Id
+			}
+		}
+		if q.Cursor = page.Links.NextCursor(); q.Cursor == "" {
+			return ids, nil
+		}
+	}
+}
+
+type Measurement string
+
+const (
+	MeasurementCount    Measurement = "count"
+	MeasurementUnique   Measurement = "unique"
+	MeasurementSumValue Measurement = "sum_value"
+)
+
+type Interval string
+
+const (
+	IntervalHour  Interval = "hour"
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// Common dimensions to group metric aggregates by. Any event property can also be used.
+const (
+	DimensionMessage        = "$message"
+	DimensionAttributedFlow = "$attributed_flow"
+	DimensionFlow           = "$flow"
+	DimensionCampaignName   = "Campaign Name"
+)
+
+// MetricAggregateQuery describes a query for the metric aggregates endpoint, e.g. the revenue of every flow in
+// January:
+//
+//	q := &klaviyo.MetricAggregateQuery{
+//		MetricId:     placedOrderId,
+//		Measurements: []klaviyo.Measurement{klaviyo.MeasurementSumValue},
+//		By:           []string{klaviyo.DimensionAttributedFlow},
+//		Interval:     klaviyo.IntervalMonth,
+//		Start:        jan,
+//		End:          jan.AddDate(0, 1, 0),
+//	}
+type MetricAggregateQuery struct {
+	MetricId     string
+	Measurements []Measurement
+
+	// Dimensions to group the results by, leave empty to get a single total per date.
+	By []string
+
+	// Leave empty to use Klaviyo's default of day.
+	Interval Interval
+
+	// Only events in [Start, End) are aggregated, both are required.
+	Start time.Time
+	End   time.Time
+
+	// Extra conditions on top of the timeframe, all of them must match.
+	Filter []FilterBuilder
+
+	// IANA name of the timezone used to split the intervals. Defaults to UTC.
+	Timezone string
+
+	// Sort by a dimension or measurement, prefix with "-" for descending order.
+	Sort string
+}
+
+func (q *MetricAggregateQuery) attributes() (map[string]interface{}, error) {
+	switch {
+	case q.MetricId == "":
+		return nil, ErrNoMetricId
+	case len(q.Measurements) == 0:
+		return nil, ErrNoMeasurements
+	case q.Start.IsZero() || q.End.IsZero():
+		return nil, ErrNoTimeframe
+	}
+	filter := []string{
+		GreaterOrEqual("datetime", q.Start).String(),
+		LessThan("datetime", q.End).String(),
+	}
+	for _, f := range q.Filter {
+		filter = append(filter, f.String())
+	}
+	timezone := q.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	attributes := map[string]interface{}{
+		"metric_id":    q.MetricId,
+		"measurements": q.Measurements,
+		"filter":       filter,
+		"timezone":     timezone,
+	}
+	if len(q.By) > 0 {
+		attributes["by"] = q.By
+	}
+	if q.Interval != "" {
+		attributes["interval"] = q.Interval
+	}
+	if q.Sort != "" {
+		attributes["sort"] = q.Sort
+	}
+	return attributes, nil
+}
+
+// Result of a metric aggregate query. Every entry in Data holds the values for one combination of the grouped by
+// dimensions, with one value per date for each measurement.
+type MetricAggregate struct {
+	Dates []time.Time
+	Data  []MetricAggregateData
+}
+
+type MetricAggregateData struct {
+	Dimensions   []string             `json:"dimensions"`
+	Measurements map[string][]float64 `json:"measurements"`
+}
+
+// Sum of all the values of the measurement across dates and dimensions.
+func (a *MetricAggregate) Total(measurement Measurement) float64 {
+	var total float64
+	for _, d := range a.Data {
+		total += d.Total(measurement)
+	}
+	return total
+}
+
+// Totals of the measurement keyed by the grouped by dimensions, joined by commas when grouping by more than one.
+func (a *MetricAggregate) TotalsBy(measurement Measurement) map[string]float64 {
+	totals := map[string]float64{}
+	for _, d := range a.Data {
+		totals[strings.Join(d.Dimensions, ",")] += d.Total(measurement)
+	}
+	return totals
+}
+
+// Sum of the values of the measurement across dates.
+func (d *MetricAggregateData) Total(measurement Measurement) float64 {
+	var total float64
+	for _, v := range d.Measurements[string(measurement)] {
+		total += v
+	}
+	return total
+}
+
+func (a *MetricAggregate) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Attributes struct {
+			Dates []time.Time           `json:"dates"`
+			Data  []MetricAggregateData `json:"data"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*a = MetricAggregate{
+		Dates: res.Attributes.Dates,
+		Data:  res.Attributes.Data,
+	}
+	return nil
+}
+
+// https://developers.klaviyo.com/en/reference/query_metric_aggregates
+// POST https://a.klaviyo.com/api/metric-aggregates
+func (c *Client) QueryMetricAggregates(q *MetricAggregateQuery) (*MetricAggregate, error) {
+	attributes, err := q.attributes()
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{
+		Data: map[string]interface{}{
+			"type":       "metric-aggregate",
+			"attributes": attributes,
+		},
+	}
+	var res struct {
+		Data MetricAggregate `json:"data"`
+	}
+	err = c.sendV3(http.MethodPost, newEndpoint(Endpoint, "metric-aggregates"), doc, &res)
+	return &res.Data, err
+}
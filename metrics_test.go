@@ -0,0 +1,101 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetricAggregateQuery_Attributes(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &MetricAggregateQuery{
+		MetricId:     "ORDER",
+		Measurements: []Measurement{MeasurementSumValue},
+		By:           []string{DimensionAttributedFlow},
+		Interval:     IntervalWeek,
+		Start:        start,
+		End:          start.AddDate(0, 1, 0),
+		Filter:       []FilterBuilder{Equals("Currency", "CAD")},
+	}
+	attrs, err := q.attributes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := []string{
+		"greater-or-equal(datetime,2023-01-01T00:00:00Z)",
+		"less-than(datetime,2023-02-01T00:00:00Z)",
+		`equals(Currency,"CAD")`,
+	}
+	if !reflect.DeepEqual(attrs["filter"], filter) {
+		t.Errorf("Unexpected filter %v", attrs["filter"])
+	}
+	if attrs["timezone"] != "UTC" || attrs["interval"] != IntervalWeek {
+		t.Errorf("Unexpected attributes %v", attrs)
+	}
+	if _, ok := attrs["sort"]; ok {
+		t.Error("Empty sort should be left out")
+	}
+
+	for _, test := range []struct {
+		q   MetricAggregateQuery
+		err error
+	}{
+		{MetricAggregateQuery{Measurements: q.Measurements, Start: q.Start, End: q.End}, ErrNoMetricId},
+		{MetricAggregateQuery{MetricId: "ORDER", Start: q.Start, End: q.End}, ErrNoMeasurements},
+		{MetricAggregateQuery{MetricId: "ORDER", Measurements: q.Measurements, Start: q.Start}, ErrNoTimeframe},
+	} {
+		if _, err := test.q.attributes(); err != test.err {
+			t.Errorf("Expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestClient_QueryMetricAggregates(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Data struct {
+				Type       string `json:"type"`
+				Attributes struct {
+					By []string `json:"by"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Data.Type != "metric-aggregate" || len(doc.Data.Attributes.By) != 1 {
+			t.Errorf("Unexpected document %+v", doc)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": {"type": "metric-aggregate", "attributes": {
+			"dates": ["2023-01-01T00:00:00+00:00", "2023-01-08T00:00:00+00:00"],
+			"data": [
+				{"dimensions": ["FLOW1"], "measurements": {"sum_value": [10.5, 4.5]}},
+				{"dimensions": ["FLOW2"], "measurements": {"sum_value": [1, 0]}}
+			]
+		}}}`))
+	})
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	agg, err := client.QueryMetricAggregates(&MetricAggregateQuery{
+		MetricId:     "ORDER",
+		Measurements: []Measurement{MeasurementSumValue},
+		By:           []string{DimensionAttributedFlow},
+		Start:        start,
+		End:          start.AddDate(0, 0, 14),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agg.Dates) != 2 {
+		t.Errorf("Expected 2 dates, got %d", len(agg.Dates))
+	}
+	if agg.Total(MeasurementSumValue) != 16 {
+		t.Errorf("Unexpected total %f", agg.Total(MeasurementSumValue))
+	}
+	expected := map[string]float64{"FLOW1": 15, "FLOW2": 1}
+	if totals := agg.TotalsBy(MeasurementSumValue); !reflect.DeepEqual(totals, expected) {
+		t.Errorf("Expected %v, got %v", expected, totals)
+	}
+}
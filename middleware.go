@@ -0,0 +1,37 @@
+package klaviyo
+
+import (
+	"net/http"
+)
+
+// RoundTripFunc sends a single HTTP request, like http.RoundTripper.RoundTrip.
+type RoundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware wraps the sending of every request made by the client. It can change the request, e.g. swap the
+// credentials, short circuit it with a cached response or record how long it took:
+//
+//	func timing(next klaviyo.RoundTripFunc) klaviyo.RoundTripFunc {
+//		return func(r *http.Request) (*http.Response, error) {
+//			start := time.Now()
+//			res, err := next(r)
+//			log.Printf("%s %s took %s", r.Method, r.URL.Path, time.Since(start))
+//			return res, err
+//		}
+//	}
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Wraps rt with the middleware, the first one is the outermost.
+func chainMiddleware(rt http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	if len(middleware) == 0 {
+		return rt
+	}
+	next := RoundTripFunc(rt.RoundTrip)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
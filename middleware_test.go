@@ -0,0 +1,59 @@
+package klaviyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "abc" {
+			t.Errorf("Expected the middleware to change the request, got %v", r.Header)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": [], "links": {}}`))
+	})
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				r.Header.Set("X-Trace", "abc")
+				res, err := next(r)
+				calls = append(calls, name+" after")
+				return res, err
+			}
+		}
+	}
+	client.Middleware = []Middleware{trace("outer"), trace("inner")}
+	if _, err := client.GetMetrics(nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"outer before", "inner before", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
+
+func TestClient_MiddlewareShortCircuit(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("The request should not reach the server")
+	})
+	client.Middleware = []Middleware{func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Content-Type", ContentJSONAPI)
+			rec.WriteString(`{"data": [{"type": "metric", "id": "M1", "attributes": {"name": "Cached"}}], "links": {}}`)
+			return rec.Result(), nil
+		}
+	}}
+	page, err := client.GetMetrics(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 || page.Data[0].Name != "Cached" {
+		t.Errorf("Unexpected metrics %+v", page.Data)
+	}
+}
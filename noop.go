@@ -0,0 +1,341 @@
+package klaviyo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NoopClient is a KlaviyoAPI which drops every call without an error, for running applications where there is no
+// Klaviyo account such as local development. Reads return empty results. Unlike DryRun nothing is sent at all, not
+// even read calls.
+type NoopClient struct{}
+
+var _ KlaviyoAPI = NoopClient{}
+
+func (NoopClient) Identify(person *Person, opts ...IdentifyOption) error {
+	return nil
+}
+
+func (NoopClient) IdentifySafe(person *Person, omit bool, opts ...IdentifyOption) error {
+	return nil
+}
+
+func (NoopClient) IdentifyBatch(people []Person) ([]string, error) {
+	return nil, nil
+}
+
+func (NoopClient) ImportCSV(r io.Reader, opts *CSVImportOptions) (*CSVImportReport, error) {
+	return &CSVImportReport{}, nil
+}
+
+func (NoopClient) GetPerson(personId string) (*Person, error) {
+	return &Person{Object: Object{Id: personId}}, nil
+}
+
+func (NoopClient) GetPersonAttributesOnly(personId string, names ...string) (Attributes, error) {
+	return Attributes{}, nil
+}
+
+func (NoopClient) GetPeople(page, count int) (*PeoplePage, error) {
+	return &PeoplePage{Page: page}, nil
+}
+
+func (NoopClient) EachPerson(count int, fn func(*Person) error) error {
+	return nil
+}
+
+func (NoopClient) EachPersonFrom(cursor *Cursor, count int, fn func(*Person) error) error {
+	cursor.Done = true
+	return nil
+}
+
+func (NoopClient) GetProfiles(q *Query) (*ProfilePage, error) {
+	return &ProfilePage{}, nil
+}
+
+func (NoopClient) EachProfile(q *Query, fn func(*Profile) error) error {
+	return nil
+}
+
+func (NoopClient) EachProfileFrom(q *Query, cursor *Cursor, fn func(*Profile) error) error {
+	cursor.Done = true
+	return nil
+}
+
+func (NoopClient) SyncChangedProfiles(since time.Time, fn func(*Profile) error) (time.Time, error) {
+	return since, nil
+}
+
+func (NoopClient) FindPersonId(p *Person) (string, error) {
+	return "", ErrPersonNotFound
+}
+
+func (NoopClient) UpdatePerson(person *Person) error {
+	return nil
+}
+
+func (NoopClient) UpdatePersonDiff(old, new *Person) error {
+	return nil
+}
+
+func (NoopClient) UpdatePersonByEmail(email string, attrs map[string]interface{}) error {
+	return nil
+}
+
+func (NoopClient) PatchPerson(person *Person, patch *ProfilePatch) error {
+	return nil
+}
+
+func (NoopClient) DeletePerson(profileId string) error {
+	return nil
+}
+
+func (NoopClient) GetPredictiveAnalytics(profileId string) (*PredictiveAnalytics, error) {
+	return &PredictiveAnalytics{}, nil
+}
+
+func (NoopClient) GetPersonGroups(personId string) ([]Group, error) {
+	return nil, nil
+}
+
+func (NoopClient) GetLists() ([]List, error) {
+	return nil, nil
+}
+
+func (NoopClient) GetList(listId string) (*List, error) {
+	return &List{}, nil
+}
+
+func (NoopClient) GetSegment(segmentId string) (*Segment, error) {
+	return &Segment{}, nil
+}
+
+func (NoopClient) DoubleOptIn(listId string) (bool, error) {
+	return false, nil
+}
+
+func (NoopClient) CountListMembers(listId string) (int, error) {
+	return 0, nil
+}
+
+func (NoopClient) GroupSizes(groupIds ...string) (map[string]GroupSample, error) {
+	return map[string]GroupSample{}, nil
+}
+
+func (NoopClient) SampleGroupGrowth(ctx context.Context, groupIds []string, interval time.Duration, samples int) (map[string][]GroupSample, error) {
+	return map[string][]GroupSample{}, nil
+}
+
+func (NoopClient) GetListExclusions(listId string, marker int) ([]ListExclusion, int, error) {
+	return nil, 0, nil
+}
+
+func (NoopClient) GetGroupMembers(groupId string, marker int) ([]ListPerson, int, error) {
+	return nil, 0, nil
+}
+
+func (NoopClient) StreamGroupMembers(groupId string, marker int, fn func(ListPerson) error) (int, error) {
+	return 0, nil
+}
+
+func (NoopClient) EachGroupMember(ctx context.Context, groupId string, fn func(ListPerson) error) error {
+	return nil
+}
+
+func (NoopClient) EachGroupMemberFrom(ctx context.Context, groupId string, cursor *Cursor, fn func(ListPerson) error) error {
+	cursor.Done = true
+	return nil
+}
+
+func (NoopClient) ForEachListMember(ctx context.Context, listId string, concurrency int, fn func(ListPerson) error) error {
+	return nil
+}
+
+func (NoopClient) ExportGroupMembers(w io.Writer, groupId string, format ExportFormat) error {
+	mw, err := newMemberWriter(w, format)
+	if err != nil {
+		return err
+	}
+	return mw.Flush()
+}
+
+func (c NoopClient) ExportGroupMembersReader(groupId string, format ExportFormat) io.ReadCloser {
+	var sb strings.Builder
+	if err := c.ExportGroupMembers(&sb, groupId, format); err != nil {
+		r, w := io.Pipe()
+		w.CloseWithError(err)
+		return r
+	}
+	return io.NopCloser(strings.NewReader(sb.String()))
+}
+
+func (NoopClient) ExportGroupMembersToFile(filename, groupId string, format ExportFormat) error {
+	return nil
+}
+
+func (NoopClient) InList(listId string, emails, phoneNumbers, pushTokens []string) ([]ListPerson, error) {
+	return nil, nil
+}
+
+func (NoopClient) AddProfilesToList(listId string, profileIds []string) error {
+	return nil
+}
+
+func (NoopClient) RemoveProfilesFromList(listId string, profileIds []string) error {
+	return nil
+}
+
+func (NoopClient) Subscribe(listId string, emails, phoneNumbers []string) ([]ListPerson, error) {
+	return nil, nil
+}
+
+func (NoopClient) SubscribeSMS(listId, phone string, consentTimestamp time.Time, method string) (*ListPerson, error) {
+	return nil, nil
+}
+
+func (NoopClient) SubscribeProfiles(listId string, profiles []SubscribeProfile) ([]ListPerson, error) {
+	return nil, nil
+}
+
+func (NoopClient) SubscribeWithStatus(listId string, profiles []SubscribeProfile) (*SubscribeResponse, error) {
+	return &SubscribeResponse{}, nil
+}
+
+func (NoopClient) Unsubscribe(listId string, emails, phoneNumbers, pushTokens []string) error {
+	return nil
+}
+
+func (NoopClient) UnsubscribeAll(listId string, person *Person) error {
+	return nil
+}
+
+func (NoopClient) BulkSubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	return nil
+}
+
+func (NoopClient) BulkUnsubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	return nil
+}
+
+func (NoopClient) RegisterPushToken(person *Person, token string, platform PushPlatform) error {
+	return nil
+}
+
+func (NoopClient) UnregisterPushToken(person *Person, token string, platform PushPlatform) error {
+	return nil
+}
+
+func (NoopClient) SuppressProfiles(emails []string) error {
+	return nil
+}
+
+func (NoopClient) UnsuppressProfiles(emails []string) error {
+	return nil
+}
+
+func (NoopClient) SuppressOnBounce(email, reason string) error {
+	return nil
+}
+
+func (NoopClient) CreateEvent(e *NewEvent) error {
+	return nil
+}
+
+func (NoopClient) CreateClientEvent(e *NewEvent) error {
+	return nil
+}
+
+func (NoopClient) CreateClientProfile(person *Person) error {
+	return nil
+}
+
+func (NoopClient) GetEvents(q *Query) (*EventPage, error) {
+	return &EventPage{}, nil
+}
+
+func (NoopClient) GetMetrics(q *Query) (*MetricPage, error) {
+	return &MetricPage{}, nil
+}
+
+func (NoopClient) QueryMetricAggregates(q *MetricAggregateQuery) (*MetricAggregate, error) {
+	return &MetricAggregate{}, nil
+}
+
+func (NoopClient) GetMetricExport(metricId string, q *MetricExportQuery) (*MetricExport, error) {
+	return &MetricExport{}, nil
+}
+
+func (NoopClient) CreateCampaign(listId, templateId, subject, fromEmail, fromName, name string) (*Campaign, error) {
+	return &Campaign{Name: name, Subject: subject, FromEmail: fromEmail, FromName: fromName}, nil
+}
+
+func (NoopClient) GetCampaignRecipients(campaignId string) ([]CampaignRecipient, error) {
+	return nil, nil
+}
+
+func (NoopClient) GetCampaignStats(campaignId string, start, end time.Time) (*CampaignStats, error) {
+	return &CampaignStats{}, nil
+}
+
+func (NoopClient) GetFlows(q *Query) (*FlowPage, error) {
+	return &FlowPage{}, nil
+}
+
+func (NoopClient) GetFlowActions(flowId string) ([]FlowAction, error) {
+	return nil, nil
+}
+
+func (NoopClient) GetFlowMessages(actionId string) ([]FlowMessage, error) {
+	return nil, nil
+}
+
+func (NoopClient) GetForms(q *Query) (*FormPage, error) {
+	return &FormPage{}, nil
+}
+
+func (NoopClient) GetForm(formId string) (*Form, error) {
+	return &Form{}, nil
+}
+
+func (NoopClient) TrackFormSubmission(s *FormSubmission) error {
+	return nil
+}
+
+func (NoopClient) GetTemplate(templateId string) (*Template, error) {
+	return &Template{Object: Object{Id: templateId}}, nil
+}
+
+func (NoopClient) CloneTemplate(templateId, newName string) (*Template, error) {
+	return &Template{Name: newName}, nil
+}
+
+func (NoopClient) UpdateTemplateHTML(templateId, html string) (*TemplateBackup, error) {
+	return &TemplateBackup{Id: templateId}, nil
+}
+
+func (NoopClient) RestoreTemplate(backup *TemplateBackup) error {
+	return nil
+}
+
+func (NoopClient) ValidateTemplate(templateId string, context map[string]interface{}) (*TemplateContextReport, error) {
+	return &TemplateContextReport{}, nil
+}
+
+func (NoopClient) SendTemplateEmail(templateId, from, fromName, subject string, to []EmailRecipient, context map[string]interface{}) (*TemplateSendResult, error) {
+	return &TemplateSendResult{}, nil
+}
+
+func (NoopClient) RenderTemplatePreview(templateId string, context map[string]interface{}) (*TemplatePreview, error) {
+	return &TemplatePreview{Id: templateId}, nil
+}
+
+func (NoopClient) RenderTemplatePreviewForPerson(templateId, personId string, context map[string]interface{}) (*TemplatePreview, error) {
+	return &TemplatePreview{Id: templateId}, nil
+}
+
+func (NoopClient) HealthCheck(ctx context.Context) (*HealthStatus, error) {
+	return &HealthStatus{StatusCode: http.StatusOK}, nil
+}
@@ -1,26 +1,40 @@
 package klaviyo
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 var (
 	frontBackQuotesRegexp = regexp.MustCompile("^\"|\"$")
 )
 
+// Returns the number inside b with any quotes removed, or an empty string for null and empty values.
+func unquoteNumber(b []byte) string {
+	if string(b) == "null" {
+		return ""
+	}
+	return strings.TrimSpace(string(frontBackQuotesRegexp.ReplaceAll(b, nil)))
+}
+
 // KFloat implements the UnmarshalJSON interface to do special processing for Klaviyo. In certain instances (such as
 // when a number field is empty), klaviyo will return the value as a string. Otherwise, it will return the value as a
-// number.
+// number. Empty strings and null decode to 0.
 type KFloat float64
 
 func (f *KFloat) UnmarshalJSON(b []byte) error {
 	// Strip the quotes from the front and back of the JSON string. This allows string values of floats such as
 	// "123.345" to pass properly. However, quotes in the middle will not be removed.
-	s := frontBackQuotesRegexp.ReplaceAll(b, nil)
-
-	// Parse to a float. This function will fail on empty string.
-	v, err := strconv.ParseFloat(string(s), 64)
+	s := unquoteNumber(b)
+	if s == "" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return err
 	}
@@ -28,20 +42,79 @@ func (f *KFloat) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Sent as a plain JSON number, which is what Klaviyo expects when receiving numbers.
+func (f KFloat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(f))
+}
+
+func (f KFloat) IsZero() bool {
+	return f == 0
+}
+
+// Returns a pointer to a copy of the value, useful for optional fields.
+func (f KFloat) Ptr() *KFloat {
+	return &f
+}
+
 // KInt implements the UnmarshalJSON interface to do special processing for Klaviyo. In certain instances (such as
 // when a number field is empty), klaviyo will return the value as a string. Otherwise, it will return the value as a
-// number.
+// number. Empty strings and null decode to 0, whole floats such as 12.0 are accepted.
 type KInt int
 
 func (i *KInt) UnmarshalJSON(b []byte) error {
 	// Strip the quotes from the front and back of the JSON string. This allows string values of floats such as
 	// "12345" to pass properly. However, quotes in the middle will not be removed.
-	s := frontBackQuotesRegexp.ReplaceAll(b, nil)
-
-	v, err := strconv.Atoi(string(s))
+	s := unquoteNumber(b)
+	if s == "" {
+		*i = 0
+		return nil
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		*i = KInt(v)
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return err
 	}
+	if v != math.Trunc(v) {
+		return fmt.Errorf("%s is not a whole number", s)
+	}
 	*i = KInt(v)
-	return nil 
+	return nil
+}
+
+// Sent as a plain JSON number, which is what Klaviyo expects when receiving numbers.
+func (i KInt) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(i))), nil
+}
+
+func (i KInt) IsZero() bool {
+	return i == 0
+}
+
+// Returns a pointer to a copy of the value, useful for optional fields.
+func (i KInt) Ptr() *KInt {
+	return &i
+}
+
+// KBool implements the UnmarshalJSON interface to do special processing for Klaviyo. Booleans can come back as
+// true/false, 1/0 or any of those as strings depending on how the value was set. An empty string or null is false.
+type KBool bool
+
+func (k *KBool) UnmarshalJSON(b []byte) error {
+	s := string(frontBackQuotesRegexp.ReplaceAll(b, nil))
+	switch strings.ToLower(s) {
+	case "true", "1":
+		*k = true
+	case "false", "0", "", "null":
+		*k = false
+	default:
+		return fmt.Errorf("invalid boolean value %s", b)
+	}
+	return nil
+}
+
+func (k KBool) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatBool(bool(k))), nil
 }
@@ -0,0 +1,151 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKFloat_JSON(t *testing.T) {
+	type s struct {
+		Latitude  KFloat `json:"$latitude"`
+		Longitude KFloat `json:"$longitude"`
+	}
+	a := s{Latitude: 49.2827, Longitude: -123.1207}
+	xs, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(xs) != `{"$latitude":49.2827,"$longitude":-123.1207}` {
+		t.Errorf("Unexpected JSON %s", xs)
+	}
+	var b s
+	if err := json.Unmarshal(xs, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("KFloat did not match after encoding/decoding process")
+	}
+	if err := json.Unmarshal([]byte(`{"$latitude":"49.2827"}`), &b); err != nil {
+		t.Fatal(err)
+	} else if b.Latitude != a.Latitude {
+		t.Error("KFloat did not parse string value")
+	}
+}
+
+func TestKInt_JSON(t *testing.T) {
+	type s struct {
+		Source KInt `json:"$source"`
+	}
+	a := s{Source: -9}
+	xs, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(xs) != `{"$source":-9}` {
+		t.Errorf("Unexpected JSON %s", xs)
+	}
+	var b s
+	if err := json.Unmarshal(xs, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("KInt did not match after encoding/decoding process")
+	}
+	if err := json.Unmarshal([]byte(`{"$source":"-9"}`), &b); err != nil {
+		t.Fatal(err)
+	} else if b.Source != a.Source {
+		t.Error("KInt did not parse string value")
+	}
+}
+
+func TestKNumber_Helpers(t *testing.T) {
+	if !KFloat(0).IsZero() || KFloat(1.5).IsZero() {
+		t.Error("KFloat.IsZero returned the wrong value")
+	}
+	if !KInt(0).IsZero() || KInt(2).IsZero() {
+		t.Error("KInt.IsZero returned the wrong value")
+	}
+	f := KFloat(1.5)
+	if p := f.Ptr(); *p != f || p == &f {
+		t.Error("KFloat.Ptr should return a pointer to a copy")
+	}
+	i := KInt(2)
+	if p := i.Ptr(); *p != i || p == &i {
+		t.Error("KInt.Ptr should return a pointer to a copy")
+	}
+}
+
+func TestKBool_UnmarshalJSON(t *testing.T) {
+	tests := map[string]KBool{
+		`true`:    true,
+		`false`:   false,
+		`1`:       true,
+		`0`:       false,
+		`"true"`:  true,
+		`"True"`:  true,
+		`"1"`:     true,
+		`"false"`: false,
+		`"0"`:     false,
+		`""`:      false,
+		`null`:    false,
+	}
+	for in, expected := range tests {
+		k := !expected
+		if err := json.Unmarshal([]byte(in), &k); err != nil {
+			t.Errorf("Failed to parse %s: %s", in, err)
+		} else if k != expected {
+			t.Errorf("Expected %v for %s", expected, in)
+		}
+	}
+	var k KBool
+	if err := json.Unmarshal([]byte(`"maybe"`), &k); err == nil {
+		t.Error("Expected an error for an invalid value")
+	}
+}
+
+func TestKBool_MarshalJSON(t *testing.T) {
+	xs, err := json.Marshal(map[string]KBool{"LikesGold": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(xs) != `{"LikesGold":true}` {
+		t.Errorf("Unexpected JSON %s", xs)
+	}
+}
+
+func TestKNumber_Empty(t *testing.T) {
+	var s struct {
+		Latitude KFloat `json:"$latitude"`
+		Source   KInt   `json:"$source"`
+	}
+	for _, body := range []string{
+		`{"$latitude": null, "$source": null}`,
+		`{"$latitude": "", "$source": ""}`,
+		`{"$latitude": " ", "$source": " "}`,
+	} {
+		s.Latitude, s.Source = 1, 1
+		if err := json.Unmarshal([]byte(body), &s); err != nil {
+			t.Errorf("Could not decode %s: %s", body, err)
+		}
+		if s.Latitude != 0 || s.Source != 0 {
+			t.Errorf("Expected zero values from %s, got %+v", body, s)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"$source": 12.0}`), &s); err != nil || s.Source != 12 {
+		t.Errorf("Expected whole floats to decode into KInt, got %d %v", s.Source, err)
+	}
+	if err := json.Unmarshal([]byte(`{"$source": "12.5"}`), &s); err == nil {
+		t.Error("Expected an error for fractions in KInt")
+	}
+}
+
+func TestPerson_UnmarshalEmptyNumbers(t *testing.T) {
+	var p Person
+	body := `{"object": "person", "id": "abc", "$email": "kitty@monstercat.com", "$latitude": "", "$longitude": null, "$source": ""}`
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Email != "kitty@monstercat.com" || p.Latitude != 0 || p.Source != 0 {
+		t.Errorf("Unexpected person %+v", p)
+	}
+}
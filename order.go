@@ -0,0 +1,305 @@
+package klaviyo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoOrderId       = errors.New("missing order id")
+	ErrInvalidCurrency = errors.New("invalid currency, must be an ISO 4217 code e.g. USD")
+	ErrNoOrderItems    = errors.New("an order needs at least one item")
+	ErrInvalidQuantity = errors.New("item quantity must be positive")
+	ErrNegativeAmount  = errors.New("amounts cannot be negative")
+	ErrNoConverter     = errors.New("a currency converter is needed to report in another currency")
+
+	currencyRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Converts amounts between currencies, e.g. with the exchange rates of the day the order was placed.
+type CurrencyConverter interface {
+	Convert(amount float64, from, to string, at time.Time) (float64, error)
+}
+
+type CurrencyConverterFunc func(amount float64, from, to string, at time.Time) (float64, error)
+
+func (f CurrencyConverterFunc) Convert(amount float64, from, to string, at time.Time) (float64, error) {
+	return f(amount, from, to, at)
+}
+
+type OrderItem struct {
+	ProductId  string
+	SKU        string
+	Name       string
+	Quantity   int
+	Price      float64
+	Categories []string
+	URL        string
+	ImageURL   string
+}
+
+type OrderDiscount struct {
+	Code   string
+	Amount float64
+}
+
+// OrderBuilder builds a MetricPlacedOrder event. Every amount is in the order's currency and is converted to the
+// reporting currency when there is one, so stores selling in several currencies report revenue in a single one. The
+// first invalid value is returned by Build:
+//
+//	e, err := klaviyo.NewOrder("1001", "EUR").
+//		WithPerson(&person).
+//		AddItem(klaviyo.OrderItem{SKU: "MCS123", Name: "Hoodie", Quantity: 2, Price: 50}).
+//		AddDiscount("WELCOME10", 10).
+//		WithShipping(5).
+//		ReportIn("USD", converter).
+//		Build()
+//	err = client.CreateEvent(e)
+type OrderBuilder struct {
+	id        string
+	currency  string
+	person    *Person
+	items     []OrderItem
+	discounts []OrderDiscount
+	shipping  float64
+	tax       float64
+	at        time.Time
+	reportIn  string
+	converter CurrencyConverter
+	err       error
+}
+
+// Starts an order with the given id, which is used to deduplicate the event, and currency code.
+func NewOrder(orderId, currency string) *OrderBuilder {
+	b := &OrderBuilder{id: strings.TrimSpace(orderId), currency: strings.ToUpper(strings.TrimSpace(currency))}
+	return b.set(func() error {
+		if b.id == "" {
+			return ErrNoOrderId
+		}
+		return checkCurrency(b.currency)
+	})
+}
+
+func checkCurrency(currency string) error {
+	if !currencyRegexp.MatchString(currency) {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return nil
+}
+
+func (b *OrderBuilder) set(fn func() error) *OrderBuilder {
+	if b.err == nil {
+		b.err = fn()
+	}
+	return b
+}
+
+func (b *OrderBuilder) WithPerson(p *Person) *OrderBuilder {
+	b.person = p
+	return b
+}
+
+func (b *OrderBuilder) AddItem(item OrderItem) *OrderBuilder {
+	return b.set(func() error {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: %d of %q", ErrInvalidQuantity, item.Quantity, item.Name)
+		}
+		if item.Price < 0 {
+			return ErrNegativeAmount
+		}
+		b.items = append(b.items, item)
+		return nil
+	})
+}
+
+// Adds a discount taken off the order total.
+func (b *OrderBuilder) AddDiscount(code string, amount float64) *OrderBuilder {
+	return b.set(func() error {
+		if amount < 0 {
+			return ErrNegativeAmount
+		}
+		b.discounts = append(b.discounts, OrderDiscount{Code: code, Amount: amount})
+		return nil
+	})
+}
+
+func (b *OrderBuilder) WithShipping(amount float64) *OrderBuilder {
+	return b.set(func() error {
+		if amount < 0 {
+			return ErrNegativeAmount
+		}
+		b.shipping = amount
+		return nil
+	})
+}
+
+func (b *OrderBuilder) WithTax(amount float64) *OrderBuilder {
+	return b.set(func() error {
+		if amount < 0 {
+			return ErrNegativeAmount
+		}
+		b.tax = amount
+		return nil
+	})
+}
+
+// When the order was placed, also passed to the converter. Defaults to now.
+func (b *OrderBuilder) WithTime(at time.Time) *OrderBuilder {
+	b.at = at
+	return b
+}
+
+// Reports the amounts in currency, converted with c. Orders already in currency are not converted.
+func (b *OrderBuilder) ReportIn(currency string, c CurrencyConverter) *OrderBuilder {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	return b.set(func() error {
+		if c == nil {
+			return ErrNoConverter
+		}
+		b.reportIn, b.converter = currency, c
+		return checkCurrency(currency)
+	})
+}
+
+// Items minus discounts plus shipping and tax in the order's currency, never below 0.
+func (b *OrderBuilder) Total() float64 {
+	var total float64
+	for _, item := range b.items {
+		total += float64(item.Quantity) * item.Price
+	}
+	for _, d := range b.discounts {
+		total -= d.Amount
+	}
+	return roundAmount(math.Max(0, total+b.shipping+b.tax))
+}
+
+// Rounds to cents so floating point noise does not end up in Klaviyo's reports.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Returns the event to pass to CreateEvent. Its Value, the $value Klaviyo reports revenue with, is the order total.
+func (b *OrderBuilder) Build() (*NewEvent, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.items) == 0 {
+		return nil, ErrNoOrderItems
+	}
+	if b.person == nil || !b.person.HasProfileIdentifier() {
+		return nil, ErrNoProfileIdentifier
+	}
+	at := b.at
+	if at.IsZero() {
+		at = time.Now()
+	}
+	currency := b.currency
+	convert := func(amount float64) (float64, error) {
+		return amount, nil
+	}
+	if b.reportIn != "" && b.reportIn != b.currency {
+		currency = b.reportIn
+		convert = func(amount float64) (float64, error) {
+			if amount == 0 {
+				return 0, nil
+			}
+			res, err := b.converter.Convert(amount, b.currency, b.reportIn, at)
+			if err != nil {
+				return 0, fmt.Errorf("converting %s to %s: %w", b.currency, b.reportIn, err)
+			}
+			return roundAmount(res), nil
+		}
+	}
+
+	total, err := convert(b.Total())
+	if err != nil {
+		return nil, err
+	}
+	props := map[string]interface{}{
+		"OrderId":  b.id,
+		"Currency": currency,
+	}
+	if currency != b.currency {
+		props["OriginalCurrency"] = b.currency
+		props["OriginalValue"] = b.Total()
+	}
+
+	var items []map[string]interface{}
+	var names, categories []string
+	seen := map[string]bool{}
+	for _, item := range b.items {
+		price, err := convert(item.Price)
+		if err != nil {
+			return nil, err
+		}
+		x := map[string]interface{}{
+			"ProductName": item.Name,
+			"Quantity":    item.Quantity,
+			"ItemPrice":   price,
+			"RowTotal":    roundAmount(price * float64(item.Quantity)),
+		}
+		setNonEmpty(x, "ProductID", item.ProductId)
+		setNonEmpty(x, "SKU", item.SKU)
+		setNonEmpty(x, "ProductURL", item.URL)
+		setNonEmpty(x, "ImageURL", item.ImageURL)
+		if len(item.Categories) > 0 {
+			x["Categories"] = item.Categories
+		}
+		items = append(items, x)
+		names = append(names, item.Name)
+		for _, c := range item.Categories {
+			if !seen[c] {
+				seen[c] = true
+				categories = append(categories, c)
+			}
+		}
+	}
+	props["Items"] = items
+	props["ItemNames"] = names
+	if len(categories) > 0 {
+		props["Categories"] = categories
+	}
+
+	if len(b.discounts) > 0 {
+		var codes []string
+		var discount float64
+		for _, d := range b.discounts {
+			if d.Code != "" {
+				codes = append(codes, d.Code)
+			}
+			discount += d.Amount
+		}
+		if discount, err = convert(roundAmount(discount)); err != nil {
+			return nil, err
+		}
+		props["DiscountCode"] = strings.Join(codes, ",")
+		props["DiscountValue"] = discount
+	}
+	for key, amount := range map[string]float64{"Shipping": b.shipping, "Tax": b.tax} {
+		if amount == 0 {
+			continue
+		}
+		if props[key], err = convert(amount); err != nil {
+			return nil, err
+		}
+	}
+
+	return &NewEvent{
+		Metric:     MetricPlacedOrder,
+		Person:     b.person,
+		Properties: props,
+		Time:       at,
+		Value:      total,
+		UniqueId:   b.id,
+	}, nil
+}
+
+func setNonEmpty(m map[string]interface{}, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
@@ -0,0 +1,109 @@
+package klaviyo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	person := &Person{Email: "kitty@monstercat.com"}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	e, err := NewOrder("1001", "eur").
+		WithPerson(person).
+		AddItem(OrderItem{SKU: "MCS123", Name: "Hoodie", Quantity: 2, Price: 50.10, Categories: []string{"Merch"}}).
+		AddItem(OrderItem{Name: "Sticker", Quantity: 3, Price: 0.1, Categories: []string{"Merch"}}).
+		AddDiscount("WELCOME10", 10).
+		WithShipping(5).
+		WithTime(at).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Metric != MetricPlacedOrder || e.UniqueId != "1001" || !e.Time.Equal(at) {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if e.Value != 95.5 {
+		t.Errorf("Expected a total of 95.5, got %v", e.Value)
+	}
+	if e.Properties["Currency"] != "EUR" || e.Properties["DiscountCode"] != "WELCOME10" {
+		t.Errorf("Unexpected properties %v", e.Properties)
+	}
+	if cs := e.Properties["Categories"].([]string); len(cs) != 1 {
+		t.Errorf("Expected categories without duplicates, got %v", cs)
+	}
+	if _, ok := e.Properties["OriginalCurrency"]; ok {
+		t.Error("Did not expect an original currency without conversion")
+	}
+}
+
+func TestOrderBuilder_ReportIn(t *testing.T) {
+	var calls int
+	rates := CurrencyConverterFunc(func(amount float64, from, to string, at time.Time) (float64, error) {
+		calls++
+		if from != "EUR" || to != "USD" {
+			t.Errorf("Unexpected conversion from %s to %s", from, to)
+		}
+		return amount * 1.1, nil
+	})
+	e, err := NewOrder("1002", "EUR").
+		WithPerson(&Person{Email: "kitty@monstercat.com"}).
+		AddItem(OrderItem{Name: "Vinyl", Quantity: 1, Price: 30}).
+		ReportIn("usd", rates).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Value != 33 || e.Properties["Currency"] != "USD" || e.Properties["OriginalValue"] != 30.0 {
+		t.Errorf("Unexpected event %v %v", e.Value, e.Properties)
+	}
+	items := e.Properties["Items"].([]map[string]interface{})
+	if items[0]["ItemPrice"] != 33.0 {
+		t.Errorf("Expected converted item prices, got %v", items[0])
+	}
+
+	// Orders already in the reporting currency are not converted.
+	calls = 0
+	e, err = NewOrder("1003", "USD").
+		WithPerson(&Person{Email: "kitty@monstercat.com"}).
+		AddItem(OrderItem{Name: "Vinyl", Quantity: 1, Price: 30}).
+		ReportIn("USD", rates).
+		Build()
+	if err != nil || calls != 0 || e.Value != 30 {
+		t.Errorf("Unexpected conversion, got %v after %d calls: %v", e, calls, err)
+	}
+
+	failing := CurrencyConverterFunc(func(float64, string, string, time.Time) (float64, error) {
+		return 0, errors.New("no rate")
+	})
+	_, err = NewOrder("1004", "EUR").
+		WithPerson(&Person{Email: "kitty@monstercat.com"}).
+		AddItem(OrderItem{Name: "Vinyl", Quantity: 1, Price: 30}).
+		ReportIn("USD", failing).
+		Build()
+	if err == nil {
+		t.Error("Expected the converter's error")
+	}
+}
+
+func TestOrderBuilder_Invalid(t *testing.T) {
+	person := &Person{Email: "kitty@monstercat.com"}
+	item := OrderItem{Name: "Vinyl", Quantity: 1, Price: 30}
+	tests := []struct {
+		b   *OrderBuilder
+		err error
+	}{
+		{NewOrder("", "USD").WithPerson(person).AddItem(item), ErrNoOrderId},
+		{NewOrder("1", "dollars").WithPerson(person).AddItem(item), ErrInvalidCurrency},
+		{NewOrder("1", "USD").WithPerson(person), ErrNoOrderItems},
+		{NewOrder("1", "USD").WithPerson(person).AddItem(OrderItem{Name: "Vinyl"}), ErrInvalidQuantity},
+		{NewOrder("1", "USD").WithPerson(person).AddItem(item).AddDiscount("", -1), ErrNegativeAmount},
+		{NewOrder("1", "USD").AddItem(item), ErrNoProfileIdentifier},
+		{NewOrder("1", "USD").WithPerson(person).AddItem(item).ReportIn("EUR", nil), ErrNoConverter},
+	}
+	for i, test := range tests {
+		if _, err := test.b.Build(); !errors.Is(err, test.err) {
+			t.Errorf("%d: expected %v, got %v", i, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// How many times in a row a rate limited page is waited out before giving up.
+	maxRateLimitRetries = 10
+
+	// Klaviyo does not always tell us how long to wait.
+	defaultRetryAfter = time.Second
+
+	// Once fewer than this share of the window's requests are left the remaining ones are spread over the rest of the
+	// window instead of being sent right away.
+	paceThreshold = 0.1
+)
+
+// How long to wait before the next request so the rate limit is not hit, based on the headers of the last response.
+// 0 while plenty of requests are left or when Klaviyo did not send rate limit headers.
+func paceDelay(rl RateLimit) time.Duration {
+	if rl.Limit <= 0 || rl.Reset <= 0 {
+		return 0
+	}
+	if rl.Remaining <= 0 {
+		return rl.Reset
+	}
+	if float64(rl.Remaining) >= float64(rl.Limit)*paceThreshold {
+		return 0
+	}
+	return rl.Reset / time.Duration(rl.Remaining+1)
+}
+
+// GET https://a.klaviyo.com/api/v2/group/group_id/members/all
+// Calls fn for every member of a list or segment, going through all pages. The rate limit headers of each page are
+// used to slow down before Klaviyo starts rejecting requests, and rate limited pages are waited out and fetched again.
+// Use a context with a deadline to cap how long the whole iteration may take, the context's error is returned once
+// it is done.
+func (c *Client) EachGroupMember(ctx context.Context, groupId string, fn func(ListPerson) error) error {
+	return c.EachGroupMemberFrom(ctx, groupId, &Cursor{}, fn)
+}
+
+// Same as EachGroupMember starting from cursor.Marker, cursor is kept up to date so the iteration can be resumed.
+func (c *Client) EachGroupMemberFrom(ctx context.Context, groupId string, cursor *Cursor, fn func(ListPerson) error) error {
+	if cursor.Done {
+		return nil
+	}
+	var meta ResponseMeta
+	cc := c.WithContext(ctx).WithResponseMeta(&meta)
+	var retries int
+	for {
+		next, err := cc.StreamGroupMembers(groupId, cursor.Marker, fn)
+		if err != nil {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || retries >= maxRateLimitRetries {
+				return err
+			}
+			retries++
+			wait := apiErr.RetryAfter
+			if wait <= 0 {
+				wait = defaultRetryAfter
+			}
+			if err := cc.sleep(wait); err != nil {
+				return err
+			}
+			continue
+		}
+		retries = 0
+		if next == 0 {
+			cursor.Done = true
+			return nil
+		}
+		cursor.Marker = next
+		if wait := paceDelay(meta.RateLimit); wait > 0 {
+			if err := cc.sleep(wait); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type indexedMember struct {
+	i int
+	p ListPerson
+}
+
+// Calls fn for every member of a list or segment from up to concurrency goroutines at once, e.g. to update everyone
+// in a list. Pages are fetched like EachGroupMember while the workers are busy. An error from fn does not stop the
+// others, they are returned together in a *BatchError keyed by the position of the member in the list. Errors getting
+// the members, including ctx ending, stop everything and are returned instead.
+func (c *Client) ForEachListMember(ctx context.Context, listId string, concurrency int, fn func(ListPerson) error) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	members := make(chan indexedMember)
+	var mu sync.Mutex
+	batchErr := &BatchError{Errors: map[int]error{}}
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range members {
+				if err := fn(m.p); err != nil {
+					mu.Lock()
+					batchErr.Errors[m.i] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	err := c.EachGroupMember(ctx, listId, func(p ListPerson) error {
+		select {
+		case members <- indexedMember{i: batchErr.Total, p: p}:
+			batchErr.Total++
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(members)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPaceDelay(t *testing.T) {
+	tests := []struct {
+		rl   RateLimit
+		want time.Duration
+	}{
+		{RateLimit{}, 0},
+		{RateLimit{Limit: 100, Remaining: 50, Reset: time.Minute}, 0},
+		{RateLimit{Limit: 100, Remaining: 0, Reset: time.Minute}, time.Minute},
+		{RateLimit{Limit: 100, Remaining: 5, Reset: time.Minute}, 10 * time.Second},
+	}
+	for _, test := range tests {
+		if got := paceDelay(test.rl); got != test.want {
+			t.Errorf("paceDelay(%+v) = %s, expected %s", test.rl, got, test.want)
+		}
+	}
+}
+
+func TestClient_EachGroupMember(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Header().Set("X-RateLimit-Limit", "75")
+		w.Header().Set("X-RateLimit-Reset", "60")
+		switch r.URL.Query().Get("marker") {
+		case "":
+			w.Header().Set("X-RateLimit-Remaining", "50")
+			w.Write([]byte(`{"records": [{"id": "abc"}], "marker": 123}`))
+		case "123":
+			// Out of requests, the next page should wait for the window to reset.
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Write([]byte(`{"records": [{"id": "def"}], "marker": 456}`))
+		default:
+			t.Errorf("Unexpected marker %s", r.URL.Query().Get("marker"))
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var ids []string
+	err := client.EachGroupMember(ctx, "LIST1", func(p ListPerson) error {
+		ids = append(ids, p.Id)
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to cut the wait short, got %v", err)
+	}
+	if hits != 2 || len(ids) != 2 {
+		t.Errorf("Expected 2 pages before pacing, got %d requests and members %v", hits, ids)
+	}
+}
+
+func TestClient_ForEachListMember(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Query().Get("marker") {
+		case "":
+			w.Write([]byte(`{"records": [{"id": "a"}, {"id": "b"}, {"id": "c"}], "marker": 123}`))
+		case "123":
+			w.Write([]byte(`{"records": [{"id": "d"}, {"id": "e"}]}`))
+		}
+	})
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	err := client.ForEachListMember(context.Background(), "LIST1", 3, func(p ListPerson) error {
+		mu.Lock()
+		seen[p.Id] = true
+		mu.Unlock()
+		if p.Id == "d" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *BatchError, got %v", err)
+	}
+	if batchErr.Total != 5 || len(batchErr.Errors) != 1 || batchErr.Errors[3] == nil {
+		t.Errorf("Unexpected errors %+v", batchErr)
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected every member to be visited, got %v", seen)
+	}
+}
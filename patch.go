@@ -0,0 +1,82 @@
+package klaviyo
+
+// ProfilePatch changes custom attributes in place through Klaviyo's $append, $unappend and $unset operations, so
+// list values can be added to and attributes removed without knowing or overwriting the current value:
+//
+//	patch := klaviyo.NewProfilePatch().Append("Genres", "house").Unset("OldFlag")
+//	err := client.PatchPerson(&klaviyo.Person{Email: email}, patch)
+type ProfilePatch struct {
+	// Values added to list attributes, the attribute becomes a list if it was not one.
+	Appends map[string]interface{}
+
+	// Values removed from list attributes.
+	Unappends map[string]interface{}
+
+	// Attributes removed from the profile.
+	Unsets []string
+}
+
+func NewProfilePatch() *ProfilePatch {
+	return &ProfilePatch{}
+}
+
+func (p *ProfilePatch) Append(name string, value interface{}) *ProfilePatch {
+	if p.Appends == nil {
+		p.Appends = map[string]interface{}{}
+	}
+	p.Appends[name] = value
+	return p
+}
+
+func (p *ProfilePatch) Unappend(name string, value interface{}) *ProfilePatch {
+	if p.Unappends == nil {
+		p.Unappends = map[string]interface{}{}
+	}
+	p.Unappends[name] = value
+	return p
+}
+
+func (p *ProfilePatch) Unset(names ...string) *ProfilePatch {
+	p.Unsets = append(p.Unsets, names...)
+	return p
+}
+
+// Checks every attribute name the same way custom attributes are checked.
+func (p *ProfilePatch) Validate() error {
+	for _, m := range []map[string]interface{}{p.Appends, p.Unappends} {
+		if err := Attributes(m).Validate(); err != nil {
+			return err
+		}
+	}
+	for _, name := range p.Unsets {
+		if err := ValidateAttributeName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProfilePatch) apply(props map[string]interface{}) {
+	if len(p.Appends) > 0 {
+		props["$append"] = p.Appends
+	}
+	if len(p.Unappends) > 0 {
+		props["$unappend"] = p.Unappends
+	}
+	if len(p.Unsets) > 0 {
+		props["$unset"] = p.Unsets
+	}
+}
+
+// Applies the patch along with the rest of the Identify call.
+func WithPatch(patch *ProfilePatch) IdentifyOption {
+	return func(o *identifyOptions) {
+		o.patch = patch
+	}
+}
+
+// Applies the patch to the person without changing any of their other fields. Only the profile identifiers of person
+// are used.
+func (c *Client) PatchPerson(person *Person, patch *ProfilePatch) error {
+	return c.IdentifySafe(person, true, WithFields(), WithPatch(patch))
+}
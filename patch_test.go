@@ -0,0 +1,47 @@
+package klaviyo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_PatchPerson(t *testing.T) {
+	var props map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("data"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload struct {
+			Properties map[string]interface{} `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatal(err)
+		}
+		props = payload.Properties
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("1"))
+	})
+	client.DefaultAttributes = Attributes{"source": "backend"}
+	patch := NewProfilePatch().Append("Genres", "house").Unappend("Genres", "dubstep").Unset("OldFlag")
+	person := &Person{Email: "kitty@monstercat.com", FirstName: "Kitty"}
+	if err := client.PatchPerson(person, patch); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"$email":    "kitty@monstercat.com",
+		"$append":   map[string]interface{}{"Genres": "house"},
+		"$unappend": map[string]interface{}{"Genres": "dubstep"},
+		"$unset":    []interface{}{"OldFlag"},
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("Expected %v, got %v", expected, props)
+	}
+
+	if err := client.PatchPerson(person, NewProfilePatch().Unset("$email")); err == nil {
+		t.Error("Expected an error for reserved attribute names")
+	}
+}
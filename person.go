@@ -2,8 +2,11 @@ package klaviyo
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Attributes map[string]interface{}
@@ -24,6 +27,127 @@ func (a Attributes) ParseBool(key string) bool {
 	return false
 }
 
+// Returns the value as a string, numbers and booleans are formatted. Returns an empty string when the key is missing.
+func (a Attributes) ParseString(key string) string {
+	switch v := a[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Returns the value as an integer, parsing strings and truncating fractions. Returns 0 when the key is missing or the
+// value is not a number.
+func (a Attributes) ParseInt(key string) int {
+	return int(a.ParseFloat(key))
+}
+
+// Returns the value as a float, parsing strings. Returns 0 when the key is missing or the value is not a number.
+func (a Attributes) ParseFloat(key string) float64 {
+	switch v := a[key].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case KFloat:
+		return float64(v)
+	case KInt:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	}
+	return 0
+}
+
+// Returns the value as a time, accepting the same formats as KTime. Returns the zero time when the key is missing or
+// the value is not a time.
+func (a Attributes) ParseTime(key string) time.Time {
+	switch v := a[key].(type) {
+	case time.Time:
+		return v
+	case KTime:
+		return v.Time
+	case nil:
+		return time.Time{}
+	}
+	xs, err := json.Marshal(a[key])
+	if err != nil {
+		return time.Time{}
+	}
+	var t KTime
+	if err := t.UnmarshalJSON(xs); err != nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// Returns the value as a list of strings. Lists stored as a JSON encoded string are decoded and any other single value
+// becomes a list of one. Returns nil when the key is missing.
+func (a Attributes) ParseStringSlice(key string) []string {
+	switch v := a[key].(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		xs := make([]string, len(v))
+		for i, x := range v {
+			xs[i] = Attributes{"": x}.ParseString("")
+		}
+		return xs
+	case string:
+		var xs []string
+		if strings.HasPrefix(v, "[") && json.Unmarshal([]byte(v), &xs) == nil {
+			return xs
+		}
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+	return []string{a.ParseString(key)}
+}
+
+// Sets the value converted to what Klaviyo stores, so it reads back the same after a round trip: times become ISO 8601
+// strings, KFloat and KInt become plain numbers and other integers become float64 like decoded JSON numbers.
+func (a Attributes) Set(key string, value interface{}) {
+	switch v := value.(type) {
+	case time.Time:
+		a[key] = v.UTC().Format(time.RFC3339)
+	case KTime:
+		a[key] = v.UTC().Format(time.RFC3339)
+	case KFloat:
+		a[key] = float64(v)
+	case KInt:
+		a[key] = float64(v)
+	case int:
+		a[key] = float64(v)
+	case int64:
+		a[key] = float64(v)
+	case float32:
+		a[key] = float64(v)
+	default:
+		a[key] = value
+	}
+}
+
+//go:generate go run ./internal/fieldgen -type Person -output person_fields.go person.go
+
 type Person struct {
 	Object
 
@@ -52,22 +176,79 @@ type Person struct {
 	Title        string   `json:"$title"`
 	Zip          string   `json:"$zip"`
 
+	// Consent provenance, only used by the legacy identify and subscribe endpoints and left out of GetMap when empty.
+	// The timestamp is in RFC 3339.
+	ConsentMethod    string `json:"$consent_method,omitempty"`
+	ConsentTimestamp string `json:"$consent_timestamp,omitempty"`
+	ConsentFormId    string `json:"$consent_form_id,omitempty"`
+
 	// Use these to have custom attributes tied to a user that can be used to create segments for lists.
 	Attributes Attributes
 }
 
-// A profile identifier is an email or phone number. In the case of SMS they must have a phone number.
+// A profile identifier is an email, phone number or custom id ($id, called external_id in v3). In the case of SMS they
+// must have a phone number.
 func (p *Person) HasProfileIdentifier() bool {
-	return !(strings.TrimSpace(p.Email) == "" && strings.TrimSpace(p.PhoneNumber) == "")
+	return !(strings.TrimSpace(p.Email) == "" && strings.TrimSpace(p.PhoneNumber) == "" && strings.TrimSpace(p.CustomId) == "")
 }
 
 func (p *Person) GetMap() map[string]interface{} {
-	m := map[string]interface{}{}
+	m := make(map[string]interface{}, len(p.Attributes)+len(personSpecialKeys))
 	for k, v := range p.Attributes {
 		m[k] = v
 	}
-	for k, v := range structToMap(p) {
-		m[k] = v
+	p.writeSpecialFields(m)
+	return m
+}
+
+// Returns the special fields and attributes of other which are different from p, keyed the same way as GetMap.
+// Attributes which p has but other does not are not included since they cannot be removed by updating.
+func (p *Person) Diff(other *Person) map[string]interface{} {
+	before := p.GetMap()
+	diff := map[string]interface{}{}
+	for k, v := range other.GetMap() {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			diff[k] = v
+		}
+	}
+	return diff
+}
+
+// Converts the person to the attributes of a v3 profile resource. Empty values are left out so they do not overwrite
+// existing values in Klaviyo.
+func (p *Person) profileAttributes() map[string]interface{} {
+	m := map[string]interface{}{}
+	set := func(dst map[string]interface{}, key, val string) {
+		if val != "" {
+			dst[key] = val
+		}
+	}
+	set(m, "email", p.Email)
+	set(m, "phone_number", p.PhoneNumber)
+	set(m, "external_id", p.CustomId)
+	set(m, "first_name", p.FirstName)
+	set(m, "last_name", p.LastName)
+	set(m, "organization", p.Organization)
+	set(m, "title", p.Title)
+	set(m, "image", p.Image)
+
+	location := map[string]interface{}{}
+	set(location, "address1", p.Address1)
+	set(location, "address2", p.Address2)
+	set(location, "city", p.City)
+	set(location, "country", p.Country)
+	set(location, "region", p.Region)
+	set(location, "zip", p.Zip)
+	set(location, "timezone", p.Timezone)
+	if p.Latitude != 0 || p.Longitude != 0 {
+		location["latitude"] = p.Latitude
+		location["longitude"] = p.Longitude
+	}
+	if len(location) > 0 {
+		m["location"] = location
+	}
+	if len(p.Attributes) > 0 {
+		m["properties"] = p.Attributes
 	}
 	return m
 }
@@ -77,57 +258,45 @@ func (p *Person) MarshalJSON() ([]byte, error) {
 }
 
 func (p *Person) UnmarshalJSON(data []byte) error {
-	m := map[string]interface{}{}
+	var m map[string]json.RawMessage
 	if err := json.Unmarshal(data, &m); err != nil {
 		return err
 	}
 
-	type Person2 Person
-	var p2 Person2
-	if err := json.Unmarshal(data, &p2); err != nil {
-		return err
-	}
-
-	// Remove keys natively supported by klaviyo
-	delete(m, "id")
-	delete(m, "object")
-	for k, _ := range m {
-		if len(k) <= 0 {
-			continue
+	*p = Person{Attributes: Attributes{}}
+	for k, raw := range m {
+		var err error
+		switch {
+		case k == "id":
+			err = json.Unmarshal(raw, &p.Id)
+		case k == "object":
+			err = json.Unmarshal(raw, &p.Object.Object)
+		case strings.HasPrefix(k, "$"):
+			// Special properties this SDK does not know about are dropped.
+			_, err = p.unmarshalSpecialField(k, raw)
+		default:
+			var v interface{}
+			err = json.Unmarshal(raw, &v)
+			p.Attributes[k] = v
 		}
-		if k[0] != '$' {
-			continue
+		if err != nil {
+			return err
 		}
-		delete(m, k)
 	}
-
-	*p = Person(p2)
-	p.Attributes = m
 	return nil
 }
 
-func structToMap(item interface{}) map[string]interface{} {
-	res := map[string]interface{}{}
-	if item == nil {
-		return res
-	}
-	v := reflect.TypeOf(item)
-	reflectValue := reflect.ValueOf(item)
-	reflectValue = reflect.Indirect(reflectValue)
-
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	for i := 0; i < v.NumField(); i++ {
-		tag := v.Field(i).Tag.Get("json")
-		field := reflectValue.Field(i).Interface()
-		if tag != "" && tag != "-" {
-			if v.Field(i).Type.Kind() == reflect.Struct {
-				res[tag] = structToMap(field)
-			} else {
-				res[tag] = field
-			}
+// Copies p along with its attributes and consent, which a plain copy would share with the caller.
+func copyPerson(p *Person) *Person {
+	cp := *p
+	if p.Attributes != nil {
+		cp.Attributes = make(Attributes, len(p.Attributes))
+		for k, v := range p.Attributes {
+			cp.Attributes[k] = v
 		}
 	}
-	return res
+	if p.Consent != nil {
+		cp.Consent = append([]string(nil), p.Consent...)
+	}
+	return &cp
 }
@@ -0,0 +1,152 @@
+package klaviyo
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrInvalidPhoneNumber = errors.New("invalid phone number, must be in E.164 format e.g. +15555555555")
+	ErrUnknownConsent     = errors.New("unknown consent, must be one of email, web, sms, directmail or mobile")
+
+	e164Regexp = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+	knownConsents = map[string]bool{
+		ConsentEmail:  true,
+		ConsentWeb:    true,
+		ConsentSMS:    true,
+		ConsentDirect: true,
+		ConsentMobile: true,
+	}
+)
+
+// PersonBuilder builds a Person, checking every value as it is set. The first invalid value is returned by Build and
+// later setters are ignored:
+//
+//	p, err := klaviyo.NewPerson("kitty@monstercat.com").
+//		WithFirstName("Kitty").
+//		WithAttribute("LikesGold", true).
+//		WithConsent(klaviyo.ConsentEmail).
+//		Build()
+type PersonBuilder struct {
+	p   Person
+	err error
+}
+
+// Starts a person identified by email. Use NewPersonBuilder to start from a phone number or custom id instead.
+func NewPerson(email string) *PersonBuilder {
+	return NewPersonBuilder().WithEmail(email)
+}
+
+func NewPersonBuilder() *PersonBuilder {
+	return &PersonBuilder{}
+}
+
+func (b *PersonBuilder) set(fn func() error) *PersonBuilder {
+	if b.err == nil {
+		b.err = fn()
+	}
+	return b
+}
+
+func (b *PersonBuilder) WithEmail(email string) *PersonBuilder {
+	return b.set(func() error {
+		email = strings.TrimSpace(email)
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+		}
+		b.p.Email = email
+		return nil
+	})
+}
+
+func (b *PersonBuilder) WithPhoneNumber(phone string) *PersonBuilder {
+	return b.set(func() error {
+		if !e164Regexp.MatchString(phone) {
+			return fmt.Errorf("%w: %q", ErrInvalidPhoneNumber, phone)
+		}
+		b.p.PhoneNumber = phone
+		return nil
+	})
+}
+
+func (b *PersonBuilder) WithCustomId(id string) *PersonBuilder {
+	return b.set(func() error {
+		b.p.CustomId = strings.TrimSpace(id)
+		return nil
+	})
+}
+
+func (b *PersonBuilder) WithFirstName(name string) *PersonBuilder {
+	return b.set(func() error {
+		b.p.FirstName = strings.TrimSpace(name)
+		return nil
+	})
+}
+
+func (b *PersonBuilder) WithLastName(name string) *PersonBuilder {
+	return b.set(func() error {
+		b.p.LastName = strings.TrimSpace(name)
+		return nil
+	})
+}
+
+// Sets the location fields, empty values are left as they are.
+func (b *PersonBuilder) WithLocation(city, region, country, zip string) *PersonBuilder {
+	return b.set(func() error {
+		for dst, v := range map[*string]string{&b.p.City: city, &b.p.Region: region, &b.p.Country: country, &b.p.Zip: zip} {
+			if v = strings.TrimSpace(v); v != "" {
+				*dst = v
+			}
+		}
+		return nil
+	})
+}
+
+// Sets a custom attribute. The name is checked with ValidateAttributeName and the value is stored with
+// Attributes.Set.
+func (b *PersonBuilder) WithAttribute(name string, value interface{}) *PersonBuilder {
+	return b.set(func() error {
+		if err := ValidateAttributeName(name); err != nil {
+			return err
+		}
+		b.p.attributes().Set(name, value)
+		return nil
+	})
+}
+
+// Adds channels the person consented to, e.g. ConsentEmail and ConsentSMS.
+func (b *PersonBuilder) WithConsent(consents ...string) *PersonBuilder {
+	return b.set(func() error {
+		for _, c := range consents {
+			if !knownConsents[c] {
+				return fmt.Errorf("%w: %q", ErrUnknownConsent, c)
+			}
+			b.p.Consent = append(b.p.Consent, c)
+		}
+		return nil
+	})
+}
+
+// Returns the person or the first invalid value. The person must have a profile identifier.
+func (b *PersonBuilder) Build() (*Person, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if !b.p.HasProfileIdentifier() {
+		return nil, ErrNoProfileIdentifier
+	}
+	p := b.p
+	if b.p.Attributes != nil {
+		p.Attributes = Attributes{}
+		for k, v := range b.p.Attributes {
+			p.Attributes[k] = v
+		}
+	}
+	p.Consent = append([]string(nil), b.p.Consent...)
+	return &p, nil
+}
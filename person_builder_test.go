@@ -0,0 +1,59 @@
+package klaviyo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPersonBuilder(t *testing.T) {
+	p, err := NewPerson("kitty@monstercat.com").
+		WithFirstName(" Kitty ").
+		WithPhoneNumber("+15555555555").
+		WithLocation("Vancouver", "BC", "Canada", "").
+		WithAttribute("LikesGold", true).
+		WithAttribute("Count", 3).
+		WithConsent(ConsentEmail, ConsentSMS).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Email != "kitty@monstercat.com" || p.FirstName != "Kitty" || p.PhoneNumber != "+15555555555" || p.City != "Vancouver" {
+		t.Errorf("Unexpected person %+v", p)
+	}
+	if p.Attributes["LikesGold"] != true || p.Attributes["Count"] != float64(3) {
+		t.Errorf("Unexpected attributes %v", p.Attributes)
+	}
+	if !reflect.DeepEqual(p.Consent, []string{ConsentEmail, ConsentSMS}) {
+		t.Errorf("Unexpected consent %v", p.Consent)
+	}
+}
+
+func TestPersonBuilder_Errors(t *testing.T) {
+	tests := []struct {
+		b   *PersonBuilder
+		err error
+	}{
+		{NewPerson("not an email"), ErrInvalidEmail},
+		{NewPerson("Kitty <kitty@monstercat.com>"), ErrInvalidEmail},
+		{NewPersonBuilder().WithPhoneNumber("555-5555"), ErrInvalidPhoneNumber},
+		{NewPerson("kitty@monstercat.com").WithConsent("fax"), ErrUnknownConsent},
+		{NewPersonBuilder().WithFirstName("Kitty"), ErrNoProfileIdentifier},
+	}
+	for i, test := range tests {
+		if _, err := test.b.Build(); !errors.Is(err, test.err) {
+			t.Errorf("Test %d: expected %v, got %v", i, test.err, err)
+		}
+	}
+
+	// The first error wins.
+	_, err := NewPerson("bad").WithAttribute("$bad", 1).Build()
+	if !errors.Is(err, ErrInvalidEmail) {
+		t.Errorf("Expected the first error, got %v", err)
+	}
+	_, err = NewPerson("kitty@monstercat.com").WithAttribute("$bad", 1).Build()
+	var attrErr *InvalidAttributeError
+	if !errors.As(err, &attrErr) {
+		t.Errorf("Expected InvalidAttributeError, got %v", err)
+	}
+}
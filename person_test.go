@@ -3,7 +3,10 @@ package klaviyo
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // A test person for our test cases. If you change this please make sure to update the tests!
@@ -39,6 +42,11 @@ func TestPerson_HasProfileIdentifier(t *testing.T) {
 	if p.HasProfileIdentifier() != false {
 		t.Error("should have returned false with no PhoneNumber or Email")
 	}
+	p.CustomId = "user-1"
+	if p.HasProfileIdentifier() == false {
+		t.Error("should have returned true with CustomId but no PhoneNumber or Email")
+	}
+	p.CustomId = ""
 	p.PhoneNumber = "+1234567890"
 	if p.HasProfileIdentifier() == false {
 		t.Error("should have returned true with PhoneNumber but no Email")
@@ -80,6 +88,15 @@ func TestPerson_GetMap(t *testing.T) {
 	} else if len(arr) != len(p.Consent) {
 		t.Errorf("Expected %d values for $consent.", len(p.Consent))
 	}
+	for _, key := range []string{"$consent_method", "$consent_timestamp", "$consent_form_id"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("Expected empty %s to be left out", key)
+		}
+	}
+	p.ConsentMethod = "Website Form"
+	if m := p.GetMap(); m["$consent_method"] != p.ConsentMethod {
+		t.Error("Field ConsentMethod did not match map value.")
+	}
 }
 
 func TestPerson_JSON(t *testing.T) {
@@ -123,3 +140,190 @@ func TestPerson_JSON(t *testing.T) {
 		t.Error("Attribute did not match")
 	}
 }
+
+func TestPerson_Diff(t *testing.T) {
+	a := newTestPerson()
+	b := newTestPerson()
+	if diff := a.Diff(&b); len(diff) != 0 {
+		t.Errorf("Expected no changes, got %v", diff)
+	}
+
+	b.City = "Toronto"
+	b.Consent = []string{ConsentEmail}
+	b.Attributes = map[string]interface{}{attrIsTest: true, attrLikesGold: true}
+	diff := a.Diff(&b)
+	if len(diff) != 3 {
+		t.Errorf("Expected 3 changes, got %v", diff)
+	}
+	if diff["$city"] != "Toronto" {
+		t.Error("Expected $city to change")
+	}
+	if _, ok := diff["$consent"]; !ok {
+		t.Error("Expected $consent to change")
+	}
+	if diff[attrLikesGold] != true {
+		t.Errorf("Expected %s to be added", attrLikesGold)
+	}
+	if _, ok := diff[attrIsTest]; ok {
+		t.Errorf("Did not expect %s to change", attrIsTest)
+	}
+
+	// Removed attributes cannot be sent so they are ignored.
+	if diff := b.Diff(&a); len(diff) != 2 {
+		t.Errorf("Expected 2 changes, got %v", diff)
+	}
+}
+
+// Person is the only profile type in this package. The special fields are mapped by their JSON tags for v1/v2 and by
+// profileAttributes for v3, this makes sure the two do not drift apart when fields are added.
+func TestPerson_FieldMappings(t *testing.T) {
+	// These special properties have no v3 profile attribute.
+	noV3 := map[string]bool{
+		"$consent":           true,
+		"$source":            true,
+		"$consent_method":    true,
+		"$consent_timestamp": true,
+		"$consent_form_id":   true,
+	}
+
+	seen := map[string]bool{}
+	typ := reflect.TypeOf(Person{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" {
+			continue
+		}
+		if !strings.HasPrefix(tag, "$") {
+			t.Errorf("Special field %s should use a $ prefixed key, got %s", typ.Field(i).Name, tag)
+		}
+		if seen[tag] {
+			t.Errorf("Key %s is used by more than one field", tag)
+		}
+		seen[tag] = true
+
+		// Setting only this field must show up in the v3 attributes.
+		var p Person
+		v := reflect.ValueOf(&p).Elem().Field(i)
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString("x")
+		case reflect.Float64:
+			v.SetFloat(1)
+		case reflect.Int:
+			v.SetInt(1)
+		case reflect.Slice:
+			v.Set(reflect.ValueOf([]string{"x"}))
+		default:
+			t.Fatalf("Unhandled kind %s for %s", v.Kind(), tag)
+		}
+		if noV3[tag] {
+			continue
+		}
+		if len(p.profileAttributes()) == 0 {
+			t.Errorf("Field %s is not mapped to a v3 profile attribute", typ.Field(i).Name)
+		}
+	}
+
+	// Unmarshalling must strip every special key from the attributes.
+	a := newTestPerson()
+	xs, _ := json.Marshal(&a)
+	var p Person
+	if err := json.Unmarshal(xs, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Attributes) != len(a.Attributes) {
+		t.Errorf("Expected only custom attributes, got %v", p.Attributes)
+	}
+}
+
+func TestAttributes_Parse(t *testing.T) {
+	a := Attributes{
+		"Name":       "Kitty",
+		"Count":      float64(3),
+		"CountStr":   " 42 ",
+		"Price":      "9.99",
+		"Unix":       float64(1667913255),
+		"ISO":        "2022-11-08T13:14:15Z",
+		"Genres":     []interface{}{"edm", "house"},
+		"GenresJSON": `["edm","house"]`,
+		"Genre":      "edm",
+		"Bool":       true,
+	}
+	if a.ParseString("Name") != "Kitty" || a.ParseString("Count") != "3" || a.ParseString("Bool") != "true" {
+		t.Error("Unexpected ParseString result")
+	}
+	if a.ParseString("Missing") != "" {
+		t.Error("Expected empty string for missing key")
+	}
+	if a.ParseInt("Count") != 3 || a.ParseInt("CountStr") != 42 || a.ParseInt("Name") != 0 {
+		t.Error("Unexpected ParseInt result")
+	}
+	if a.ParseFloat("Price") != 9.99 {
+		t.Errorf("Unexpected ParseFloat result %f", a.ParseFloat("Price"))
+	}
+	expected := time.Date(2022, 11, 8, 13, 14, 15, 0, time.UTC)
+	if !a.ParseTime("Unix").Equal(expected) || !a.ParseTime("ISO").Equal(expected) {
+		t.Errorf("Unexpected ParseTime result %s %s", a.ParseTime("Unix"), a.ParseTime("ISO"))
+	}
+	if !a.ParseTime("Name").IsZero() || !a.ParseTime("Missing").IsZero() {
+		t.Error("Expected zero time for values which are not times")
+	}
+	genres := []string{"edm", "house"}
+	if !reflect.DeepEqual(a.ParseStringSlice("Genres"), genres) || !reflect.DeepEqual(a.ParseStringSlice("GenresJSON"), genres) {
+		t.Error("Unexpected ParseStringSlice result")
+	}
+	if !reflect.DeepEqual(a.ParseStringSlice("Genre"), []string{"edm"}) || a.ParseStringSlice("Missing") != nil {
+		t.Error("Unexpected ParseStringSlice result for single values")
+	}
+}
+
+func TestAttributes_Set(t *testing.T) {
+	a := Attributes{}
+	at := time.Date(2022, 11, 8, 13, 14, 15, 0, time.FixedZone("PST", -8*3600))
+	a.Set("At", at)
+	a.Set("Count", 3)
+	a.Set("Lat", KFloat(1.5))
+	a.Set("Name", "Kitty")
+	if a["At"] != "2022-11-08T21:14:15Z" {
+		t.Errorf("Unexpected time %v", a["At"])
+	}
+	if a["Count"] != float64(3) || a["Lat"] != 1.5 || a["Name"] != "Kitty" {
+		t.Errorf("Unexpected attributes %v", a)
+	}
+	if !a.ParseTime("At").Equal(at) {
+		t.Error("Time did not read back the same")
+	}
+}
+
+func benchmarkPerson() *Person {
+	return &Person{
+		Email:      "kitty@monstercat.com",
+		FirstName:  "Kitty",
+		LastName:   "Cat",
+		City:       "Vancouver",
+		Consent:    []string{ConsentEmail},
+		Attributes: Attributes{"LikesGold": true, "Tier": "gold", "Visits": 12},
+	}
+}
+
+func BenchmarkPerson_GetMap(b *testing.B) {
+	p := benchmarkPerson()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.GetMap()
+	}
+}
+
+func BenchmarkPerson_UnmarshalJSON(b *testing.B) {
+	xs, err := json.Marshal(benchmarkPerson())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var p Person
+		if err := json.Unmarshal(xs, &p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package klaviyo
+
+import (
+	"net/url"
+)
+
+// Payload of the legacy track endpoint.
+type trackPayload struct {
+	Token              string                 `json:"token"`
+	Event              string                 `json:"event"`
+	CustomerProperties map[string]interface{} `json:"customer_properties"`
+	Properties         map[string]interface{} `json:"properties,omitempty"`
+	Time               int64                  `json:"time,omitempty"`
+}
+
+// https://apidocs.klaviyo.com/reference/track-identify#track
+// GET https://a.klaviyo.com/api/track
+// Returns a URL which tracks e once it is requested, so the request can come from the person themselves, e.g. through
+// a redirect. Empty values of the person are left out like IdentifySafe does to keep the URL short. Value and UniqueId
+// are sent as the $value and $event_id properties. The URL holds the public key and the person's identifiers, only
+// show it to that person.
+func (c *Client) TrackURL(e *NewEvent) (string, error) {
+	u, err := c.trackURL(e)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Same as TrackURL but Klaviyo answers with a 1x1 GIF, for embedding as an <img> in server rendered emails to track
+// opens.
+func (c *Client) TrackPixelURL(e *NewEvent) (string, error) {
+	u, err := c.trackURL(e)
+	if err != nil {
+		return "", err
+	}
+	values := u.Query()
+	values.Set("i", "1")
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+func (c *Client) trackURL(e *NewEvent) (*url.URL, error) {
+	if e.Metric == "" {
+		return nil, ErrNoMetricName
+	}
+	if e.Person == nil || !e.Person.HasProfileIdentifier() {
+		return nil, ErrNoProfileIdentifier
+	}
+	publicKey, err := c.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	person, err := c.checkAttributes(e.Person)
+	if err != nil {
+		return nil, err
+	}
+	payload := trackPayload{
+		Token:              publicKey,
+		Event:              e.Metric,
+		CustomerProperties: trimEmptyValues(c.withDefaultAttributes(person).GetMap(), c.TrimOptions),
+		Properties:         map[string]interface{}{},
+	}
+	for k, v := range e.Properties {
+		payload.Properties[k] = v
+	}
+	if e.Value != 0 {
+		payload.Properties["$value"] = e.Value
+	}
+	if e.UniqueId != "" {
+		payload.Properties["$event_id"] = e.UniqueId
+	}
+	if !e.Time.IsZero() {
+		payload.Time = e.Time.Unix()
+	}
+	u, err := newLegacyURL("track", &payload)
+	if err != nil {
+		return nil, err
+	}
+	c.regionURL(u)
+	return u, nil
+}
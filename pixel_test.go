@@ -0,0 +1,82 @@
+package klaviyo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func decodeTrackURL(t *testing.T, raw string) (url.Values, map[string]interface{}) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme+"://"+u.Host+u.Path != Endpoint+"/track" {
+		t.Errorf("Unexpected endpoint %s", raw)
+	}
+	xs, err := base64.StdEncoding.DecodeString(u.Query().Get("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(xs, &payload); err != nil {
+		t.Fatal(err)
+	}
+	return u.Query(), payload
+}
+
+func TestClient_TrackURL(t *testing.T) {
+	client := &Client{PublicKey: "public"}
+	e := &NewEvent{
+		Metric:     "Opened Newsletter",
+		Person:     &Person{Email: "kitty@monstercat.com"},
+		Properties: map[string]interface{}{"Issue": 12},
+		Time:       time.Unix(1600000000, 0),
+		UniqueId:   "open-1",
+	}
+	raw, err := client.TrackURL(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, payload := decodeTrackURL(t, raw)
+	if values.Get("i") != "" {
+		t.Error("Expected no pixel parameter")
+	}
+	if payload["token"] != "public" || payload["event"] != "Opened Newsletter" || payload["time"] != float64(1600000000) {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+	customer := payload["customer_properties"].(map[string]interface{})
+	if customer["$email"] != "kitty@monstercat.com" {
+		t.Errorf("Unexpected customer properties %v", customer)
+	}
+	if _, ok := customer["$first_name"]; ok {
+		t.Error("Expected empty values to be left out")
+	}
+	props := payload["properties"].(map[string]interface{})
+	if props["Issue"] != float64(12) || props["$event_id"] != "open-1" {
+		t.Errorf("Unexpected properties %v", props)
+	}
+	if _, ok := e.Properties["$event_id"]; ok {
+		t.Error("Expected the event properties not to be changed")
+	}
+
+	raw, err = client.TrackPixelURL(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values, _ := decodeTrackURL(t, raw); values.Get("i") != "1" {
+		t.Errorf("Expected the pixel parameter, got %s", raw)
+	}
+
+	if _, err := client.TrackURL(&NewEvent{Person: e.Person}); err != ErrNoMetricName {
+		t.Errorf("Expected ErrNoMetricName, got %v", err)
+	}
+	if _, err := client.TrackURL(&NewEvent{Metric: "Opened", Person: &Person{}}); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+	if _, err := (&Client{}).TrackURL(e); err != ErrNoPublicKey {
+		t.Errorf("Expected ErrNoPublicKey, got %v", err)
+	}
+}
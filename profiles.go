@@ -0,0 +1,258 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPersonNotFound = errors.New("person not found")
+)
+
+// https://developers.klaviyo.com/en/reference/get_profiles
+// GET https://a.klaviyo.com/api/profiles
+// Looks up the Klaviyo id of a person using their profile identifier. The custom id is the most specific so it is
+// used first, followed by email and then phone number. Returns ErrPersonNotFound if there is no match.
+func (c *Client) FindPersonId(p *Person) (string, error) {
+	var filter FilterBuilder
+	switch {
+	case strings.TrimSpace(p.CustomId) != "":
+		filter = Equals("external_id", p.CustomId)
+	case strings.TrimSpace(p.Email) != "":
+		filter = Equals("email", p.Email)
+	case strings.TrimSpace(p.PhoneNumber) != "":
+		filter = Equals("phone_number", p.PhoneNumber)
+	default:
+		return "", ErrNoProfileIdentifier
+	}
+	u := newEndpoint(Endpoint, "profiles")
+	q := &Query{
+		Filter: filter.String(),
+		Fields: map[string][]string{"profile": {"email"}},
+	}
+	q.apply(u)
+	var res struct {
+		Data []resourceIdentifier `json:"data"`
+	}
+	if err := c.sendV3(http.MethodGet, u, nil, &res); err != nil {
+		return "", err
+	}
+	if len(res.Data) == 0 {
+		return "", ErrPersonNotFound
+	}
+	return res.Data[0].Id, nil
+}
+
+// Updates the custom attributes of the person with the given email without having to fetch them first. The profile is
+// found with FindPersonId and updated right away like UpdatePerson. When there is no such profile yet it is created
+// through IdentifySafe instead, which Klaviyo processes asynchronously.
+func (c *Client) UpdatePersonByEmail(email string, attrs map[string]interface{}) error {
+	if strings.TrimSpace(email) == "" {
+		return ErrNoProfileIdentifier
+	}
+	person := &Person{Email: email, Attributes: Attributes(attrs)}
+	id, err := c.FindPersonId(person)
+	if err == ErrPersonNotFound {
+		return c.IdentifySafe(person, true)
+	}
+	if err != nil {
+		return err
+	}
+	p, err := c.checkAttributes(person)
+	if err != nil {
+		return err
+	}
+	// Only the attributes, GetMap would also send every empty special field and blank them on the profile.
+	return c.updatePerson(id, map[string]interface{}(p.Attributes), nil, person)
+}
+
+// https://developers.klaviyo.com/en/reference/get_profile
+// GET https://a.klaviyo.com/api/profiles/profile_id?fields[profile]=properties
+// Returns only the named custom attributes of a person, for hot paths which need a single flag and not the whole
+// profile GetPerson returns. Klaviyo is asked for the custom properties alone through a sparse fieldset. Names the
+// person has no value for are left out of the result.
+func (c *Client) GetPersonAttributesOnly(personId string, names ...string) (Attributes, error) {
+	u := newEndpoint(Endpoint, fmt.Sprintf("profiles/%s", personId))
+	q := &Query{Fields: map[string][]string{"profile": {"properties"}}}
+	q.apply(u)
+	var res struct {
+		Data struct {
+			Attributes struct {
+				Properties Attributes `json:"properties"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.sendV3(http.MethodGet, u, nil, &res); err != nil {
+		return nil, err
+	}
+	attrs := Attributes{}
+	for _, name := range names {
+		if v, ok := res.Data.Attributes.Properties[name]; ok {
+			attrs[name] = v
+		}
+	}
+	return attrs, nil
+}
+
+// A profile returned by the v3 profiles endpoints, the person along with when Klaviyo created and last updated it.
+type Profile struct {
+	Person
+
+	Created KTime
+	Updated KTime
+
+	// Attributes Klaviyo returned which the SDK has no field for yet, e.g. subscriptions when requested through
+	// additional fields. The custom properties are in Attributes.
+	Extra Attributes
+}
+
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			Email        string `json:"email"`
+			PhoneNumber  string `json:"phone_number"`
+			ExternalId   string `json:"external_id"`
+			FirstName    string `json:"first_name"`
+			LastName     string `json:"last_name"`
+			Organization string `json:"organization"`
+			Title        string `json:"title"`
+			Image        string `json:"image"`
+			Created      KTime  `json:"created"`
+			Updated      KTime  `json:"updated"`
+			Location     struct {
+				Address1  string `json:"address1"`
+				Address2  string `json:"address2"`
+				City      string `json:"city"`
+				Country   string `json:"country"`
+				Region    string `json:"region"`
+				Zip       string `json:"zip"`
+				Timezone  string `json:"timezone"`
+				Latitude  KFloat `json:"latitude"`
+				Longitude KFloat `json:"longitude"`
+			} `json:"location"`
+			Properties Attributes `json:"properties"`
+		} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	extra, err := extraAttributes(data, &res.Attributes)
+	if err != nil {
+		return err
+	}
+	attrs, loc := res.Attributes, res.Attributes.Location
+	if attrs.Properties == nil {
+		attrs.Properties = Attributes{}
+	}
+	*p = Profile{
+		Person: Person{
+			Object:       Object{Id: res.Id, Object: "profile"},
+			CustomId:     attrs.ExternalId,
+			Email:        attrs.Email,
+			PhoneNumber:  attrs.PhoneNumber,
+			FirstName:    attrs.FirstName,
+			LastName:     attrs.LastName,
+			Organization: attrs.Organization,
+			Title:        attrs.Title,
+			Image:        attrs.Image,
+			Address1:     loc.Address1,
+			Address2:     loc.Address2,
+			City:         loc.City,
+			Country:      loc.Country,
+			Region:       loc.Region,
+			Zip:          loc.Zip,
+			Timezone:     loc.Timezone,
+			Latitude:     loc.Latitude,
+			Longitude:    loc.Longitude,
+			Attributes:   attrs.Properties,
+		},
+		Created: attrs.Created,
+		Updated: attrs.Updated,
+		Extra:   extra,
+	}
+	return nil
+}
+
+type ProfilePage struct {
+	Data  []Profile `json:"data"`
+	Links Links     `json:"links"`
+}
+
+// https://developers.klaviyo.com/en/reference/get_profiles
+// GET https://a.klaviyo.com/api/profiles
+// Returns a page of profiles, use Links.NextCursor() to get the next one. Profiles can be filtered and sorted on their
+// identifiers, created and updated, e.g. GreaterThan("updated", since) sorted by "updated" to go through the profiles
+// changed since a point in time.
+func (c *Client) GetProfiles(q *Query) (*ProfilePage, error) {
+	u := newEndpoint(Endpoint, "profiles")
+	q.apply(u)
+	var res ProfilePage
+	err := c.sendV3(http.MethodGet, u, nil, &res)
+	return &res, err
+}
+
+// Calls fn for every profile matching q, going through all the pages of GetProfiles starting at q.Cursor. Returning
+// an error from fn stops the iteration and the error is returned. q is not changed.
+func (c *Client) EachProfile(q *Query, fn func(*Profile) error) error {
+	var cursor Cursor
+	if q != nil {
+		cursor.Next = q.Cursor
+	}
+	return c.EachProfileFrom(q, &cursor, fn)
+}
+
+// Same as EachProfile starting from cursor.Next instead of q.Cursor, cursor is kept up to date so the iteration can be
+// resumed. Resume with the same q, the cursor is only valid for the same filter and sort.
+func (c *Client) EachProfileFrom(q *Query, cursor *Cursor, fn func(*Profile) error) error {
+	var page Query
+	if q != nil {
+		page = *q
+	}
+	for !cursor.Done {
+		page.Cursor = cursor.Next
+		res, err := c.GetProfiles(&page)
+		if err != nil {
+			return err
+		}
+		for i := range res.Data {
+			if err := fn(&res.Data[i]); err != nil {
+				return err
+			}
+		}
+		if cursor.Next = res.Links.NextCursor(); cursor.Next == "" {
+			cursor.Done = true
+		}
+	}
+	return nil
+}
+
+// Calls fn for every profile updated at or after since, oldest first, and returns the checkpoint to pass as since on
+// the next sync: the last update time seen, or since when nothing changed. When fn or a request fails the checkpoint
+// of the profiles handled so far is returned along with the error, so the next sync picks up where this one stopped.
+//
+// Klaviyo only filters updated with greater-than and to the second, so a plain greater-than the checkpoint would miss
+// profiles updated later in the same second as the last one handled. The filter starts a second before since
+// instead, which means profiles updated in that second are seen again by the next sync. fn should not mind being
+// called twice for the same change.
+func (c *Client) SyncChangedProfiles(since time.Time, fn func(*Profile) error) (time.Time, error) {
+	checkpoint := since
+	q := &Query{
+		Filter: GreaterThan("updated", since.Add(-time.Second)).String(),
+		Sort:   "updated",
+	}
+	err := c.EachProfile(q, func(p *Profile) error {
+		if err := fn(p); err != nil {
+			return err
+		}
+		if p.Updated.After(checkpoint) {
+			checkpoint = p.Updated.Time
+		}
+		return nil
+	})
+	return checkpoint, err
+}
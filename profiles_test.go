@@ -0,0 +1,198 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_FindPersonId(t *testing.T) {
+	var filter string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("filter")
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		if filter == `equals(email,"nobody@monstercat.com")` {
+			w.Write([]byte(`{"data": []}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"type": "profile", "id": "01GDDKASAP8TKDDA2GRZDSVP4H"}]}`))
+	})
+
+	p := newTestPerson()
+	p.CustomId = "user-1"
+	id, err := client.FindPersonId(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "01GDDKASAP8TKDDA2GRZDSVP4H" {
+		t.Errorf("Unexpected id %s", id)
+	}
+	if filter != `equals(external_id,"user-1")` {
+		t.Errorf("Expected the custom id to be used first, got %s", filter)
+	}
+
+	p = Person{Email: "nobody@monstercat.com"}
+	if _, err := client.FindPersonId(&p); err != ErrPersonNotFound {
+		t.Errorf("Expected ErrPersonNotFound, got %v", err)
+	}
+	if _, err := client.FindPersonId(&Person{}); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+}
+
+func TestClient_UpdatePersonByEmail(t *testing.T) {
+	var updated, identified bool
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/profiles":
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			if r.URL.Query().Get("filter") == `equals(email,"new@monstercat.com")` {
+				w.Write([]byte(`{"data": []}`))
+				return
+			}
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE1"}]}`))
+		case "/api/v1/person/PROFILE1":
+			updated = true
+			if r.Method != http.MethodPut {
+				t.Errorf("Expected PUT, got %s", r.Method)
+			}
+			if v := r.URL.Query().Get("LikesGold"); v != "true" {
+				t.Errorf("Expected LikesGold to be sent, got %q", v)
+			}
+			for k := range r.URL.Query() {
+				if strings.HasPrefix(k, "$") {
+					t.Errorf("Expected only the attributes to be sent, got %s", k)
+				}
+			}
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1"}`))
+		case "/api/identify":
+			identified = true
+			w.Header().Set("Content-Type", ContentHTML)
+			w.Write([]byte("1"))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	attrs := map[string]interface{}{"LikesGold": true}
+	if err := client.UpdatePersonByEmail("kitty@monstercat.com", attrs); err != nil {
+		t.Fatal(err)
+	}
+	if !updated || identified {
+		t.Error("Expected the existing profile to be updated")
+	}
+
+	updated = false
+	if err := client.UpdatePersonByEmail("new@monstercat.com", attrs); err != nil {
+		t.Fatal(err)
+	}
+	if updated || !identified {
+		t.Error("Expected the missing profile to be identified")
+	}
+
+	if err := client.UpdatePersonByEmail(" ", attrs); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+	if err := client.UpdatePersonByEmail("kitty@monstercat.com", map[string]interface{}{"$bad": 1}); err == nil {
+		t.Error("Expected invalid attributes to be rejected")
+	}
+}
+
+func TestClient_GetPersonAttributesOnly(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/profiles/PROFILE1" || r.URL.Query().Get("fields[profile]") != "properties" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": {"type": "profile", "id": "PROFILE1", "attributes": {"properties": {"LikesGold": true, "Tier": "gold"}}}}`))
+	})
+	attrs, err := client.GetPersonAttributesOnly("PROFILE1", "LikesGold", "Missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 1 || !attrs.ParseBool("LikesGold") {
+		t.Errorf("Unexpected attributes %v", attrs)
+	}
+}
+
+func TestClient_EachProfile(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var cursors []string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/profiles" || q.Get("filter") != `greater-than(updated,2024-01-01T00:00:00Z)` || q.Get("sort") != "updated" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		cursors = append(cursors, q.Get("page[cursor]"))
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		if q.Get("page[cursor]") == "" {
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE1", "attributes": {"email": "kitty@monstercat.com", "external_id": "user-1", "location": {"city": "Vancouver", "latitude": "49.28"}, "properties": {"LikesGold": true}, "updated": "2024-02-01T00:00:00+00:00", "subscriptions": {}}}], "links": {"next": "https://a.klaviyo.com/api/profiles?page%5Bcursor%5D=NEXT"}}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE2", "attributes": {"phone_number": "+15555555555"}}], "links": {}}`))
+	})
+
+	q := &Query{Filter: GreaterThan("updated", since).String(), Sort: "updated"}
+	var profiles []Profile
+	err := client.EachProfile(q, func(p *Profile) error {
+		profiles = append(profiles, *p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || len(cursors) != 2 || cursors[1] != "NEXT" || q.Cursor != "" {
+		t.Fatalf("Unexpected profiles %+v after cursors %v", profiles, cursors)
+	}
+	p := profiles[0]
+	if p.Id != "PROFILE1" || p.Email != "kitty@monstercat.com" || p.CustomId != "user-1" || p.City != "Vancouver" || p.Latitude != 49.28 {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+	if !p.Attributes.ParseBool("LikesGold") || !p.Updated.After(since) || p.Extra["subscriptions"] == nil {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+	if profiles[1].PhoneNumber != "+15555555555" || profiles[1].Attributes == nil {
+		t.Errorf("Unexpected profile %+v", profiles[1])
+	}
+}
+
+func TestClient_SyncChangedProfiles(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("filter") != `greater-than(updated,2023-12-31T23:59:59Z)` || q.Get("sort") != "updated" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": [
+			{"type": "profile", "id": "PROFILE1", "attributes": {"updated": "2024-02-01T00:00:00+00:00"}},
+			{"type": "profile", "id": "PROFILE2", "attributes": {"updated": "2024-03-01T00:00:00+00:00"}}
+		], "links": {}}`))
+	})
+
+	var ids []string
+	next, err := client.SyncChangedProfiles(since, func(p *Profile) error {
+		ids = append(ids, p.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || !next.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected sync of %v up to %s", ids, next)
+	}
+
+	failed := errors.New("crm is down")
+	next, err = client.SyncChangedProfiles(since, func(p *Profile) error {
+		if p.Id == "PROFILE2" {
+			return failed
+		}
+		return nil
+	})
+	if err != failed || !next.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the checkpoint of the handled profiles, got %s and %v", next, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package prommetrics exposes the request metrics of a klaviyo.Client in the Prometheus text format, so Klaviyo error
+// rates and latency can be scraped and alerted on per operation without pulling the Prometheus client library into
+// the SDK:
+//
+//	metrics := prommetrics.New("klaviyo")
+//	client := &klaviyo.Client{PrivateKey: key, RequestMetrics: metrics}
+//	http.Handle("/metrics/klaviyo", metrics)
+//
+// Two metrics are exported: <namespace>_requests_total, a counter labelled by operation and outcome, and
+// <namespace>_request_duration_seconds, a histogram labelled by operation.
+package prommetrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/monstercat/go-klaviyo"
+)
+
+// The content type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Latency buckets in seconds, the same as the Prometheus client's defaults.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector is a klaviyo.RequestMetrics which keeps its counters in memory and serves them over HTTP in the
+// Prometheus text format. Safe for concurrent use.
+type Collector struct {
+	namespace string
+	buckets   []float64
+
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[string]*histogram
+}
+
+type requestKey struct {
+	operation string
+	outcome   klaviyo.Outcome
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+var _ klaviyo.RequestMetrics = (*Collector)(nil)
+
+// Returns a Collector naming its metrics after namespace, e.g. "klaviyo", using DefaultBuckets.
+func New(namespace string) *Collector {
+	return NewWithBuckets(namespace, DefaultBuckets)
+}
+
+// Same as New with custom latency buckets, upper bounds in seconds in increasing order.
+func NewWithBuckets(namespace string, buckets []float64) *Collector {
+	return &Collector{
+		namespace: namespace,
+		buckets:   append([]float64(nil), buckets...),
+		requests:  map[requestKey]uint64{},
+		durations: map[string]*histogram{},
+	}
+}
+
+func (c *Collector) Increment(operation string, outcome klaviyo.Outcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[requestKey{operation, outcome}]++
+}
+
+func (c *Collector) Observe(operation string, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.durations[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[operation] = h
+	}
+	seconds := latency.Seconds()
+	for i, le := range c.buckets {
+		if seconds <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Writes every metric in the Prometheus text format, sorted so the output is stable.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sb strings.Builder
+
+	name := c.metricName("requests_total")
+	fmt.Fprintf(&sb, "# HELP %s Calls to the Klaviyo API by operation and outcome.\n", name)
+	fmt.Fprintf(&sb, "# TYPE %s counter\n", name)
+	keys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s{operation=%s,outcome=%s} %d\n", name, quote(k.operation), quote(string(k.outcome)), c.requests[k])
+	}
+
+	name = c.metricName("request_duration_seconds")
+	fmt.Fprintf(&sb, "# HELP %s Latency of calls to the Klaviyo API by operation, including retries.\n", name)
+	fmt.Fprintf(&sb, "# TYPE %s histogram\n", name)
+	ops := make([]string, 0, len(c.durations))
+	for op := range c.durations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		h := c.durations[op]
+		var cumulative uint64
+		for i, le := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&sb, "%s_bucket{operation=%s,le=%q} %d\n", name, quote(op), formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(&sb, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(op), h.count)
+		fmt.Fprintf(&sb, "%s_sum{operation=%s} %s\n", name, quote(op), formatFloat(h.sum))
+		fmt.Fprintf(&sb, "%s_count{operation=%s} %d\n", name, quote(op), h.count)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	c.WriteTo(w)
+}
+
+func (c *Collector) metricName(name string) string {
+	if c.namespace == "" {
+		return name
+	}
+	return c.namespace + "_" + name
+}
+
+// Label values are quoted with backslashes, double quotes and line feeds escaped.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,50 @@
+package prommetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monstercat/go-klaviyo"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewWithBuckets("klaviyo", []float64{0.1, 1})
+	c.Increment("GET /api/v2/list/:id", klaviyo.OutcomeSuccess)
+	c.Increment("GET /api/v2/list/:id", klaviyo.OutcomeSuccess)
+	c.Increment("GET /api/v2/list/:id", klaviyo.OutcomeServerError)
+	c.Observe("GET /api/v2/list/:id", 50*time.Millisecond)
+	c.Observe("GET /api/v2/list/:id", 500*time.Millisecond)
+	c.Observe("GET /api/v2/list/:id", 5*time.Second)
+
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Unexpected content type %s", w.Header().Get("Content-Type"))
+	}
+	expected := []string{
+		`# TYPE klaviyo_requests_total counter`,
+		`klaviyo_requests_total{operation="GET /api/v2/list/:id",outcome="server_error"} 1`,
+		`klaviyo_requests_total{operation="GET /api/v2/list/:id",outcome="success"} 2`,
+		`# TYPE klaviyo_request_duration_seconds histogram`,
+		`klaviyo_request_duration_seconds_bucket{operation="GET /api/v2/list/:id",le="0.1"} 1`,
+		`klaviyo_request_duration_seconds_bucket{operation="GET /api/v2/list/:id",le="1"} 2`,
+		`klaviyo_request_duration_seconds_bucket{operation="GET /api/v2/list/:id",le="+Inf"} 3`,
+		`klaviyo_request_duration_seconds_sum{operation="GET /api/v2/list/:id"} 5.55`,
+		`klaviyo_request_duration_seconds_count{operation="GET /api/v2/list/:id"} 3`,
+	}
+	body := w.Body.String()
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Missing %s in:\n%s", line, body)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote("a\"b\\c\nd"); got != `"a\"b\\c\nd"` {
+		t.Errorf("Unexpected quoting %s", got)
+	}
+}
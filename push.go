@@ -0,0 +1,98 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Unregistering push tokens was added after APIRevision.
+const pushTokensRevision = "2025-01-15"
+
+type PushPlatform string
+
+const (
+	PushPlatformIOS     PushPlatform = "ios"
+	PushPlatformAndroid PushPlatform = "android"
+)
+
+var (
+	ErrNoPushToken         = errors.New("missing push token")
+	ErrUnknownPushPlatform = errors.New("unknown push platform")
+
+	// Klaviyo sends to iOS through APNs and to Android through FCM.
+	pushVendors = map[PushPlatform]string{
+		PushPlatformIOS:     "apns",
+		PushPlatformAndroid: "fcm",
+	}
+)
+
+func pushTokenAttributes(person *Person, token string, platform PushPlatform) (map[string]interface{}, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, ErrNoPushToken
+	}
+	vendor, ok := pushVendors[platform]
+	if !ok {
+		return nil, ErrUnknownPushPlatform
+	}
+	if !person.HasProfileIdentifier() {
+		return nil, ErrNoProfileIdentifier
+	}
+	return map[string]interface{}{
+		"token":    token,
+		"platform": platform,
+		"vendor":   vendor,
+		"profile": map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":       "profile",
+				"attributes": person.profileAttributes(),
+			},
+		},
+	}, nil
+}
+
+// https://developers.klaviyo.com/en/reference/create_client_push_token
+// POST https://a.klaviyo.com/client/push-tokens/
+// Registers a device's push token for person so they can be sent push notifications, the profile is created if it
+// does not exist. Only needs the public key. Use UnregisterPushToken when the app is uninstalled or notifications are
+// turned off.
+func (c *Client) RegisterPushToken(person *Person, token string, platform PushPlatform) error {
+	attrs, err := pushTokenAttributes(person, token, platform)
+	if err != nil {
+		return err
+	}
+	attrs["enablement_status"] = "AUTHORIZED"
+	attrs["background"] = "AVAILABLE"
+	u, err := c.clientSideEndpoint("push-tokens")
+	if err != nil {
+		return err
+	}
+	doc := &document{
+		Data: map[string]interface{}{
+			"type":       "push-token",
+			"attributes": attrs,
+		},
+	}
+	return c.sendV3Revision(pushTokensRevision, http.MethodPost, u, doc, nil)
+}
+
+// https://developers.klaviyo.com/en/reference/unregister_client_push_token
+// POST https://a.klaviyo.com/client/push-token-unregister/
+// Stops sending push notifications to the token. Tokens can also be removed from a list with Unsubscribe.
+func (c *Client) UnregisterPushToken(person *Person, token string, platform PushPlatform) error {
+	attrs, err := pushTokenAttributes(person, token, platform)
+	if err != nil {
+		return err
+	}
+	u, err := c.clientSideEndpoint("push-token-unregister")
+	if err != nil {
+		return err
+	}
+	doc := &document{
+		Data: map[string]interface{}{
+			"type":       "push-token-unregister",
+			"attributes": attrs,
+		},
+	}
+	return c.sendV3Revision(pushTokensRevision, http.MethodPost, u, doc, nil)
+}
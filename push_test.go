@@ -0,0 +1,51 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_RegisterPushToken(t *testing.T) {
+	var paths []string
+	var bodies []map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("Revision") != pushTokensRevision {
+			t.Errorf("Unexpected revision %s", r.Header.Get("Revision"))
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body["data"].(map[string]interface{}))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	p := &Person{Email: "kitty@monstercat.com"}
+	if err := client.RegisterPushToken(p, "TOKEN", PushPlatformIOS); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UnregisterPushToken(p, "TOKEN", PushPlatformAndroid); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "/client/push-tokens/" || paths[1] != "/client/push-token-unregister/" {
+		t.Fatalf("Unexpected requests %v", paths)
+	}
+	attrs := bodies[0]["attributes"].(map[string]interface{})
+	if bodies[0]["type"] != "push-token" || attrs["vendor"] != "apns" || attrs["enablement_status"] != "AUTHORIZED" {
+		t.Errorf("Unexpected register body %v", bodies[0])
+	}
+	attrs = bodies[1]["attributes"].(map[string]interface{})
+	if bodies[1]["type"] != "push-token-unregister" || attrs["vendor"] != "fcm" || attrs["token"] != "TOKEN" {
+		t.Errorf("Unexpected unregister body %v", bodies[1])
+	}
+
+	if err := client.RegisterPushToken(p, "", PushPlatformIOS); err != ErrNoPushToken {
+		t.Errorf("Expected ErrNoPushToken, got %v", err)
+	}
+	if err := client.RegisterPushToken(p, "TOKEN", "windows"); err != ErrUnknownPushPlatform {
+		t.Errorf("Expected ErrUnknownPushPlatform, got %v", err)
+	}
+	if err := client.RegisterPushToken(&Person{}, "TOKEN", PushPlatformIOS); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+}
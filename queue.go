@@ -0,0 +1,413 @@
+package klaviyo
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	QueueIdentify = "identify"
+	QueueEvent    = "event"
+
+	defaultQueueInterval  = 10 * time.Second
+	defaultQueueBatchSize = 100
+
+	// Longest line FileQueueStore will read back.
+	maxQueuedRequestSize = 5 * 1024 * 1024
+)
+
+var (
+	ErrUnknownQueueKind = errors.New("unknown queued request kind")
+	ErrQueueStarted     = errors.New("queue is already started")
+)
+
+// A call waiting to be sent to Klaviyo. Only one of Person and Event is set depending on Kind.
+type QueuedRequest struct {
+	Id       string
+	Kind     string
+	Person   *Person   `json:",omitempty"`
+	Event    *NewEvent `json:",omitempty"`
+	Enqueued time.Time
+}
+
+// Person marshals through GetMap which leaves out the profile id, so it is kept next to the request to survive
+// FileQueueStore.
+type queuedRequestJSON struct {
+	queuedRequest
+	PersonId      string `json:",omitempty"`
+	EventPersonId string `json:",omitempty"`
+}
+
+type queuedRequest QueuedRequest
+
+func (r QueuedRequest) MarshalJSON() ([]byte, error) {
+	res := queuedRequestJSON{queuedRequest: queuedRequest(r)}
+	if r.Person != nil {
+		res.PersonId = r.Person.Id
+	}
+	if r.Event != nil && r.Event.Person != nil {
+		res.EventPersonId = r.Event.Person.Id
+	}
+	return json.Marshal(res)
+}
+
+func (r *QueuedRequest) UnmarshalJSON(data []byte) error {
+	var res queuedRequestJSON
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*r = QueuedRequest(res.queuedRequest)
+	if r.Person != nil && res.PersonId != "" {
+		r.Person.Id = res.PersonId
+	}
+	if r.Event != nil && r.Event.Person != nil && res.EventPersonId != "" {
+		r.Event.Person.Id = res.EventPersonId
+	}
+	return nil
+}
+
+// QueueStore keeps queued requests until they were sent. Implementations must be safe for concurrent use and return
+// requests in the order they were pushed.
+type QueueStore interface {
+	Push(r QueuedRequest) error
+
+	// Returns up to n of the oldest requests without removing them.
+	Peek(n int) ([]QueuedRequest, error)
+
+	// Removes the requests with the given ids, unknown ids are ignored.
+	Remove(ids ...string) error
+
+	Len() (int, error)
+}
+
+// RequestQueue sends Identify and CreateEvent calls in the background so callers are not affected by Klaviyo outages.
+// Requests are only removed from the Store once Klaviyo accepted them or rejected them for good, so with a durable
+// store like FileQueueStore nothing is lost when the process restarts.
+//
+//	q := &klaviyo.RequestQueue{Client: client, Store: store}
+//	q.Start()
+//	defer q.Stop()
+//	err := q.Identify(&person)
+type RequestQueue struct {
+	Client *Client
+	Store  QueueStore
+
+	// How often the store is flushed, defaults to 10 seconds.
+	Interval time.Duration
+
+	// How many requests are sent per flush at most, defaults to 100.
+	BatchSize int
+
+	// Called with requests Klaviyo rejected for good, e.g. because of a validation error. They are removed from the
+	// store after this returns.
+	OnDrop func(r QueuedRequest, err error)
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+
+	// Held while flushing so a manual Flush and the background one do not send the same requests twice.
+	flushMu sync.Mutex
+}
+
+// Queues the person to be identified. The person is checked right away so invalid people are not queued.
+func (q *RequestQueue) Identify(person *Person) error {
+	if !person.HasProfileIdentifier() {
+		return ErrNoProfileIdentifier
+	}
+	p, err := q.Client.checkAttributes(person)
+	if err != nil {
+		return err
+	}
+	// The memory store keeps the pointer, the caller may change the person before it is sent.
+	return q.push(QueuedRequest{Kind: QueueIdentify, Person: copyPerson(p)})
+}
+
+// Queues the event to be created. The event time is set to now when empty so it is not changed by the delay.
+func (q *RequestQueue) Track(e *NewEvent) error {
+	if e.Metric == "" {
+		return ErrNoMetricName
+	}
+	if e.Person == nil || !e.Person.HasProfileIdentifier() {
+		return ErrNoProfileIdentifier
+	}
+	ev := copyEvent(e)
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	return q.push(QueuedRequest{Kind: QueueEvent, Event: ev})
+}
+
+func (q *RequestQueue) push(r QueuedRequest) error {
+	id, err := newQueueId()
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	r.Enqueued = time.Now()
+	return q.Store.Push(r)
+}
+
+// Sends everything in the store. Stops at the first request which failed because of a temporary problem such as a
+// server error or rate limit and returns that error, the request stays in the store to be tried again later.
+func (q *RequestQueue) Flush() error {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+	batchSize := q.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultQueueBatchSize
+	}
+	for {
+		reqs, err := q.Store.Peek(batchSize)
+		if err != nil || len(reqs) == 0 {
+			return err
+		}
+		// Removed once per batch, the file store rewrites the whole file every time.
+		ids := make([]string, 0, len(reqs))
+		for _, r := range reqs {
+			err := q.send(r)
+			if err != nil && isTemporaryError(err) {
+				if len(ids) > 0 {
+					if rmErr := q.Store.Remove(ids...); rmErr != nil {
+						return rmErr
+					}
+				}
+				return err
+			}
+			if err != nil && q.OnDrop != nil {
+				q.OnDrop(r, err)
+			}
+			ids = append(ids, r.Id)
+		}
+		if err := q.Store.Remove(ids...); err != nil {
+			return err
+		}
+	}
+}
+
+func (q *RequestQueue) send(r QueuedRequest) error {
+	switch r.Kind {
+	case QueueIdentify:
+		return q.Client.Identify(r.Person)
+	case QueueEvent:
+		return q.Client.CreateEvent(r.Event)
+	}
+	return ErrUnknownQueueKind
+}
+
+// Flushes the store every Interval in a background goroutine until Stop is called. Errors are left for the next
+// flush to retry.
+func (q *RequestQueue) Start() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stop != nil {
+		return ErrQueueStarted
+	}
+	interval := q.Interval
+	if interval <= 0 {
+		interval = defaultQueueInterval
+	}
+	q.stop = make(chan struct{})
+	q.done = make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				q.Flush()
+			}
+		}
+	}(q.stop, q.done)
+	return nil
+}
+
+// Stops the background flushing and makes a last attempt to send everything.
+func (q *RequestQueue) Stop() error {
+	q.mu.Lock()
+	stop, done := q.stop, q.done
+	q.stop, q.done = nil, nil
+	q.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return q.Flush()
+}
+
+// Errors which are likely to go away when trying again later.
+func isTemporaryError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.Is(err, ErrCircuitOpen) || errors.As(err, &netErr)
+}
+
+func newQueueId() (string, error) {
+	xs := make([]byte, 16)
+	if _, err := rand.Read(xs); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(xs), nil
+}
+
+// Keeps queued requests in memory, they are lost when the process exits.
+type MemoryQueueStore struct {
+	mu   sync.Mutex
+	reqs []QueuedRequest
+}
+
+func (s *MemoryQueueStore) Push(r QueuedRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, r)
+	return nil
+}
+
+func (s *MemoryQueueStore) Peek(n int) ([]QueuedRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > len(s.reqs) {
+		n = len(s.reqs)
+	}
+	res := make([]QueuedRequest, n)
+	copy(res, s.reqs)
+	return res, nil
+}
+
+func (s *MemoryQueueStore) Remove(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(ids)
+	return nil
+}
+
+func (s *MemoryQueueStore) remove(ids []string) {
+	drop := map[string]bool{}
+	for _, id := range ids {
+		drop[id] = true
+	}
+	kept := s.reqs[:0]
+	for _, r := range s.reqs {
+		if !drop[r.Id] {
+			kept = append(kept, r)
+		}
+	}
+	s.reqs = kept
+}
+
+func (s *MemoryQueueStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.reqs), nil
+}
+
+// Keeps queued requests in a file as one JSON object per line so they survive restarts. Pushes are appended and
+// synced to disk, removals rewrite the file.
+type FileQueueStore struct {
+	filename string
+	mem      MemoryQueueStore
+}
+
+// Opens the store, loading any requests left in the file. The file is created if it does not exist.
+func OpenFileQueueStore(filename string) (*FileQueueStore, error) {
+	s := &FileQueueStore{filename: filename}
+	f, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxQueuedRequestSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r QueuedRequest
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, err
+		}
+		s.mem.reqs = append(s.mem.reqs, r)
+	}
+	return s, scanner.Err()
+}
+
+func (s *FileQueueStore) Push(r QueuedRequest) error {
+	xs, err := json.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	f, err := os.OpenFile(s.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(xs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.mem.reqs = append(s.mem.reqs, r)
+	return nil
+}
+
+func (s *FileQueueStore) Peek(n int) ([]QueuedRequest, error) {
+	return s.mem.Peek(n)
+}
+
+func (s *FileQueueStore) Remove(ids ...string) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	s.mem.remove(ids)
+
+	// Write to a temporary file first so a crash cannot leave a half written queue behind.
+	tmp := s.filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range s.mem.reqs {
+		if err := enc.Encode(&s.mem.reqs[i]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.filename)
+}
+
+func (s *FileQueueStore) Len() (int, error) {
+	return s.mem.Len()
+}
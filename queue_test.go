@@ -0,0 +1,204 @@
+package klaviyo
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFileQueueStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "queue.ndjson")
+	s, err := OpenFileQueueStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []QueuedRequest{
+		{Id: "1", Kind: QueueIdentify, Person: &Person{Object: Object{Id: "PROFILE1"}, Email: "kitty@monstercat.com", Attributes: Attributes{"Plan": "gold"}}},
+		{Id: "2", Kind: QueueEvent, Event: &NewEvent{Metric: "Placed Order", Person: &Person{Email: "kitty@monstercat.com"}, Value: 9.99}},
+		{Id: "3", Kind: QueueIdentify, Person: &Person{PhoneNumber: "+1234567890"}},
+		{Id: "4", Kind: QueueEvent, Event: &NewEvent{Metric: "Placed Order", Person: &Person{Object: Object{Id: "PROFILE2"}}}},
+	}
+	for _, r := range reqs {
+		if err := s.Push(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Remove("2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening must give back what was left.
+	s, err = OpenFileQueueStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, err := s.Peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 3 || left[0].Id != "1" || left[1].Id != "3" {
+		t.Fatalf("Unexpected requests %+v", left)
+	}
+	if left[0].Person.Email != "kitty@monstercat.com" || left[0].Person.Attributes["Plan"] != "gold" {
+		t.Errorf("Person did not survive the round trip %+v", left[0].Person)
+	}
+	if left[0].Person.Id != "PROFILE1" || left[1].Person.Id != "" || left[2].Event.Person.Id != "PROFILE2" {
+		t.Errorf("Expected the profile ids to be kept, got %q, %q and %q", left[0].Person.Id, left[1].Person.Id, left[2].Event.Person.Id)
+	}
+	if n, _ := s.Len(); n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+}
+
+func TestRequestQueue_Flush(t *testing.T) {
+	var fail bool
+	var events int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case fail:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/api/events":
+			events++
+			if events == 1 {
+				// Rejected for good, should be dropped.
+				w.Header().Set("Content-Type", ContentJSONAPI)
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors": [{"status": 400, "detail": "bad event"}]}`))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("1"))
+		}
+	})
+	var dropped []QueuedRequest
+	q := &RequestQueue{
+		Client: client,
+		Store:  &MemoryQueueStore{},
+		OnDrop: func(r QueuedRequest, err error) {
+			dropped = append(dropped, r)
+		},
+	}
+	if err := q.Identify(&Person{}); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+	person := &Person{Email: "kitty@monstercat.com"}
+	if err := q.Identify(person); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := q.Track(&NewEvent{Metric: "Placed Order", Person: person}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fail = true
+	if err := q.Flush(); !isTemporaryError(err) {
+		t.Errorf("Expected a temporary error, got %v", err)
+	}
+	if n, _ := q.Store.Len(); n != 3 {
+		t.Fatalf("Expected everything to stay queued, got %d", n)
+	}
+
+	fail = false
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.Store.Len(); n != 0 {
+		t.Errorf("Expected an empty queue, got %d", n)
+	}
+	if len(dropped) != 1 || dropped[0].Kind != QueueEvent || dropped[0].Event.Time.IsZero() {
+		t.Errorf("Unexpected dropped requests %+v", dropped)
+	}
+}
+
+// Counts the calls to Remove.
+type countingQueueStore struct {
+	MemoryQueueStore
+	removes [][]string
+}
+
+func (s *countingQueueStore) Remove(ids ...string) error {
+	s.removes = append(s.removes, ids)
+	return s.MemoryQueueStore.Remove(ids...)
+}
+
+func TestRequestQueue_FlushBatches(t *testing.T) {
+	var identifies int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		identifies++
+		if identifies == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("1"))
+	})
+	store := &countingQueueStore{}
+	q := &RequestQueue{Client: client, Store: store}
+	person := &Person{Email: "kitty@monstercat.com", Attributes: Attributes{"plan": "gold"}}
+	for i := 0; i < 3; i++ {
+		if err := q.Identify(person); err != nil {
+			t.Fatal(err)
+		}
+	}
+	event := &NewEvent{Metric: "Placed Order", Person: person}
+	if err := q.Track(event); err != nil {
+		t.Fatal(err)
+	}
+	person.Email = "changed@monstercat.com"
+	person.Attributes["plan"] = "silver"
+	queued, _ := store.Peek(4)
+	if queued[0].Person.Email != "kitty@monstercat.com" || queued[0].Person.Attributes["plan"] != "gold" ||
+		queued[3].Event.Person.Email != "kitty@monstercat.com" {
+		t.Errorf("Expected queued people to be copied, got %+v and %+v", queued[0].Person, queued[3].Event.Person)
+	}
+
+	// The first request went through before the second failed, it is removed before returning.
+	if err := q.Flush(); !isTemporaryError(err) {
+		t.Errorf("Expected a temporary error, got %v", err)
+	}
+	if len(store.removes) != 1 || len(store.removes[0]) != 1 {
+		t.Fatalf("Expected the sent request to be removed, got %v", store.removes)
+	}
+
+	store.removes = nil
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.removes) != 1 || len(store.removes[0]) != 3 {
+		t.Errorf("Expected one Remove for the batch, got %v", store.removes)
+	}
+}
+
+func TestRequestQueue_FlushConcurrently(t *testing.T) {
+	var hits int32
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", ContentHTML)
+		w.Write([]byte("1"))
+	})
+	q := &RequestQueue{Client: client, Store: &MemoryQueueStore{}}
+	for i := 0; i < 5; i++ {
+		if err := q.Identify(&Person{Email: "kitty@monstercat.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := q.Flush(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&hits); n != 5 {
+		t.Errorf("Expected every request to be sent once, got %d requests", n)
+	}
+}
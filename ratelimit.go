@@ -0,0 +1,83 @@
+package klaviyo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is parsed from the rate limit headers Klaviyo sends with each response. Fields are 0 when the header was
+// not sent, which is the case for most legacy endpoints.
+type RateLimit struct {
+	// Requests allowed in the current window.
+	Limit int
+
+	// Requests left in the current window.
+	Remaining int
+
+	// Time until the current window resets.
+	Reset time.Duration
+}
+
+// Information about a response which is not part of the decoded result.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	RateLimit  RateLimit
+
+	// Only set on 429 responses.
+	RetryAfter time.Duration
+}
+
+func newResponseMeta(res *http.Response, now time.Time) ResponseMeta {
+	return ResponseMeta{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		RateLimit: RateLimit{
+			Limit:     rateLimitHeader(res.Header, "Limit"),
+			Remaining: rateLimitHeader(res.Header, "Remaining"),
+			Reset:     time.Duration(rateLimitHeader(res.Header, "Reset")) * time.Second,
+		},
+		RetryAfter: ParseRetryAfter(res.Header, now),
+	}
+}
+
+// v3 endpoints use RateLimit-*, older endpoints use X-RateLimit-*.
+func rateLimitHeader(h http.Header, name string) int {
+	for _, key := range []string{"RateLimit-" + name, "X-RateLimit-" + name} {
+		if v, err := strconv.Atoi(h.Get(key)); err == nil {
+			return v
+		}
+	}
+	return 0
+}
+
+// Returns how long the Retry-After header asks to wait, given in seconds or as an HTTP date which is compared to now.
+// 0 when the header is missing, invalid or in the past.
+func ParseRetryAfter(h http.Header, now time.Time) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// Returns a copy of the client which stores the ResponseMeta of every response in meta, so callers can see how much of
+// the rate limit is left after a call:
+//
+//	var meta klaviyo.ResponseMeta
+//	members, marker, err := client.WithResponseMeta(&meta).GetGroupMembers(groupId, marker)
+//
+// When a call is retried meta holds the last response. Do not share meta between goroutines.
+func (c *Client) WithResponseMeta(meta *ResponseMeta) *Client {
+	cc := *c
+	cc.meta = meta
+	return &cc
+}
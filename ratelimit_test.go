@@ -0,0 +1,73 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_WithResponseMeta(t *testing.T) {
+	limited := false
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "75")
+		w.Header().Set("RateLimit-Remaining", "74")
+		w.Header().Set("RateLimit-Reset", "3")
+		w.Header().Set("Content-Type", ContentJSON)
+		if limited {
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"detail": "Request was throttled."}`))
+			return
+		}
+		w.Write([]byte(`{"list_name": "Test"}`))
+	})
+
+	var meta ResponseMeta
+	if _, err := client.WithResponseMeta(&meta).GetList(testListId); err != nil {
+		t.Fatal(err)
+	}
+	if meta.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected status code %d", meta.StatusCode)
+	}
+	expected := RateLimit{Limit: 75, Remaining: 74, Reset: 3 * time.Second}
+	if meta.RateLimit != expected {
+		t.Errorf("Unexpected rate limit %+v", meta.RateLimit)
+	}
+	if client.meta != nil {
+		t.Error("WithResponseMeta should not change the original client")
+	}
+
+	limited = true
+	_, err := client.GetList(testListId)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.RateLimit.Remaining != 0 || apiErr.RateLimit.Limit != 75 {
+		t.Errorf("Unexpected rate limit %+v", apiErr.RateLimit)
+	}
+	if apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("Unexpected retry after %s", apiErr.RetryAfter)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 00:01:00 GMT": time.Minute,
+		"Sun, 31 Dec 2023 23:59:00 GMT": 0,
+	}
+	for v, want := range tests {
+		h := http.Header{}
+		h.Set("Retry-After", v)
+		if got := ParseRetryAfter(h, now); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %s, expected %s", v, got, want)
+		}
+	}
+}
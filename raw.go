@@ -0,0 +1,48 @@
+package klaviyo
+
+import "net/http"
+
+// A response exactly as Klaviyo sent it, for comparing with what was decoded or sharing with Klaviyo support. See
+// WithRawResponse.
+type RawResponse struct {
+	Method string
+
+	// The requested URL without the api_key.
+	URL string
+
+	StatusCode int
+	Header     http.Header
+
+	// Decompressed when Klaviyo gzipped it.
+	Body []byte
+}
+
+// Returns a copy of the client which stores the last response of every call in raw, next to the decoded result:
+//
+//	var raw klaviyo.RawResponse
+//	person, err := client.WithRawResponse(&raw).GetPerson(personId)
+//	log.Printf("%s %s: %d %s", raw.Method, raw.URL, raw.StatusCode, raw.Body)
+//
+// Responses which are normally decoded as they are read, such as StreamGroupMembers, are held in memory instead so
+// only use this for debugging. Do not share raw between goroutines.
+func (c *Client) WithRawResponse(raw *RawResponse) *Client {
+	cc := *c
+	cc.raw = raw
+	return &cc
+}
+
+func newRawResponse(r *http.Request, res *http.Response, body []byte) RawResponse {
+	u := *r.URL
+	values := u.Query()
+	for k := range canonicalSkipParams {
+		values.Del(k)
+	}
+	u.RawQuery = values.Encode()
+	return RawResponse{
+		Method:     r.Method,
+		URL:        u.String(),
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+}
@@ -0,0 +1,49 @@
+package klaviyo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_WithRawResponse(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Header().Set("X-Klaviyo-Trace", "abc")
+		if strings.HasPrefix(r.URL.Path, "/api/v2/group/") {
+			w.Write([]byte(`{"records": [{"id": "a"}, {"id": "b"}], "marker": 0}`))
+			return
+		}
+		w.Write([]byte(`{"object": "person", "id": "PROFILE1", "$email": "kitty@monstercat.com", "surprise": 1}`))
+	})
+
+	var raw RawResponse
+	p, err := client.WithRawResponse(&raw).GetPerson("PROFILE1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Email != "kitty@monstercat.com" {
+		t.Errorf("Unexpected person %+v", p)
+	}
+	if raw.Method != http.MethodGet || raw.StatusCode != http.StatusOK || raw.Header.Get("X-Klaviyo-Trace") != "abc" {
+		t.Errorf("Unexpected raw response %+v", raw)
+	}
+	if !strings.Contains(string(raw.Body), `"surprise": 1`) {
+		t.Errorf("Expected the body as sent, got %s", raw.Body)
+	}
+	if strings.Contains(raw.URL, "api_key") || !strings.HasSuffix(raw.URL, "/api/v1/person/PROFILE1") {
+		t.Errorf("Unexpected URL %s", raw.URL)
+	}
+
+	// Streamed responses are still decoded.
+	var ids []string
+	if _, err := client.WithRawResponse(&raw).StreamGroupMembers("LIST1", 0, func(p ListPerson) error {
+		ids = append(ids, p.Id)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || !strings.Contains(string(raw.Body), "records") {
+		t.Errorf("Unexpected members %v and body %s", ids, raw.Body)
+	}
+}
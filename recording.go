@@ -0,0 +1,516 @@
+package klaviyo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// A call made through a RecordingClient. Args are the arguments in order, variadic ones as a slice. People and events
+// are copied when recorded so changes the caller makes afterwards do not show up.
+type RecordedCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// RecordingClient is a KlaviyoAPI which keeps every call in memory before passing it on to API, so tests can assert on
+// the profile updates and events an application sends:
+//
+//	rec := &klaviyo.RecordingClient{}
+//	svc := NewService(rec)
+//	...
+//	if people := rec.People(); len(people) != 1 || people[0].Email != "kitty@monstercat.com" { ... }
+//
+// Safe for concurrent use.
+type RecordingClient struct {
+	// Optional, where calls go after being recorded, e.g. a Client pointed at a fake server. Defaults to NoopClient.
+	API KlaviyoAPI
+
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+var _ KlaviyoAPI = (*RecordingClient)(nil)
+
+func (r *RecordingClient) api() KlaviyoAPI {
+	if r.API == nil {
+		return NoopClient{}
+	}
+	return r.API
+}
+
+func (r *RecordingClient) record(method string, args ...interface{}) {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case *Person:
+			if v != nil {
+				args[i] = copyPerson(v)
+			}
+		case *NewEvent:
+			if v != nil {
+				args[i] = copyEvent(v)
+			}
+		case []Person:
+			people := make([]Person, len(v))
+			for j := range v {
+				people[j] = *copyPerson(&v[j])
+			}
+			args[i] = people
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, RecordedCall{Method: method, Args: args})
+}
+
+// Returns every call recorded so far in the order they were made.
+func (r *RecordingClient) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedCall(nil), r.calls...)
+}
+
+// Returns the calls made to the given method, e.g. "Identify".
+func (r *RecordingClient) CallsTo(method string) []RecordedCall {
+	var res []RecordedCall
+	for _, call := range r.Calls() {
+		if call.Method == method {
+			res = append(res, call)
+		}
+	}
+	return res
+}
+
+// Returns every person sent through Identify, IdentifySafe, IdentifyBatch, UpdatePerson, UpdatePersonDiff (the new
+// person), PatchPerson and CreateClientProfile, in order. Calls made with a nil person are left out.
+func (r *RecordingClient) People() []Person {
+	var res []Person
+	for _, call := range r.Calls() {
+		switch call.Method {
+		case "Identify", "IdentifySafe", "UpdatePerson", "PatchPerson", "CreateClientProfile":
+			if p := call.Args[0].(*Person); p != nil {
+				res = append(res, *p)
+			}
+		case "UpdatePersonDiff":
+			if p := call.Args[1].(*Person); p != nil {
+				res = append(res, *p)
+			}
+		case "IdentifyBatch":
+			res = append(res, call.Args[0].([]Person)...)
+		}
+	}
+	return res
+}
+
+// Returns every event sent through CreateEvent and CreateClientEvent, in order. Calls made with a nil event are left
+// out.
+func (r *RecordingClient) Events() []NewEvent {
+	var res []NewEvent
+	for _, call := range r.Calls() {
+		if call.Method != "CreateEvent" && call.Method != "CreateClientEvent" {
+			continue
+		}
+		if e := call.Args[0].(*NewEvent); e != nil {
+			res = append(res, *e)
+		}
+	}
+	return res
+}
+
+// Forgets every recorded call.
+func (r *RecordingClient) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+func (r *RecordingClient) Identify(person *Person, opts ...IdentifyOption) error {
+	r.record("Identify", person, opts)
+	return r.api().Identify(person, opts...)
+}
+
+func (r *RecordingClient) IdentifySafe(person *Person, omit bool, opts ...IdentifyOption) error {
+	r.record("IdentifySafe", person, omit, opts)
+	return r.api().IdentifySafe(person, omit, opts...)
+}
+
+func (r *RecordingClient) IdentifyBatch(people []Person) ([]string, error) {
+	r.record("IdentifyBatch", people)
+	return r.api().IdentifyBatch(people)
+}
+
+func (r *RecordingClient) ImportCSV(rd io.Reader, opts *CSVImportOptions) (*CSVImportReport, error) {
+	r.record("ImportCSV", rd, opts)
+	return r.api().ImportCSV(rd, opts)
+}
+
+func (r *RecordingClient) GetPerson(personId string) (*Person, error) {
+	r.record("GetPerson", personId)
+	return r.api().GetPerson(personId)
+}
+
+func (r *RecordingClient) GetPersonAttributesOnly(personId string, names ...string) (Attributes, error) {
+	r.record("GetPersonAttributesOnly", personId, names)
+	return r.api().GetPersonAttributesOnly(personId, names...)
+}
+
+func (r *RecordingClient) GetPeople(page, count int) (*PeoplePage, error) {
+	r.record("GetPeople", page, count)
+	return r.api().GetPeople(page, count)
+}
+
+func (r *RecordingClient) EachPerson(count int, fn func(*Person) error) error {
+	r.record("EachPerson", count, fn)
+	return r.api().EachPerson(count, fn)
+}
+
+func (r *RecordingClient) EachPersonFrom(cursor *Cursor, count int, fn func(*Person) error) error {
+	r.record("EachPersonFrom", cursor, count, fn)
+	return r.api().EachPersonFrom(cursor, count, fn)
+}
+
+func (r *RecordingClient) GetProfiles(q *Query) (*ProfilePage, error) {
+	r.record("GetProfiles", q)
+	return r.api().GetProfiles(q)
+}
+
+func (r *RecordingClient) EachProfile(q *Query, fn func(*Profile) error) error {
+	r.record("EachProfile", q, fn)
+	return r.api().EachProfile(q, fn)
+}
+
+func (r *RecordingClient) EachProfileFrom(q *Query, cursor *Cursor, fn func(*Profile) error) error {
+	r.record("EachProfileFrom", q, cursor, fn)
+	return r.api().EachProfileFrom(q, cursor, fn)
+}
+
+func (r *RecordingClient) SyncChangedProfiles(since time.Time, fn func(*Profile) error) (time.Time, error) {
+	r.record("SyncChangedProfiles", since, fn)
+	return r.api().SyncChangedProfiles(since, fn)
+}
+
+func (r *RecordingClient) FindPersonId(p *Person) (string, error) {
+	r.record("FindPersonId", p)
+	return r.api().FindPersonId(p)
+}
+
+func (r *RecordingClient) UpdatePerson(person *Person) error {
+	r.record("UpdatePerson", person)
+	return r.api().UpdatePerson(person)
+}
+
+func (r *RecordingClient) UpdatePersonDiff(old, new *Person) error {
+	r.record("UpdatePersonDiff", old, new)
+	return r.api().UpdatePersonDiff(old, new)
+}
+
+func (r *RecordingClient) UpdatePersonByEmail(email string, attrs map[string]interface{}) error {
+	r.record("UpdatePersonByEmail", email, attrs)
+	return r.api().UpdatePersonByEmail(email, attrs)
+}
+
+func (r *RecordingClient) PatchPerson(person *Person, patch *ProfilePatch) error {
+	r.record("PatchPerson", person, patch)
+	return r.api().PatchPerson(person, patch)
+}
+
+func (r *RecordingClient) DeletePerson(profileId string) error {
+	r.record("DeletePerson", profileId)
+	return r.api().DeletePerson(profileId)
+}
+
+func (r *RecordingClient) GetPredictiveAnalytics(profileId string) (*PredictiveAnalytics, error) {
+	r.record("GetPredictiveAnalytics", profileId)
+	return r.api().GetPredictiveAnalytics(profileId)
+}
+
+func (r *RecordingClient) GetPersonGroups(personId string) ([]Group, error) {
+	r.record("GetPersonGroups", personId)
+	return r.api().GetPersonGroups(personId)
+}
+
+func (r *RecordingClient) GetLists() ([]List, error) {
+	r.record("GetLists")
+	return r.api().GetLists()
+}
+
+func (r *RecordingClient) GetList(listId string) (*List, error) {
+	r.record("GetList", listId)
+	return r.api().GetList(listId)
+}
+
+func (r *RecordingClient) GetSegment(segmentId string) (*Segment, error) {
+	r.record("GetSegment", segmentId)
+	return r.api().GetSegment(segmentId)
+}
+
+func (r *RecordingClient) DoubleOptIn(listId string) (bool, error) {
+	r.record("DoubleOptIn", listId)
+	return r.api().DoubleOptIn(listId)
+}
+
+func (r *RecordingClient) CountListMembers(listId string) (int, error) {
+	r.record("CountListMembers", listId)
+	return r.api().CountListMembers(listId)
+}
+
+func (r *RecordingClient) GroupSizes(groupIds ...string) (map[string]GroupSample, error) {
+	r.record("GroupSizes", groupIds)
+	return r.api().GroupSizes(groupIds...)
+}
+
+func (r *RecordingClient) SampleGroupGrowth(ctx context.Context, groupIds []string, interval time.Duration, samples int) (map[string][]GroupSample, error) {
+	r.record("SampleGroupGrowth", ctx, groupIds, interval, samples)
+	return r.api().SampleGroupGrowth(ctx, groupIds, interval, samples)
+}
+
+func (r *RecordingClient) GetListExclusions(listId string, marker int) ([]ListExclusion, int, error) {
+	r.record("GetListExclusions", listId, marker)
+	return r.api().GetListExclusions(listId, marker)
+}
+
+func (r *RecordingClient) GetGroupMembers(groupId string, marker int) ([]ListPerson, int, error) {
+	r.record("GetGroupMembers", groupId, marker)
+	return r.api().GetGroupMembers(groupId, marker)
+}
+
+func (r *RecordingClient) StreamGroupMembers(groupId string, marker int, fn func(ListPerson) error) (int, error) {
+	r.record("StreamGroupMembers", groupId, marker, fn)
+	return r.api().StreamGroupMembers(groupId, marker, fn)
+}
+
+func (r *RecordingClient) EachGroupMember(ctx context.Context, groupId string, fn func(ListPerson) error) error {
+	r.record("EachGroupMember", ctx, groupId, fn)
+	return r.api().EachGroupMember(ctx, groupId, fn)
+}
+
+func (r *RecordingClient) EachGroupMemberFrom(ctx context.Context, groupId string, cursor *Cursor, fn func(ListPerson) error) error {
+	r.record("EachGroupMemberFrom", ctx, groupId, cursor, fn)
+	return r.api().EachGroupMemberFrom(ctx, groupId, cursor, fn)
+}
+
+func (r *RecordingClient) ForEachListMember(ctx context.Context, listId string, concurrency int, fn func(ListPerson) error) error {
+	r.record("ForEachListMember", ctx, listId, concurrency, fn)
+	return r.api().ForEachListMember(ctx, listId, concurrency, fn)
+}
+
+func (r *RecordingClient) ExportGroupMembers(w io.Writer, groupId string, format ExportFormat) error {
+	r.record("ExportGroupMembers", w, groupId, format)
+	return r.api().ExportGroupMembers(w, groupId, format)
+}
+
+func (r *RecordingClient) ExportGroupMembersReader(groupId string, format ExportFormat) io.ReadCloser {
+	r.record("ExportGroupMembersReader", groupId, format)
+	return r.api().ExportGroupMembersReader(groupId, format)
+}
+
+func (r *RecordingClient) ExportGroupMembersToFile(filename, groupId string, format ExportFormat) error {
+	r.record("ExportGroupMembersToFile", filename, groupId, format)
+	return r.api().ExportGroupMembersToFile(filename, groupId, format)
+}
+
+func (r *RecordingClient) InList(listId string, emails, phoneNumbers, pushTokens []string) ([]ListPerson, error) {
+	r.record("InList", listId, emails, phoneNumbers, pushTokens)
+	return r.api().InList(listId, emails, phoneNumbers, pushTokens)
+}
+
+func (r *RecordingClient) AddProfilesToList(listId string, profileIds []string) error {
+	r.record("AddProfilesToList", listId, profileIds)
+	return r.api().AddProfilesToList(listId, profileIds)
+}
+
+func (r *RecordingClient) RemoveProfilesFromList(listId string, profileIds []string) error {
+	r.record("RemoveProfilesFromList", listId, profileIds)
+	return r.api().RemoveProfilesFromList(listId, profileIds)
+}
+
+func (r *RecordingClient) Subscribe(listId string, emails, phoneNumbers []string) ([]ListPerson, error) {
+	r.record("Subscribe", listId, emails, phoneNumbers)
+	return r.api().Subscribe(listId, emails, phoneNumbers)
+}
+
+func (r *RecordingClient) SubscribeSMS(listId, phone string, consentTimestamp time.Time, method string) (*ListPerson, error) {
+	r.record("SubscribeSMS", listId, phone, consentTimestamp, method)
+	return r.api().SubscribeSMS(listId, phone, consentTimestamp, method)
+}
+
+func (r *RecordingClient) SubscribeProfiles(listId string, profiles []SubscribeProfile) ([]ListPerson, error) {
+	r.record("SubscribeProfiles", listId, profiles)
+	return r.api().SubscribeProfiles(listId, profiles)
+}
+
+func (r *RecordingClient) SubscribeWithStatus(listId string, profiles []SubscribeProfile) (*SubscribeResponse, error) {
+	r.record("SubscribeWithStatus", listId, profiles)
+	return r.api().SubscribeWithStatus(listId, profiles)
+}
+
+func (r *RecordingClient) Unsubscribe(listId string, emails, phoneNumbers, pushTokens []string) error {
+	r.record("Unsubscribe", listId, emails, phoneNumbers, pushTokens)
+	return r.api().Unsubscribe(listId, emails, phoneNumbers, pushTokens)
+}
+
+func (r *RecordingClient) UnsubscribeAll(listId string, person *Person) error {
+	r.record("UnsubscribeAll", listId, person)
+	return r.api().UnsubscribeAll(listId, person)
+}
+
+func (r *RecordingClient) BulkSubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	r.record("BulkSubscribeProfiles", listId, profiles)
+	return r.api().BulkSubscribeProfiles(listId, profiles)
+}
+
+func (r *RecordingClient) BulkUnsubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	r.record("BulkUnsubscribeProfiles", listId, profiles)
+	return r.api().BulkUnsubscribeProfiles(listId, profiles)
+}
+
+func (r *RecordingClient) RegisterPushToken(person *Person, token string, platform PushPlatform) error {
+	r.record("RegisterPushToken", person, token, platform)
+	return r.api().RegisterPushToken(person, token, platform)
+}
+
+func (r *RecordingClient) UnregisterPushToken(person *Person, token string, platform PushPlatform) error {
+	r.record("UnregisterPushToken", person, token, platform)
+	return r.api().UnregisterPushToken(person, token, platform)
+}
+
+func (r *RecordingClient) SuppressProfiles(emails []string) error {
+	r.record("SuppressProfiles", emails)
+	return r.api().SuppressProfiles(emails)
+}
+
+func (r *RecordingClient) UnsuppressProfiles(emails []string) error {
+	r.record("UnsuppressProfiles", emails)
+	return r.api().UnsuppressProfiles(emails)
+}
+
+func (r *RecordingClient) SuppressOnBounce(email, reason string) error {
+	r.record("SuppressOnBounce", email, reason)
+	return r.api().SuppressOnBounce(email, reason)
+}
+
+func (r *RecordingClient) CreateEvent(e *NewEvent) error {
+	r.record("CreateEvent", e)
+	return r.api().CreateEvent(e)
+}
+
+func (r *RecordingClient) CreateClientEvent(e *NewEvent) error {
+	r.record("CreateClientEvent", e)
+	return r.api().CreateClientEvent(e)
+}
+
+func (r *RecordingClient) CreateClientProfile(person *Person) error {
+	r.record("CreateClientProfile", person)
+	return r.api().CreateClientProfile(person)
+}
+
+func (r *RecordingClient) GetEvents(q *Query) (*EventPage, error) {
+	r.record("GetEvents", q)
+	return r.api().GetEvents(q)
+}
+
+func (r *RecordingClient) GetMetrics(q *Query) (*MetricPage, error) {
+	r.record("GetMetrics", q)
+	return r.api().GetMetrics(q)
+}
+
+func (r *RecordingClient) QueryMetricAggregates(q *MetricAggregateQuery) (*MetricAggregate, error) {
+	r.record("QueryMetricAggregates", q)
+	return r.api().QueryMetricAggregates(q)
+}
+
+func (r *RecordingClient) GetMetricExport(metricId string, q *MetricExportQuery) (*MetricExport, error) {
+	r.record("GetMetricExport", metricId, q)
+	return r.api().GetMetricExport(metricId, q)
+}
+
+func (r *RecordingClient) CreateCampaign(listId, templateId, subject, fromEmail, fromName, name string) (*Campaign, error) {
+	r.record("CreateCampaign", listId, templateId, subject, fromEmail, fromName, name)
+	return r.api().CreateCampaign(listId, templateId, subject, fromEmail, fromName, name)
+}
+
+func (r *RecordingClient) GetCampaignRecipients(campaignId string) ([]CampaignRecipient, error) {
+	r.record("GetCampaignRecipients", campaignId)
+	return r.api().GetCampaignRecipients(campaignId)
+}
+
+func (r *RecordingClient) GetCampaignStats(campaignId string, start, end time.Time) (*CampaignStats, error) {
+	r.record("GetCampaignStats", campaignId, start, end)
+	return r.api().GetCampaignStats(campaignId, start, end)
+}
+
+func (r *RecordingClient) GetFlows(q *Query) (*FlowPage, error) {
+	r.record("GetFlows", q)
+	return r.api().GetFlows(q)
+}
+
+func (r *RecordingClient) GetFlowActions(flowId string) ([]FlowAction, error) {
+	r.record("GetFlowActions", flowId)
+	return r.api().GetFlowActions(flowId)
+}
+
+func (r *RecordingClient) GetFlowMessages(actionId string) ([]FlowMessage, error) {
+	r.record("GetFlowMessages", actionId)
+	return r.api().GetFlowMessages(actionId)
+}
+
+func (r *RecordingClient) GetForms(q *Query) (*FormPage, error) {
+	r.record("GetForms", q)
+	return r.api().GetForms(q)
+}
+
+func (r *RecordingClient) GetForm(formId string) (*Form, error) {
+	r.record("GetForm", formId)
+	return r.api().GetForm(formId)
+}
+
+func (r *RecordingClient) TrackFormSubmission(s *FormSubmission) error {
+	r.record("TrackFormSubmission", s)
+	return r.api().TrackFormSubmission(s)
+}
+
+func (r *RecordingClient) GetTemplate(templateId string) (*Template, error) {
+	r.record("GetTemplate", templateId)
+	return r.api().GetTemplate(templateId)
+}
+
+func (r *RecordingClient) CloneTemplate(templateId, newName string) (*Template, error) {
+	r.record("CloneTemplate", templateId, newName)
+	return r.api().CloneTemplate(templateId, newName)
+}
+
+func (r *RecordingClient) UpdateTemplateHTML(templateId, html string) (*TemplateBackup, error) {
+	r.record("UpdateTemplateHTML", templateId, html)
+	return r.api().UpdateTemplateHTML(templateId, html)
+}
+
+func (r *RecordingClient) RestoreTemplate(backup *TemplateBackup) error {
+	r.record("RestoreTemplate", backup)
+	return r.api().RestoreTemplate(backup)
+}
+
+func (r *RecordingClient) ValidateTemplate(templateId string, context map[string]interface{}) (*TemplateContextReport, error) {
+	r.record("ValidateTemplate", templateId, context)
+	return r.api().ValidateTemplate(templateId, context)
+}
+
+func (r *RecordingClient) SendTemplateEmail(templateId, from, fromName, subject string, to []EmailRecipient, context map[string]interface{}) (*TemplateSendResult, error) {
+	r.record("SendTemplateEmail", templateId, from, fromName, subject, to, context)
+	return r.api().SendTemplateEmail(templateId, from, fromName, subject, to, context)
+}
+
+func (r *RecordingClient) RenderTemplatePreview(templateId string, context map[string]interface{}) (*TemplatePreview, error) {
+	r.record("RenderTemplatePreview", templateId, context)
+	return r.api().RenderTemplatePreview(templateId, context)
+}
+
+func (r *RecordingClient) RenderTemplatePreviewForPerson(templateId, personId string, context map[string]interface{}) (*TemplatePreview, error) {
+	r.record("RenderTemplatePreviewForPerson", templateId, personId, context)
+	return r.api().RenderTemplatePreviewForPerson(templateId, personId, context)
+}
+
+func (r *RecordingClient) HealthCheck(ctx context.Context) (*HealthStatus, error) {
+	r.record("HealthCheck", ctx)
+	return r.api().HealthCheck(ctx)
+}
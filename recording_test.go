@@ -0,0 +1,109 @@
+package klaviyo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNoopClient(t *testing.T) {
+	var api KlaviyoAPI = NoopClient{}
+	p := newTestPerson()
+	if err := api.Identify(&p); err != nil {
+		t.Error(err)
+	}
+	if _, err := api.FindPersonId(&p); err != ErrPersonNotFound {
+		t.Errorf("Expected ErrPersonNotFound, got %v", err)
+	}
+	res, err := api.GetPerson("PROFILE1")
+	if err != nil || res.Id != "PROFILE1" {
+		t.Errorf("Unexpected person %+v, %v", res, err)
+	}
+	xs, err := io.ReadAll(api.ExportGroupMembersReader("LIST1", ExportCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(xs) != "id,email,phone_number,push_token,created\n" {
+		t.Errorf("Expected only the CSV header, got %q", xs)
+	}
+	if _, err := io.ReadAll(api.ExportGroupMembersReader("LIST1", "xml")); err != ErrUnknownExportFormat {
+		t.Errorf("Expected ErrUnknownExportFormat, got %v", err)
+	}
+}
+
+func TestRecordingClient(t *testing.T) {
+	rec := &RecordingClient{}
+	var api KlaviyoAPI = rec
+
+	p := newTestPerson()
+	if err := api.Identify(&p, WithFields("$first_name")); err != nil {
+		t.Fatal(err)
+	}
+	p.FirstName = "Changed"
+	if err := api.UpdatePersonDiff(&Person{}, &p); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.CreateEvent(&NewEvent{Metric: "Bought"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.InList("LIST1", []string{p.Email}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	people := rec.People()
+	if len(people) != 2 {
+		t.Fatalf("Expected 2 people, got %d", len(people))
+	}
+	if people[0].FirstName == "Changed" || people[1].FirstName != "Changed" {
+		t.Errorf("Expected people to be copied when recorded, got %q and %q", people[0].FirstName, people[1].FirstName)
+	}
+	if events := rec.Events(); len(events) != 1 || events[0].Metric != "Bought" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	calls := rec.CallsTo("InList")
+	if len(calls) != 1 || calls[0].Args[0] != "LIST1" {
+		t.Errorf("Unexpected InList calls %+v", calls)
+	}
+	if len(rec.Calls()) != 4 {
+		t.Errorf("Expected 4 calls, got %d", len(rec.Calls()))
+	}
+	rec.Reset()
+	if len(rec.Calls()) != 0 {
+		t.Error("Expected Reset to forget the calls")
+	}
+
+	// Maps and slices are copied too.
+	p = newTestPerson()
+	p.Attributes = Attributes{"plan": "gold"}
+	p.Consent = []string{"email"}
+	e := &NewEvent{Metric: "Bought", Person: &p, Properties: map[string]interface{}{"sku": "A"}}
+	api.Identify(&p)
+	api.IdentifyBatch([]Person{p})
+	api.CreateEvent(e)
+	p.Attributes["plan"] = "silver"
+	p.Consent[0] = "sms"
+	e.Properties["sku"] = "B"
+	for _, person := range append(rec.People(), *rec.Events()[0].Person) {
+		if person.Attributes["plan"] != "gold" || person.Consent[0] != "email" {
+			t.Errorf("Expected the attributes and consent to be copied, got %v and %v", person.Attributes, person.Consent)
+		}
+	}
+	if rec.Events()[0].Properties["sku"] != "A" {
+		t.Errorf("Expected the properties to be copied, got %v", rec.Events()[0].Properties)
+	}
+	rec.Reset()
+
+	api.Identify(nil)
+	api.UpdatePersonDiff(&p, nil)
+	api.CreateEvent(nil)
+	if len(rec.People()) != 0 || len(rec.Events()) != 0 || len(rec.Calls()) != 3 {
+		t.Errorf("Expected nil people and events to be recorded but skipped, got %+v", rec.Calls())
+	}
+	rec.Reset()
+
+	var buf bytes.Buffer
+	rec.API = newMockClient(t, nil)
+	if err := rec.ExportGroupMembers(&buf, "LIST1", "xml"); err != ErrUnknownExportFormat {
+		t.Errorf("Expected the call to be passed on, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package klaviyo
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Where a Klaviyo account's data is hosted, which decides the host every request is sent to. The value is the host
+// itself so accounts on a region added after this SDK was released can use Region("a.example.klaviyo.com").
+type Region string
+
+const (
+	RegionUS Region = "a.klaviyo.com"
+	RegionEU Region = "a.eu.klaviyo.com"
+
+	// The host all endpoints in this SDK are written against.
+	defaultHost = string(RegionUS)
+)
+
+// Points u at the client's region when it is one of Klaviyo's endpoints.
+func (c *Client) regionURL(u *url.URL) {
+	if c.Region == "" || u.Host != defaultHost {
+		return
+	}
+	u.Host = string(c.Region)
+}
+
+// Same as regionURL for a request, which also carries the host separately.
+func (c *Client) regionRequest(r *http.Request) {
+	c.regionURL(r.URL)
+	r.Host = r.URL.Host
+}
@@ -0,0 +1,50 @@
+package klaviyo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_Region(t *testing.T) {
+	var dry []DryRunRequest
+	client := &Client{
+		PublicKey:  "public",
+		PrivateKey: "private",
+		Region:     RegionEU,
+		DryRun:     true,
+		OnDryRun: func(r DryRunRequest) {
+			dry = append(dry, r)
+		},
+	}
+	if err := client.CreateEvent(&NewEvent{Metric: MetricPlacedOrder, Person: &Person{Email: "kitty@monstercat.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dry) != 1 || !strings.HasPrefix(dry[0].URL, "https://a.eu.klaviyo.com/api/") {
+		t.Errorf("Expected the request to go to the EU host, got %+v", dry)
+	}
+
+	u, err := client.TrackURL(&NewEvent{Metric: MetricOpenedEmail, Person: &Person{Email: "kitty@monstercat.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u, "https://a.eu.klaviyo.com/api/track?") {
+		t.Errorf("Unexpected track URL %s", u)
+	}
+}
+
+func TestClient_regionRequest(t *testing.T) {
+	client := &Client{Region: RegionEU}
+	r, _ := http.NewRequest(http.MethodGet, "https://a.klaviyo.com/api/lists", nil)
+	client.regionRequest(r)
+	if r.URL.Host != "a.eu.klaviyo.com" || r.Host != "a.eu.klaviyo.com" {
+		t.Errorf("Unexpected host %s, %s", r.URL.Host, r.Host)
+	}
+
+	// Hosts other than Klaviyo's, e.g. a proxy, are left alone.
+	r, _ = http.NewRequest(http.MethodGet, "https://proxy.monstercat.com/api/lists", nil)
+	client.regionRequest(r)
+	if r.URL.Host != "proxy.monstercat.com" {
+		t.Errorf("Unexpected host %s", r.URL.Host)
+	}
+}
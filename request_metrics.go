@@ -0,0 +1,76 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// How a call to Klaviyo ended, see RequestMetrics.
+type Outcome string
+
+const (
+	OutcomeSuccess     Outcome = "success"
+	OutcomeClientError Outcome = "client_error"
+	OutcomeRateLimited Outcome = "rate_limited"
+	OutcomeServerError Outcome = "server_error"
+
+	// The call failed without an answer from Klaviyo, e.g. a network error or timeout, or the answer could not be read.
+	OutcomeError Outcome = "error"
+)
+
+// RequestMetrics is told about every call the client makes to the Klaviyo API, to chart and alert on error rates and
+// latency per operation. These are about the requests themselves, not Klaviyo metrics like MetricPlacedOrder. See the
+// prommetrics package for a Prometheus adapter.
+//
+// The operation is the method and path of the endpoint with ids replaced by :id, e.g. "GET /api/v2/list/:id/members".
+// A call is counted once with its final outcome and the latency includes retries. Calls skipped by DryRun are not
+// counted. Implementations must be safe for concurrent use.
+type RequestMetrics interface {
+	Increment(operation string, outcome Outcome)
+	Observe(operation string, latency time.Duration)
+}
+
+var (
+	// Klaviyo ids are mixed case or contain digits while the rest of the paths are lowercase words.
+	pathIdRegexp      = regexp.MustCompile(`[A-Z0-9]`)
+	pathVersionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// The operation name of the request for RequestMetrics.
+func operationName(r *http.Request) string {
+	segments := strings.Split(r.URL.Path, "/")
+	for i, s := range segments {
+		if pathIdRegexp.MatchString(s) && !pathVersionRegexp.MatchString(s) {
+			segments[i] = ":id"
+		}
+	}
+	return r.Method + " " + strings.Join(segments, "/")
+}
+
+func requestOutcome(err error) Outcome {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	var apiErr *APIError
+	switch {
+	case !errors.As(err, &apiErr):
+		return OutcomeError
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return OutcomeRateLimited
+	case apiErr.StatusCode >= http.StatusInternalServerError:
+		return OutcomeServerError
+	}
+	return OutcomeClientError
+}
+
+func (c *Client) measure(r *http.Request, start time.Time, err error) {
+	if c.RequestMetrics == nil {
+		return
+	}
+	op := operationName(r)
+	c.RequestMetrics.Increment(op, requestOutcome(err))
+	c.RequestMetrics.Observe(op, c.clock().Now().Sub(start))
+}
@@ -0,0 +1,76 @@
+package klaviyo
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testRequestMetrics struct {
+	mu        sync.Mutex
+	outcomes  map[string][]Outcome
+	latencies map[string]int
+}
+
+func (m *testRequestMetrics) Increment(operation string, outcome Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[operation] = append(m.outcomes[operation], outcome)
+}
+
+func (m *testRequestMetrics) Observe(operation string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[operation]++
+}
+
+func TestOperationName(t *testing.T) {
+	tests := map[string]string{
+		"/api/v2/list/LIST1/members":                "/api/v2/list/:id/members",
+		"/api/v1/person/01GDDKASAP8TKDDA2GRZDSVP4H": "/api/v1/person/:id",
+		"/api/lists/Xy9abc/relationships/profiles":  "/api/lists/:id/relationships/profiles",
+		"/api/profile-suppression-bulk-create-jobs": "/api/profile-suppression-bulk-create-jobs",
+		"/client/push-tokens":                       "/client/push-tokens",
+		"/api/metric-aggregates":                    "/api/metric-aggregates",
+	}
+	for path, want := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "https://a.klaviyo.com"+path, nil)
+		if got := operationName(r); got != "GET "+want {
+			t.Errorf("operationName(%s) = %s, expected GET %s", path, got, want)
+		}
+	}
+}
+
+func TestClient_RequestMetrics(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/list/LIST1":
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"list_name": "Newsletter"}`))
+		case "/api/v2/list/LIST2":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	metrics := &testRequestMetrics{outcomes: map[string][]Outcome{}, latencies: map[string]int{}}
+	client.RequestMetrics = metrics
+	client.MaxRetries = 1
+	client.RetryBackoff = time.Millisecond
+
+	for _, id := range []string{"LIST1", "LIST2", "LIST3"} {
+		client.GetList(id)
+	}
+	client.DryRun = true
+	client.Unsubscribe("LIST1", []string{"kitty@monstercat.com"}, nil, nil)
+
+	outcomes := metrics.outcomes["GET /api/v2/list/:id"]
+	if fmt.Sprint(outcomes) != "[success rate_limited server_error]" || metrics.latencies["GET /api/v2/list/:id"] != 3 {
+		t.Errorf("Unexpected metrics %v %v", metrics.outcomes, metrics.latencies)
+	}
+	if len(metrics.outcomes) != 1 {
+		t.Errorf("Expected calls skipped by DryRun not to be counted, got %v", metrics.outcomes)
+	}
+}
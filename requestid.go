@@ -0,0 +1,42 @@
+package klaviyo
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header the request id is sent in unless Client.RequestIdHeader is set.
+const DefaultRequestIdHeader = "X-Request-ID"
+
+type requestIdKey struct{}
+
+// Returns a copy of ctx carrying id, which is sent along with every request made with the context so failures can be
+// correlated across logs and support tickets:
+//
+//	ctx := klaviyo.ContextWithRequestId(r.Context(), r.Header.Get("X-Request-ID"))
+//	err := client.WithContext(ctx).Identify(&person)
+//
+// The id is also set on APIError and BadResponseError.
+func ContextWithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// Returns the id set by ContextWithRequestId, or an empty string.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+func (c *Client) requestIdHeader() string {
+	if c.RequestIdHeader != "" {
+		return c.RequestIdHeader
+	}
+	return DefaultRequestIdHeader
+}
+
+// Sets the request id of the request's context as a header.
+func (c *Client) setRequestId(r *http.Request) {
+	if id := RequestIdFromContext(r.Context()); id != "" {
+		r.Header.Set(c.requestIdHeader(), id)
+	}
+}
@@ -0,0 +1,38 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_RequestId(t *testing.T) {
+	var header string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Correlation-ID")
+		w.Header().Set("Content-Type", ContentJSON)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "not found"}`))
+	})
+	client.RequestIdHeader = "X-Correlation-ID"
+
+	ctx := ContextWithRequestId(context.Background(), "req-1")
+	_, err := client.WithContext(ctx).GetList("LIST1")
+	if header != "req-1" {
+		t.Errorf("Expected the request id header, got %q", header)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestId != "req-1" {
+		t.Errorf("Expected the request id on the error, got %v", err)
+	}
+
+	if _, err := client.GetList("LIST1"); header != "" {
+		t.Errorf("Expected no header without a request id, got %q", header)
+	} else if !errors.As(err, &apiErr) || apiErr.RequestId != "" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if RequestIdFromContext(context.Background()) != "" {
+		t.Error("Expected no request id")
+	}
+}
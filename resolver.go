@@ -0,0 +1,108 @@
+package klaviyo
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Klaviyo never changes the id of a profile, entries only go stale when profiles are merged or deleted.
+const defaultResolverTTL = time.Hour
+
+// IdentityResolver remembers which Klaviyo id belongs to an email address, phone number or custom id so flows that
+// update the same people over and over only search for them once. Lookups go through Client.FindPersonId, misses are
+// not remembered.
+//
+//	r := &klaviyo.IdentityResolver{Client: client}
+//	id, err := r.Resolve(&klaviyo.Person{Email: "kitty@monstercat.com"})
+//
+// Call Invalidate after deleting or merging a profile. Safe for concurrent use.
+type IdentityResolver struct {
+	Client *Client
+
+	// How long ids are remembered, defaults to an hour.
+	TTL time.Duration
+
+	// Optional, where ids are kept, e.g. to share them between processes. Defaults to a MemoryCache.
+	Cache Cache
+
+	once sync.Once
+}
+
+func (r *IdentityResolver) cache() Cache {
+	r.once.Do(func() {
+		if r.Cache == nil {
+			r.Cache = &MemoryCache{}
+		}
+	})
+	return r.Cache
+}
+
+func (r *IdentityResolver) ttl() time.Duration {
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return defaultResolverTTL
+}
+
+// Cache keys for the identifiers of p in the order FindPersonId uses them. Emails are case insensitive in Klaviyo.
+func identityKeys(p *Person) []string {
+	var keys []string
+	if id := strings.TrimSpace(p.CustomId); id != "" {
+		keys = append(keys, "identity:external_id:"+id)
+	}
+	if email := strings.TrimSpace(p.Email); email != "" {
+		keys = append(keys, "identity:email:"+strings.ToLower(email))
+	}
+	if phone := strings.TrimSpace(p.PhoneNumber); phone != "" {
+		keys = append(keys, "identity:phone_number:"+phone)
+	}
+	return keys
+}
+
+// Returns the Klaviyo id of p, which is p.Id when it is already set. Otherwise the first identifier FindPersonId would
+// search by is looked up in the cache before asking Klaviyo. Returns ErrPersonNotFound if there is no such profile.
+func (r *IdentityResolver) Resolve(p *Person) (string, error) {
+	if p.Id != "" {
+		return p.Id, nil
+	}
+	keys := identityKeys(p)
+	if len(keys) == 0 {
+		return "", ErrNoProfileIdentifier
+	}
+	if id, ok := r.cache().Get(keys[0]); ok {
+		return string(id), nil
+	}
+	id, err := r.Client.FindPersonId(p)
+	if err != nil {
+		return "", err
+	}
+	r.cache().Set(keys[0], []byte(id), r.ttl())
+	return id, nil
+}
+
+func (r *IdentityResolver) ResolveEmail(email string) (string, error) {
+	return r.Resolve(&Person{Email: email})
+}
+
+func (r *IdentityResolver) ResolvePhoneNumber(phoneNumber string) (string, error) {
+	return r.Resolve(&Person{PhoneNumber: phoneNumber})
+}
+
+func (r *IdentityResolver) ResolveCustomId(customId string) (string, error) {
+	return r.Resolve(&Person{CustomId: customId})
+}
+
+// Remembers that every identifier of p belongs to id, e.g. after creating the profile.
+func (r *IdentityResolver) Remember(p *Person, id string) {
+	for _, key := range identityKeys(p) {
+		r.cache().Set(key, []byte(id), r.ttl())
+	}
+}
+
+// Forgets the ids remembered for every identifier of p.
+func (r *IdentityResolver) Invalidate(p *Person) {
+	for _, key := range identityKeys(p) {
+		r.cache().Delete(key)
+	}
+}
@@ -0,0 +1,67 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIdentityResolver(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		if r.URL.Query().Get("filter") == `equals(email,"nobody@monstercat.com")` {
+			w.Write([]byte(`{"data": []}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE1"}]}`))
+	})
+	r := &IdentityResolver{Client: client}
+
+	for i := 0; i < 2; i++ {
+		id, err := r.ResolveEmail("kitty@monstercat.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "PROFILE1" {
+			t.Errorf("Unexpected id %s", id)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Expected the id to be looked up once, got %d requests", hits)
+	}
+	if id, _ := r.ResolveEmail("Kitty@Monstercat.com"); id != "PROFILE1" || hits != 1 {
+		t.Errorf("Expected emails to be case insensitive, got %s after %d requests", id, hits)
+	}
+
+	r.Invalidate(&Person{Email: "kitty@monstercat.com"})
+	if _, err := r.ResolveEmail("kitty@monstercat.com"); err != nil || hits != 2 {
+		t.Errorf("Expected a new lookup after Invalidate, got %v after %d requests", err, hits)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.ResolveEmail("nobody@monstercat.com"); err != ErrPersonNotFound {
+			t.Errorf("Expected ErrPersonNotFound, got %v", err)
+		}
+	}
+	if hits != 4 {
+		t.Errorf("Expected misses not to be remembered, got %d requests", hits)
+	}
+
+	r.Remember(&Person{PhoneNumber: "+15555555555", CustomId: "user-1"}, "PROFILE2")
+	if id, _ := r.ResolvePhoneNumber("+15555555555"); id != "PROFILE2" {
+		t.Errorf("Expected the remembered id, got %s", id)
+	}
+	if id, _ := r.ResolveCustomId("user-1"); id != "PROFILE2" {
+		t.Errorf("Expected the remembered id, got %s", id)
+	}
+	if id, _ := r.Resolve(&Person{Object: Object{Id: "PROFILE3"}}); id != "PROFILE3" {
+		t.Errorf("Expected Id to be used as is, got %s", id)
+	}
+	if _, err := r.Resolve(&Person{}); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+	if hits != 4 {
+		t.Errorf("Unexpected requests, got %d", hits)
+	}
+}
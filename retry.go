@@ -0,0 +1,165 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultRetryBackoff = 500 * time.Millisecond
+
+var (
+	ErrCircuitOpen         = errors.New("circuit breaker is open, not sending request")
+	ErrRetryBudgetExceeded = errors.New("retry budget exceeded")
+)
+
+// Returned instead of retrying when the retry would start after Client.RetryBudget is spent or after the deadline of
+// the call's context. Err is the error of the last attempt. Matches ErrRetryBudgetExceeded with errors.Is, and
+// context.DeadlineExceeded too when it was the deadline which was in the way.
+type RetryBudgetError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+
+	deadline bool
+}
+
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts in %s: %s", e.Attempts, e.Elapsed, e.Err)
+}
+
+func (e *RetryBudgetError) Is(target error) bool {
+	return target == ErrRetryBudgetExceeded || (e.deadline && target == context.DeadlineExceeded)
+}
+
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+func isServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// Whether err means Klaviyo could not be reached or answered with a 5XX error. Connection errors, DNS failures and
+// timeouts count, errors Klaviyo answered with below 500 and calls the caller canceled do not.
+func isOutage(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// Exponential backoff starting from RetryBackoff. Stops doubling before it would overflow, the retry budget or the
+// context's deadline gives up long before that anyway.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.RetryBackoff
+	if wait <= 0 {
+		wait = defaultRetryBackoff
+	}
+	for i := 0; i < attempt && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+// Whether sending r twice has the same effect as sending it once, only those are retried unless the client was made
+// with WithUnsafeRetries. A POST which timed out or failed with a 5XX may still have gone through, e.g. created an event.
+func (c *Client) canRetry(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return c.unsafeRetries
+}
+
+// Returns a copy of the client which also retries POST and PATCH requests after a 5XX error, for calls which are safe
+// to repeat or where a duplicate is better than a lost request:
+//
+//	_, err := client.WithUnsafeRetries().Subscribe(listId, emails, nil)
+func (c *Client) WithUnsafeRetries() *Client {
+	cc := *c
+	cc.unsafeRetries = true
+	return &cc
+}
+
+// Returns a *RetryBudgetError when waiting for wait before retrying would go past RetryBudget, counted from start, or
+// the deadline of the context. err is the error of the last attempt.
+func (c *Client) checkRetryBudget(start time.Time, attempts int, wait time.Duration, err error) error {
+	now := c.clock().Now()
+	next := now.Add(wait)
+	deadline, ok := c.context().Deadline()
+	pastDeadline := ok && next.After(deadline)
+	if !pastDeadline && (c.RetryBudget <= 0 || next.Sub(start) <= c.RetryBudget) {
+		return nil
+	}
+	return &RetryBudgetError{Attempts: attempts, Elapsed: now.Sub(start), Err: err, deadline: pastDeadline}
+}
+
+// CircuitBreaker stops requests from being sent after Threshold consecutive 5XX errors or requests which did not reach
+// Klaviyo at all, e.g. refused connections and timeouts. Once Cooldown has passed a single probe request is let through
+// (half-open), if it succeeds the breaker closes again, otherwise it stays open for another Cooldown. Every attempt
+// counts, including retries. Time is told by the Clock of the client making the calls.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	// Of the last client to use the breaker, for Open.
+	clock Clock
+}
+
+func (b *CircuitBreaker) allow(clock Clock) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+	if b.Threshold <= 0 || b.failures < b.Threshold {
+		return true
+	}
+	if b.probing || clock.Now().Sub(b.openedAt) < b.Cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *CircuitBreaker) record(success bool, clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.Threshold > 0 && b.failures >= b.Threshold {
+		b.openedAt = clock.Now()
+	}
+}
+
+// Returns true while requests are being rejected.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.Threshold <= 0 || b.failures < b.Threshold {
+		return false
+	}
+	clock := b.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return clock.Now().Sub(b.openedAt) < b.Cooldown
+}
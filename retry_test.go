@@ -0,0 +1,189 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_Retry(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"list_name": "Test"}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	client.MaxRetries = 3
+	client.RetryBackoff = time.Millisecond
+	if _, err := client.GetList(testListId); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 attempts, got %d", hits)
+	}
+
+	// A POST may have gone through, it is only retried when asked to.
+	hits = 0
+	if _, err := client.Subscribe(testListId, []string{"kitty@monstercat.com"}, nil); !isServerError(err) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 attempt, got %d", hits)
+	}
+	hits = 0
+	if _, err := client.WithUnsafeRetries().Subscribe(testListId, []string{"kitty@monstercat.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 attempts, got %d", hits)
+	}
+
+	// Client errors are never retried.
+	client = newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	client.MaxRetries = 3
+	client.RetryBackoff = time.Millisecond
+	hits = 0
+	if _, err := client.GetList(testListId); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 attempt, got %d", hits)
+	}
+}
+
+func TestClient_BackoffOverflow(t *testing.T) {
+	client := &Client{RetryBackoff: time.Hour}
+	for attempt := 0; attempt < 100; attempt++ {
+		if wait := client.backoff(attempt); wait < time.Hour {
+			t.Fatalf("Expected attempt %d to wait at least an hour, got %s", attempt, wait)
+		}
+	}
+}
+
+func TestClient_RetryBudget(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.MaxRetries = 5
+	client.RetryBackoff = 10 * time.Millisecond
+	client.RetryBudget = 25 * time.Millisecond
+	_, err := client.GetList(testListId)
+	var budgetErr *RetryBudgetError
+	if !errors.Is(err, ErrRetryBudgetExceeded) || !errors.As(err, &budgetErr) {
+		t.Fatalf("Expected a RetryBudgetError, got %v", err)
+	}
+	// The second retry would have to wait 20ms more, which is past the budget.
+	if hits != 2 || budgetErr.Attempts != 2 || !isServerError(err) {
+		t.Errorf("Unexpected error %+v after %d attempts", budgetErr, hits)
+	}
+
+	hits = 0
+	client.RetryBudget = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.WithContext(ctx).GetList(testListId)
+	if !errors.Is(err, ErrRetryBudgetExceeded) || !errors.Is(err, context.DeadlineExceeded) || hits != 1 {
+		t.Errorf("Expected the retry to stop before the deadline, got %v after %d attempts", err, hits)
+	}
+	if time.Since(start) >= 5*time.Millisecond {
+		t.Error("Expected to give up without waiting for the deadline")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits int
+	fail := true
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"list_name": "Test"}`))
+	})
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client.Clock = clock
+	breaker := &CircuitBreaker{Threshold: 2, Cooldown: time.Minute}
+	client.CircuitBreaker = breaker
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetList(testListId); !isServerError(err) {
+			t.Fatalf("Expected a server error, got %v", err)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatal("Expected the breaker to be open")
+	}
+	if _, err := client.GetList(testListId); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", hits)
+	}
+
+	// After the cooldown a failing probe opens the breaker again.
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := client.GetList(testListId); !isServerError(err) {
+		t.Fatalf("Expected the probe to reach the server, got %v", err)
+	}
+	if _, err := client.GetList(testListId); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after a failed probe, got %v", err)
+	}
+
+	// A successful probe closes it.
+	clock.now = clock.now.Add(time.Minute)
+	fail = false
+	if _, err := client.GetList(testListId); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.Open() {
+		t.Error("Expected the breaker to be closed")
+	}
+}
+
+func TestCircuitBreaker_ConnectionErrors(t *testing.T) {
+	var hits int
+	client := &Client{
+		PrivateKey:     "private",
+		CircuitBreaker: &CircuitBreaker{Threshold: 2, Cooldown: time.Minute},
+		Middleware: []Middleware{func(next RoundTripFunc) RoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				hits++
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			}
+		}},
+	}
+	for i := 0; i < 2; i++ {
+		var netErr net.Error
+		if _, err := client.GetList(testListId); !errors.As(err, &netErr) {
+			t.Fatalf("Expected a connection error, got %v", err)
+		}
+	}
+	if !client.CircuitBreaker.Open() {
+		t.Fatal("Expected connection errors to open the breaker")
+	}
+	if _, err := client.GetList(testListId); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 requests to be attempted, got %d", hits)
+	}
+}
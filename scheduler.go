@@ -0,0 +1,248 @@
+package klaviyo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How urgent a request is to a Scheduler, set with ContextWithPriority.
+type Priority int
+
+const (
+	// Bulk jobs and syncs, they wait while the rate limit is nearly used up so there is room left for everything else.
+	PriorityBackground Priority = -1
+
+	// Requests without a priority.
+	PriorityNormal Priority = 0
+
+	// Requests a person is waiting on, e.g. GetPerson for a support screen. They go first.
+	PriorityInteractive Priority = 1
+)
+
+type priorityKey struct{}
+
+// Returns a copy of ctx which sends requests made with it at p, use it with Client.WithContext.
+//
+//	person, err := client.WithContext(klaviyo.ContextWithPriority(r.Context(), klaviyo.PriorityInteractive)).GetPerson(id)
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// Returns the priority set with ContextWithPriority, PriorityNormal when there is none.
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// Scheduler shares a request budget between everything using a client, or several clients of the same account, so
+// bulk jobs do not starve requests people are waiting on. At most MaxConcurrent requests are in flight and waiting
+// requests are let through by priority, first come first served within the same priority. Add it to the client's
+// Middleware:
+//
+//	scheduler := &klaviyo.Scheduler{MaxConcurrent: 4, Reserve: 10}
+//	client.Middleware = append(client.Middleware, scheduler.Middleware)
+//
+// It also reads the rate limit headers of each response. Once Reserve or fewer requests are left in the window,
+// background requests wait for the window to reset, and after a 429 everything below PriorityInteractive waits for
+// Retry-After. Pauses are timed on the Clock of the client sending the requests. Safe for concurrent use, do not copy
+// after first use.
+type Scheduler struct {
+	// Requests in flight at the same time, defaults to 1.
+	MaxConcurrent int
+
+	// Requests of the rate limit window kept for normal and interactive requests.
+	Reserve int
+
+	mu       sync.Mutex
+	inFlight int
+	seq      int
+	waiting  []*scheduledRequest
+
+	// Canceled when the wake up for the end of the earliest pause is replaced by an earlier one.
+	wakeCtx    context.Context
+	cancelWake context.CancelFunc
+	wake       time.Time
+
+	// Of the last request, pauses are timed on it.
+	clock Clock
+
+	// Until when requests below the given priority are held back.
+	pausedUntil map[Priority]time.Time
+}
+
+type scheduledRequest struct {
+	priority Priority
+	seq      int
+	ready    chan struct{}
+}
+
+func (s *Scheduler) Middleware(next RoundTripFunc) RoundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		clock := clockFromContext(r.Context())
+		if err := s.acquire(r.Context(), clock); err != nil {
+			return nil, err
+		}
+		res, err := next(r)
+		if err != nil {
+			s.release()
+			return nil, err
+		}
+		s.observe(res, clock)
+		// The slot is held until the body is read so large responses count as in flight.
+		res.Body = &scheduledBody{ReadCloser: res.Body, release: s.release}
+		return res, nil
+	}
+}
+
+func (s *Scheduler) maxConcurrent() int {
+	if s.MaxConcurrent <= 0 {
+		return 1
+	}
+	return s.MaxConcurrent
+}
+
+// Reports whether a request at p has to wait for a pause to end, and until when.
+func (s *Scheduler) paused(p Priority, now time.Time) (time.Time, bool) {
+	var until time.Time
+	for below, t := range s.pausedUntil {
+		if p < below && t.After(now) && t.After(until) {
+			until = t
+		}
+	}
+	return until, !until.IsZero()
+}
+
+func (s *Scheduler) acquire(ctx context.Context, clock Clock) error {
+	p := PriorityFromContext(ctx)
+	s.mu.Lock()
+	s.clock = clock
+	s.seq++
+	req := &scheduledRequest{priority: p, seq: s.seq, ready: make(chan struct{})}
+	s.waiting = append(s.waiting, req)
+	s.dispatch()
+	s.mu.Unlock()
+
+	select {
+	case <-req.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-req.ready:
+			// Let through at the same time as ctx ended, give the slot to someone else.
+			s.inFlight--
+			s.dispatch()
+		default:
+			s.remove(req)
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	s.dispatch()
+}
+
+func (s *Scheduler) remove(req *scheduledRequest) {
+	for i, x := range s.waiting {
+		if x == req {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// Lets through as many waiting requests as there are free slots, highest priority first. Must hold mu.
+func (s *Scheduler) dispatch() {
+	clock := s.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	now := clock.Now()
+	var wake time.Time
+	for s.inFlight < s.maxConcurrent() {
+		var best *scheduledRequest
+		for _, req := range s.waiting {
+			if until, ok := s.paused(req.priority, now); ok {
+				if wake.IsZero() || until.Before(wake) {
+					wake = until
+				}
+				continue
+			}
+			if best == nil || req.priority > best.priority || (req.priority == best.priority && req.seq < best.seq) {
+				best = req
+			}
+		}
+		if best == nil {
+			break
+		}
+		s.remove(best)
+		s.inFlight++
+		close(best.ready)
+	}
+	// Paused requests are let through once the pause ends even when nothing else finishes in the meantime.
+	if !wake.IsZero() && (s.wakeCtx == nil || wake.Before(s.wake)) {
+		if s.cancelWake != nil {
+			s.cancelWake()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		s.wakeCtx, s.cancelWake, s.wake = ctx, cancel, wake
+		go func() {
+			if clock.Sleep(ctx, wake.Sub(now)) != nil {
+				return
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// Replaced by an earlier wake up while sleeping, which must not be forgotten.
+			if s.wakeCtx != ctx {
+				return
+			}
+			cancel()
+			s.wakeCtx, s.cancelWake = nil, nil
+			s.dispatch()
+		}()
+	}
+}
+
+// Pauses lower priorities based on the rate limit headers of a response.
+func (s *Scheduler) observe(res *http.Response, clock Clock) {
+	now := clock.Now()
+	meta := newResponseMeta(res, now)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pausedUntil == nil {
+		s.pausedUntil = map[Priority]time.Time{}
+	}
+	if res.StatusCode == http.StatusTooManyRequests && meta.RetryAfter > 0 {
+		s.pause(PriorityInteractive, now.Add(meta.RetryAfter))
+	}
+	rl := meta.RateLimit
+	if rl.Limit > 0 && rl.Reset > 0 && rl.Remaining <= s.Reserve {
+		s.pause(PriorityNormal, now.Add(rl.Reset))
+	}
+}
+
+func (s *Scheduler) pause(below Priority, until time.Time) {
+	if until.After(s.pausedUntil[below]) {
+		s.pausedUntil[below] = until
+	}
+}
+
+type scheduledBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *scheduledBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
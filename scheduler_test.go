@@ -0,0 +1,162 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newScheduledRequest(p Priority) *http.Request {
+	r, _ := http.NewRequestWithContext(ContextWithPriority(context.Background(), p), http.MethodGet, "https://a.klaviyo.com/api/lists", nil)
+	return r
+}
+
+func TestScheduler_Priority(t *testing.T) {
+	s := &Scheduler{MaxConcurrent: 1}
+	block := make(chan struct{})
+	var mu sync.Mutex
+	var order []Priority
+	rt := s.Middleware(func(r *http.Request) (*http.Response, error) {
+		p := PriorityFromContext(r.Context())
+		if p == PriorityNormal {
+			<-block
+		}
+		mu.Lock()
+		order = append(order, p)
+		mu.Unlock()
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	send := func(p Priority) {
+		res, err := rt(newScheduledRequest(p))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		res.Body.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		send(PriorityNormal)
+	}()
+	waitFor(t, s, func() bool { return s.inFlight == 1 })
+	go func() {
+		defer wg.Done()
+		send(PriorityBackground)
+	}()
+	waitFor(t, s, func() bool { return len(s.waiting) == 1 })
+	go func() {
+		defer wg.Done()
+		send(PriorityInteractive)
+	}()
+	waitFor(t, s, func() bool { return len(s.waiting) == 2 })
+	close(block)
+	wg.Wait()
+
+	expected := []Priority{PriorityNormal, PriorityInteractive, PriorityBackground}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestScheduler_Reserve(t *testing.T) {
+	s := &Scheduler{MaxConcurrent: 2, Reserve: 5}
+	rt := s.Middleware(func(r *http.Request) (*http.Response, error) {
+		h := http.Header{}
+		h.Set("RateLimit-Limit", "75")
+		h.Set("RateLimit-Remaining", "3")
+		h.Set("RateLimit-Reset", "60")
+		return &http.Response{StatusCode: http.StatusOK, Header: h, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	res, err := rt(newScheduledRequest(PriorityNormal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	// Nearly out of requests, background work waits for the window to reset.
+	ctx, cancel := context.WithTimeout(ContextWithPriority(context.Background(), PriorityBackground), 50*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://a.klaviyo.com/api/lists", nil)
+	if _, err := rt(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the background request to wait, got %v", err)
+	}
+	if len(s.waiting) != 0 {
+		t.Error("Expected the cancelled request to stop waiting")
+	}
+
+	res, err = rt(newScheduledRequest(PriorityInteractive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if s.inFlight != 0 {
+		t.Errorf("Expected every slot to be released, got %d in flight", s.inFlight)
+	}
+}
+
+// Waits until cond, which is called holding the scheduler's lock, is true.
+func waitFor(t *testing.T, s *Scheduler, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		ok := cond()
+		s.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("Timed out")
+}
+
+func TestScheduler_Clock(t *testing.T) {
+	s := &Scheduler{Reserve: 5}
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rt := s.Middleware(func(r *http.Request) (*http.Response, error) {
+		h := http.Header{}
+		h.Set("RateLimit-Limit", "75")
+		h.Set("RateLimit-Remaining", "3")
+		h.Set("RateLimit-Reset", "60")
+		return &http.Response{StatusCode: http.StatusOK, Header: h, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+	send := func(p Priority) {
+		t.Helper()
+		ctx := context.WithValue(ContextWithPriority(context.Background(), p), clockKey{}, Clock(clock))
+		r, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://a.klaviyo.com/api/lists", nil)
+		res, err := rt(r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		res.Body.Close()
+	}
+	send(PriorityNormal)
+
+	// The background request waits for the window to reset on the client's clock, not for a real minute.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		send(PriorityBackground)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the pause to be timed on the clock")
+	}
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if len(clock.waits) != 1 || clock.waits[0] != time.Minute {
+		t.Errorf("Expected to wait a minute, got %v", clock.waits)
+	}
+}
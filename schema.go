@@ -0,0 +1,192 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// The type Klaviyo should see for a custom attribute. Segments compare values by type, so "42" and 42 do not match
+// the same conditions.
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "boolean"
+
+	// Sent as an RFC 3339 string, accepts time.Time, KTime and strings in RFC 3339 or YYYY-MM-DD format.
+	AttributeDate AttributeType = "date"
+
+	// Any slice, Klaviyo stores these as lists.
+	AttributeList AttributeType = "list"
+)
+
+// The expected type of each custom attribute by name, see Client.Schema. Attributes not in the schema are sent as
+// they are.
+//
+//	client.Schema = klaviyo.AttributeSchema{
+//		"plan":           klaviyo.AttributeString,
+//		"lifetime_value": klaviyo.AttributeNumber,
+//	}
+type AttributeSchema map[string]AttributeType
+
+type AttributeTypeError struct {
+	Name     string
+	Expected AttributeType
+	Value    interface{}
+}
+
+func (e *AttributeTypeError) Error() string {
+	return fmt.Sprintf("attribute %q should be a %s, got %T %v", e.Name, e.Expected, e.Value, e.Value)
+}
+
+// Adds or replaces the type of an attribute.
+func (s AttributeSchema) Register(name string, t AttributeType) {
+	s[name] = t
+}
+
+// Checks the attributes against the schema and returns the first mismatch as an *AttributeTypeError, in alphabetical
+// order so the result is stable. With coerce set, values which can be converted to the expected type are converted
+// instead, e.g. "42" to 42 for a number, and only the ones which cannot are reported. The returned attributes are a copy
+// when anything was converted. Dates are formatted as RFC 3339 with or without coerce. nil values are always accepted
+// since they clear the attribute.
+func (s AttributeSchema) Check(a Attributes, coerce bool) (Attributes, error) {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		if _, ok := s[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	res, copied := a, false
+	for _, k := range keys {
+		t, v := s[k], a[k]
+		if v == nil {
+			continue
+		}
+		var cv interface{}
+		switch {
+		case t == AttributeDate && hasAttributeType(v, t):
+			// Dates are always sent in the same format whatever they were given as, it loses nothing.
+			if cv, _ = coerceAttribute(v, t); cv == v {
+				continue
+			}
+		case hasAttributeType(v, t):
+			continue
+		default:
+			var ok bool
+			cv, ok = coerceAttribute(v, t)
+			if !coerce || !ok {
+				return a, &AttributeTypeError{Name: k, Expected: t, Value: v}
+			}
+		}
+		if !copied {
+			res = make(Attributes, len(a))
+			for k, v := range a {
+				res[k] = v
+			}
+			copied = true
+		}
+		res[k] = cv
+	}
+	return res, nil
+}
+
+func hasAttributeType(v interface{}, t AttributeType) bool {
+	switch t {
+	case AttributeString:
+		_, ok := v.(string)
+		return ok
+	case AttributeNumber:
+		_, ok := attributeNumber(v)
+		return ok
+	case AttributeBool:
+		return reflect.ValueOf(v).Kind() == reflect.Bool
+	case AttributeDate:
+		switch x := v.(type) {
+		case time.Time, KTime:
+			return true
+		case string:
+			_, ok := parseAttributeDate(x)
+			return ok
+		}
+	case AttributeList:
+		return reflect.ValueOf(v).Kind() == reflect.Slice
+	}
+	return false
+}
+
+// Converts v to t, reporting false when it cannot be converted without losing information.
+func coerceAttribute(v interface{}, t AttributeType) (interface{}, bool) {
+	switch t {
+	case AttributeString:
+		switch x := v.(type) {
+		case time.Time:
+			return x.Format(time.RFC3339), true
+		case json.Number:
+			return x.String(), true
+		}
+		if reflect.ValueOf(v).Kind() == reflect.Bool {
+			return fmt.Sprint(v), true
+		}
+		if f, ok := attributeNumber(v); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), true
+		}
+	case AttributeNumber:
+		if s, ok := v.(string); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, true
+			}
+		}
+	case AttributeBool:
+		if s, ok := v.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, true
+			}
+		}
+	case AttributeDate:
+		switch x := v.(type) {
+		case time.Time:
+			return x.Format(time.RFC3339), true
+		case KTime:
+			return x.Format(time.RFC3339), true
+		case string:
+			if d, ok := parseAttributeDate(x); ok {
+				return d.Format(time.RFC3339), true
+			}
+		}
+	case AttributeList:
+		return []interface{}{v}, true
+	}
+	return nil, false
+}
+
+func attributeNumber(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func parseAttributeDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
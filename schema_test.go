@@ -0,0 +1,194 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAttributeSchema_Check(t *testing.T) {
+	schema := AttributeSchema{
+		"plan":      AttributeString,
+		"ltv":       AttributeNumber,
+		"vip":       AttributeBool,
+		"birthday":  AttributeDate,
+		"genres":    AttributeList,
+		"unchecked": AttributeNumber,
+	}
+	schema.Register("age", AttributeNumber)
+
+	valid := Attributes{
+		"plan":      "gold",
+		"ltv":       12.5,
+		"age":       KInt(30),
+		"vip":       true,
+		"birthday":  "1990-04-01",
+		"genres":    []string{"edm"},
+		"unchecked": nil,
+		"other":     "anything",
+	}
+	res, err := schema.Check(valid, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(valid) {
+		t.Errorf("Unexpected attributes %v", res)
+	}
+
+	drifted := Attributes{"plan": 3, "ltv": "12.5", "vip": "true", "genres": "edm"}
+	_, err = schema.Check(drifted, false)
+	var typeErr *AttributeTypeError
+	if !errors.As(err, &typeErr) || typeErr.Name != "genres" || typeErr.Expected != AttributeList {
+		t.Errorf("Expected the first mismatch in alphabetical order, got %v", err)
+	}
+
+	res, err = schema.Check(drifted, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["plan"] != "3" || res["ltv"] != 12.5 || res["vip"] != true {
+		t.Errorf("Unexpected coerced attributes %v", res)
+	}
+	if xs, ok := res["genres"].([]interface{}); !ok || len(xs) != 1 || xs[0] != "edm" {
+		t.Errorf("Unexpected list %v", res["genres"])
+	}
+	if drifted["ltv"] != "12.5" {
+		t.Error("Expected the input not to change")
+	}
+
+	if _, err := schema.Check(Attributes{"ltv": "a lot"}, true); !errors.As(err, &typeErr) || typeErr.Name != "ltv" {
+		t.Errorf("Expected values which cannot be converted to be reported, got %v", err)
+	}
+	if _, err := schema.Check(Attributes{"birthday": time.Now()}, false); err != nil {
+		t.Errorf("Expected time.Time to be a date, got %v", err)
+	}
+
+	birthday := time.Date(1990, 4, 1, 12, 30, 0, 0, time.UTC)
+	for _, v := range []interface{}{birthday, KTime{birthday}, "1990-04-01T12:30:00Z"} {
+		res, err := schema.Check(Attributes{"birthday": v}, false)
+		if err != nil || res["birthday"] != "1990-04-01T12:30:00Z" {
+			t.Errorf("Expected %v to be sent as RFC 3339, got %v, %v", v, res["birthday"], err)
+		}
+	}
+	if res, _ := schema.Check(Attributes{"birthday": "1990-04-01"}, false); res["birthday"] != "1990-04-01T00:00:00Z" {
+		t.Errorf("Unexpected date %v", res["birthday"])
+	}
+}
+
+func TestClient_SchemaDates(t *testing.T) {
+	var sent []interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/person/PROFILE1":
+			sent = append(sent, r.URL.Query().Get("birthday"))
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1"}`))
+		case "/api/profile-bulk-import-jobs":
+			var doc struct {
+				Data struct {
+					Attributes struct {
+						Profiles struct {
+							Data []struct {
+								Attributes struct {
+									Properties map[string]interface{} `json:"properties"`
+								} `json:"attributes"`
+							} `json:"data"`
+						} `json:"profiles"`
+					} `json:"attributes"`
+				} `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&doc)
+			for _, p := range doc.Data.Attributes.Profiles.Data {
+				sent = append(sent, p.Attributes.Properties["birthday"])
+			}
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"data": {"type": "profile-bulk-import-job", "id": "JOB1"}}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	client.Schema = AttributeSchema{"birthday": AttributeDate}
+	birthday := time.Date(1990, 4, 1, 12, 30, 0, 0, time.UTC)
+
+	if err := client.UpdatePerson(&Person{Object: Object{Id: "PROFILE1"}, Attributes: Attributes{"birthday": birthday}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.IdentifyBatch([]Person{{Email: "kitty@monstercat.com", Attributes: Attributes{"birthday": KTime{birthday}}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0] != "1990-04-01T12:30:00Z" || sent[1] != "1990-04-01T12:30:00Z" {
+		t.Errorf("Expected RFC 3339 dates on the v1 and v3 calls, got %v", sent)
+	}
+}
+
+func TestClient_Schema(t *testing.T) {
+	client := &Client{PrivateKey: "private", Schema: AttributeSchema{"ltv": AttributeNumber}}
+	p := &Person{Email: "kitty@monstercat.com", Attributes: Attributes{"ltv": "12.5"}}
+	if _, err := client.checkAttributes(p); err == nil {
+		t.Error("Expected an error for a string number")
+	}
+
+	client.CoerceAttributes = true
+	checked, err := client.checkAttributes(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked.Attributes["ltv"] != 12.5 || p.Attributes["ltv"] != "12.5" {
+		t.Errorf("Expected a coerced copy, got %v and %v", checked.Attributes, p.Attributes)
+	}
+}
+
+func TestClient_SchemaOnDiffAndSubscribe(t *testing.T) {
+	var ltv []string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.URL.Path {
+		case "/api/v1/person/PROFILE1":
+			ltv = append(ltv, r.URL.Query().Get("ltv"))
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1"}`))
+		case "/api/v2/list/LIST1/subscribe":
+			var body struct {
+				Profiles []map[string]interface{} `json:"profiles"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			ltv = append(ltv, fmt.Sprint(body.Profiles[0]["ltv"]))
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	client.Schema = AttributeSchema{"ltv": AttributeNumber}
+	old := &Person{Object: Object{Id: "PROFILE1"}, Attributes: Attributes{"ltv": 10}}
+	profiles := []SubscribeProfile{{Email: "kitty@monstercat.com", Attributes: Attributes{"ltv": "12.5"}}}
+
+	if err := client.UpdatePersonDiff(old, &Person{Attributes: Attributes{"ltv": "12.5"}}); err == nil {
+		t.Error("Expected UpdatePersonDiff to check the schema")
+	}
+	if _, err := client.SubscribeProfiles("LIST1", profiles); err == nil {
+		t.Error("Expected SubscribeProfiles to check the schema")
+	}
+	if _, err := client.SubscribeProfiles("LIST1", []SubscribeProfile{{Attributes: Attributes{"bad\nname": 1}}}); err == nil {
+		t.Error("Expected SubscribeProfiles to validate attribute names")
+	}
+	if len(ltv) != 0 {
+		t.Fatalf("Expected nothing to be sent, got %v", ltv)
+	}
+
+	client.CoerceAttributes = true
+	if err := client.UpdatePersonDiff(old, &Person{Attributes: Attributes{"ltv": "12.5"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SubscribeProfiles("LIST1", profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(ltv) != 2 || ltv[0] != "12.5" || ltv[1] != "12.5" {
+		t.Errorf("Expected the coerced values to be sent, got %v", ltv)
+	}
+	if profiles[0].Attributes["ltv"] != "12.5" {
+		t.Errorf("Expected the caller's profile not to change, got %v", profiles[0].Attributes)
+	}
+}
@@ -0,0 +1,87 @@
+package klaviyo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Query parameters left out of the canonical request because they hold credentials.
+var canonicalSkipParams = map[string]bool{
+	"api_key": true,
+}
+
+// RequestSigner is called with every request right before it is sent, along with its canonical form. Use it to add a
+// signature header or to keep an audit trail of exactly what was sent.
+type RequestSigner interface {
+	Sign(r *http.Request, canonical string) error
+}
+
+// Returns a stable text form of the request which does not include credentials:
+//
+//	METHOD
+//	/path
+//	sorted query string
+//	hex encoded SHA-256 of the body
+//
+// The same call always gives the same canonical request, so it can be used to audit and replay calls.
+func CanonicalRequest(r *http.Request) (string, error) {
+	values := r.URL.Query()
+	for k := range canonicalSkipParams {
+		values.Del(k)
+	}
+	hash := sha256.New()
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(hash, body)
+		body.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		// Encode sorts by key.
+		values.Encode(),
+		hex.EncodeToString(hash.Sum(nil)),
+	}, "\n"), nil
+}
+
+// Signs requests with an HMAC-SHA256 of their canonical form, sent hex encoded in Header.
+type HMACSigner struct {
+	Key []byte
+
+	// Defaults to X-Signature.
+	Header string
+}
+
+func (s *HMACSigner) Sign(r *http.Request, canonical string) error {
+	header := s.Header
+	if header == "" {
+		header = "X-Signature"
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(canonical))
+	r.Header.Set(header, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+func (c *Client) sign(r *http.Request) error {
+	// Sorting the query keeps the URL itself stable too, not just the canonical form.
+	r.URL.RawQuery = r.URL.Query().Encode()
+	if c.Signer == nil {
+		return nil
+	}
+	canonical, err := CanonicalRequest(r)
+	if err != nil {
+		return err
+	}
+	return c.Signer.Sign(r, canonical)
+}
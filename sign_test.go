@@ -0,0 +1,61 @@
+package klaviyo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	a, _ := http.NewRequest(http.MethodPost, "https://a.klaviyo.com/api/v2/list/ABC/subscribe?b=2&a=1&api_key=secret", strings.NewReader(`{"profiles":[]}`))
+	b, _ := http.NewRequest(http.MethodPost, "https://a.klaviyo.com/api/v2/list/ABC/subscribe?a=1&b=2", strings.NewReader(`{"profiles":[]}`))
+	ca, err := CanonicalRequest(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := CanonicalRequest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca != cb {
+		t.Errorf("Expected the same canonical request, got\n%s\nand\n%s", ca, cb)
+	}
+	if strings.Contains(ca, "secret") {
+		t.Error("The canonical request should not contain the api_key")
+	}
+	lines := strings.Split(ca, "\n")
+	if len(lines) != 4 || lines[0] != http.MethodPost || lines[1] != "/api/v2/list/ABC/subscribe" || lines[2] != "a=1&b=2" {
+		t.Errorf("Unexpected canonical request %q", ca)
+	}
+}
+
+func TestClient_Signer(t *testing.T) {
+	key := []byte("shh")
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "a=1&api_key=private&b=2" {
+			t.Errorf("Expected a sorted query, got %s", r.URL.RawQuery)
+		}
+		canonical := strings.Join([]string{
+			http.MethodGet,
+			"/api/v1/people",
+			"a=1&b=2",
+			hex.EncodeToString(sha256.New().Sum(nil)),
+		}, "\n")
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(canonical))
+		if r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Unexpected signature %s", r.Header.Get("X-Signature"))
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{}`))
+	})
+	client.Signer = &HMACSigner{Key: key}
+	u := newEndpoint(EndpointV1, "people")
+	u.RawQuery = "b=2&a=1"
+	if err := client.send(http.MethodGet, ContentJSON, u, nil); err != nil {
+		t.Fatal(err)
+	}
+}
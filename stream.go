@@ -0,0 +1,92 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Implemented by out args which decode the response body themselves as it is read, so large responses are never
+// fully held in memory.
+type streamDecoder interface {
+	decodeStream(r io.Reader) error
+}
+
+// Decodes {"records": [...], "marker": 123} calling fn for every record.
+type memberStream struct {
+	fn     func(ListPerson) error
+	marker int
+}
+
+func (s *memberStream) decodeStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "records":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				var p ListPerson
+				if err := dec.Decode(&p); err != nil {
+					return err
+				}
+				if err := s.fn(p); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		case "marker":
+			var marker *int
+			if err := dec.Decode(&marker); err != nil {
+				return err
+			}
+			if marker != nil {
+				s.marker = *marker
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %s in response, got %v", delim, tok)
+	}
+	return nil
+}
+
+// GET https://a.klaviyo.com/api/v2/group/group_id/members/all
+// Same as GetGroupMembers but calls fn for each member as the response is decoded instead of returning them all at
+// once. Returning an error from fn stops decoding and the error is returned. Use this for very large groups.
+func (c *Client) StreamGroupMembers(groupId string, marker int, fn func(ListPerson) error) (int, error) {
+	u := newEndpoint(EndpointV2, fmt.Sprintf("group/%s/members/all", groupId))
+	if marker != 0 {
+		values := u.Query()
+		values.Add("marker", strconv.Itoa(marker))
+		u.RawQuery = values.Encode()
+	}
+	s := memberStream{fn: fn}
+	err := c.send(http.MethodGet, ContentJSON, u, &s)
+	return s.marker, err
+}
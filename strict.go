@@ -0,0 +1,120 @@
+package klaviyo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrUnknownResponseField = errors.New("response has a field the SDK does not know about")
+
+// JSON:API members which are allowed in strict mode even when the result has no field for them. Every v3 response
+// has some of them and the SDK usually only keeps what it needs, e.g. the attributes of a resource.
+var jsonAPIMembers = map[string]bool{
+	"links":         true,
+	"meta":          true,
+	"jsonapi":       true,
+	"included":      true,
+	"type":          true,
+	"id":            true,
+	"relationships": true,
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Decodes a response body into out, rejecting unknown fields with ErrUnknownResponseField in StrictDecoding. Only
+// fields decoded through struct tags are checked, types with their own UnmarshalJSON such as Person decide for
+// themselves.
+func (c *Client) decodeJSON(data []byte, out interface{}) error {
+	if !c.StrictDecoding {
+		return json.NewDecoder(bytes.NewBuffer(data)).Decode(out)
+	}
+	data, err := dropJSONAPIMembers(data, out)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewBuffer(data))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(out)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field") {
+		return fmt.Errorf("%w: %s", ErrUnknownResponseField, strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return err
+}
+
+// Removes the JSON:API members out has no field for, at every level decoded through struct tags.
+func dropJSONAPIMembers(data []byte, out interface{}) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewBuffer(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if !dropMembers(v, reflect.TypeOf(out)) {
+		return data, nil
+	}
+	return json.Marshal(v)
+}
+
+// Reports whether anything was dropped from v, which is decoded into t.
+func dropMembers(v interface{}, t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		if t.Implements(unmarshalerType) {
+			return false
+		}
+		t = t.Elem()
+	}
+	if t == nil || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	var dropped bool
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		xs, _ := v.([]interface{})
+		for _, x := range xs {
+			dropped = dropMembers(x, t.Elem()) || dropped
+		}
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, x := range m {
+			if f, ok := jsonField(t, k); ok {
+				dropped = dropMembers(x, f.Type) || dropped
+			} else if jsonAPIMembers[k] {
+				delete(m, k)
+				dropped = true
+			}
+		}
+	}
+	return dropped
+}
+
+// Finds the field of t which the key decodes into, the same way encoding/json matches them.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			if ef, ok := jsonField(f.Type, key); ok {
+				return ef, true
+			}
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,31 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_StrictDecoding(t *testing.T) {
+	body := `{"data": {"type": "profile", "id": "PROFILE1", "attributes": {"predictive_analytics": {"historic_clv": 10}}}, "links": {"self": "x"}}`
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(body))
+	})
+	client.StrictDecoding = true
+
+	// Only fields the result has no place for are rejected, the document's links are fine.
+	if _, err := client.GetPredictiveAnalytics("PROFILE1"); err != nil {
+		t.Errorf("Expected a known shape to decode, got %v", err)
+	}
+
+	body = `{"data": {"type": "profile", "id": "PROFILE1", "attributes": {"predictive_analytics": {"historic_clv": 10, "new_score": 1}}}}`
+	if _, err := client.GetPredictiveAnalytics("PROFILE1"); !errors.Is(err, ErrUnknownResponseField) {
+		t.Errorf("Expected ErrUnknownResponseField, got %v", err)
+	}
+
+	client.StrictDecoding = false
+	if _, err := client.GetPredictiveAnalytics("PROFILE1"); err != nil {
+		t.Errorf("Expected unknown fields to be ignored by default, got %v", err)
+	}
+}
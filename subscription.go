@@ -0,0 +1,199 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Channels a profile can consent to through the v3 subscription endpoints.
+type ConsentChannel string
+
+const (
+	ConsentEmailMarketing   ConsentChannel = "email_marketing"
+	ConsentSMSMarketing     ConsentChannel = "sms_marketing"
+	ConsentSMSTransactional ConsentChannel = "sms_transactional"
+
+	// Klaviyo caps the amount of profiles in a single subscription job.
+	maxSubscriptionJobProfiles = 1000
+)
+
+var (
+	ErrUnknownConsentChannel = errors.New("unknown consent channel")
+
+	// Maps each channel to the keys used in the subscriptions object of the profile.
+	consentChannelKeys = map[ConsentChannel]struct {
+		channel MessageChannel
+		kind    string
+	}{
+		ConsentEmailMarketing:   {ChannelEmail, "marketing"},
+		ConsentSMSMarketing:     {ChannelSMS, "marketing"},
+		ConsentSMSTransactional: {ChannelSMS, "transactional"},
+	}
+)
+
+type SubscriptionProfile struct {
+	Email       string
+	PhoneNumber string
+
+	// Leave empty to use email marketing when there is an email and SMS marketing when there is a phone number.
+	Channels []ConsentChannel
+
+	// When consent was given, for importing consent collected outside of Klaviyo. Only used when subscribing and
+	// Klaviyo rejects it for lists using double opt-in.
+	ConsentedAt time.Time
+}
+
+func (p *SubscriptionProfile) channels() []ConsentChannel {
+	if len(p.Channels) > 0 {
+		return p.Channels
+	}
+	var xs []ConsentChannel
+	if p.Email != "" {
+		xs = append(xs, ConsentEmailMarketing)
+	}
+	if p.PhoneNumber != "" {
+		xs = append(xs, ConsentSMSMarketing)
+	}
+	return xs
+}
+
+func (p *SubscriptionProfile) resource(consent string) (map[string]interface{}, error) {
+	subscriptions := map[string]map[string]interface{}{}
+	for _, ch := range p.channels() {
+		keys, ok := consentChannelKeys[ch]
+		if !ok {
+			return nil, ErrUnknownConsentChannel
+		}
+		channel := string(keys.channel)
+		if subscriptions[channel] == nil {
+			subscriptions[channel] = map[string]interface{}{}
+		}
+		sub := map[string]string{"consent": consent}
+		if consent == "SUBSCRIBED" && !p.ConsentedAt.IsZero() {
+			sub["consented_at"] = p.ConsentedAt.UTC().Format(time.RFC3339)
+		}
+		subscriptions[channel][keys.kind] = sub
+	}
+	attrs := map[string]interface{}{
+		"subscriptions": subscriptions,
+	}
+	if p.Email != "" {
+		attrs["email"] = p.Email
+	}
+	if p.PhoneNumber != "" {
+		attrs["phone_number"] = p.PhoneNumber
+	}
+	return map[string]interface{}{
+		"type":       "profile",
+		"attributes": attrs,
+	}, nil
+}
+
+// https://developers.klaviyo.com/en/reference/subscribe_profiles
+// POST https://a.klaviyo.com/api/profile-subscription-bulk-create-jobs
+// This replaces the v2 Subscribe call. Jobs are processed asynchronously by Klaviyo, large inputs are split into
+// multiple jobs.
+func (c *Client) BulkSubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	return c.subscriptionJobs("profile-subscription-bulk-create-job", "SUBSCRIBED", listId, profiles)
+}
+
+// https://developers.klaviyo.com/en/reference/unsubscribe_profiles
+// POST https://a.klaviyo.com/api/profile-subscription-bulk-delete-jobs
+// This replaces the v2 Unsubscribe call.
+func (c *Client) BulkUnsubscribeProfiles(listId string, profiles []SubscriptionProfile) error {
+	return c.subscriptionJobs("profile-subscription-bulk-delete-job", "UNSUBSCRIBED", listId, profiles)
+}
+
+func (c *Client) subscriptionJobs(jobType, consent, listId string, profiles []SubscriptionProfile) error {
+	u := newEndpoint(Endpoint, jobType+"s")
+	for len(profiles) > 0 {
+		n := len(profiles)
+		if n > maxSubscriptionJobProfiles {
+			n = maxSubscriptionJobProfiles
+		}
+		doc, err := newSubscriptionJob(jobType, consent, listId, profiles[:n])
+		if err != nil {
+			return err
+		}
+		if err := c.sendV3(http.MethodPost, u, doc, nil); err != nil {
+			return err
+		}
+		profiles = profiles[n:]
+	}
+	return nil
+}
+
+func newSubscriptionJob(jobType, consent, listId string, profiles []SubscriptionProfile) (*document, error) {
+	data := make([]map[string]interface{}, len(profiles))
+	for i := range profiles {
+		r, err := profiles[i].resource(consent)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = r
+	}
+	return &document{
+		Data: map[string]interface{}{
+			"type": jobType,
+			"attributes": map[string]interface{}{
+				"profiles": map[string]interface{}{
+					"data": data,
+				},
+			},
+			"relationships": map[string]interface{}{
+				"list": relationshipOne{Data: resourceIdentifier{Type: "list", Id: listId}},
+			},
+		},
+	}, nil
+}
+
+// Removes the person from the list on every channel at once: their email, their phone number and every push token the
+// list has for them. Any profile identifier will do, the others are looked up first. The profile is found with
+// FindPersonId unless person has an Id, its email and phone number are read with GetPerson, and the push tokens come
+// from the list memberships InList returns for them. Does nothing when the person is not on the list.
+func (c *Client) UnsubscribeAll(listId string, person *Person) error {
+	id := person.Id
+	if id == "" {
+		var err error
+		if id, err = c.FindPersonId(person); err != nil && err != ErrPersonNotFound {
+			return err
+		}
+	}
+
+	var emails, phoneNumbers, pushTokens []string
+	seen := map[string]bool{}
+	add := func(ids *[]string, id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			*ids = append(*ids, id)
+		}
+	}
+	add(&emails, person.Email)
+	add(&phoneNumbers, person.PhoneNumber)
+	if id != "" {
+		p, err := c.GetPerson(id)
+		if err != nil {
+			return err
+		}
+		add(&emails, p.Email)
+		add(&phoneNumbers, p.PhoneNumber)
+	}
+	if len(emails) == 0 && len(phoneNumbers) == 0 {
+		return ErrPersonNotFound
+	}
+
+	members, err := c.InList(listId, emails, phoneNumbers, nil)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	for _, m := range members {
+		add(&emails, m.Email)
+		add(&phoneNumbers, m.PhoneNumber)
+		add(&pushTokens, m.PushToken)
+	}
+	return c.Unsubscribe(listId, emails, phoneNumbers, pushTokens)
+}
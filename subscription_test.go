@@ -0,0 +1,132 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewSubscriptionJob(t *testing.T) {
+	profiles := []SubscriptionProfile{
+		{Email: "kitty@monstercat.com"},
+		{PhoneNumber: "+1234567890", Channels: []ConsentChannel{ConsentSMSMarketing, ConsentSMSTransactional}},
+	}
+	doc, err := newSubscriptionJob("profile-subscription-bulk-create-job", "SUBSCRIBED", "ABC123", profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xs, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type consent struct {
+		Consent string `json:"consent"`
+	}
+	var res struct {
+		Data struct {
+			Attributes struct {
+				Profiles struct {
+					Data []struct {
+						Attributes struct {
+							Email         string                        `json:"email"`
+							PhoneNumber   string                        `json:"phone_number"`
+							Subscriptions map[string]map[string]consent `json:"subscriptions"`
+						} `json:"attributes"`
+					} `json:"data"`
+				} `json:"profiles"`
+			} `json:"attributes"`
+			Relationships struct {
+				List relationshipOne `json:"list"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(xs, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Relationships.List.Data.Id != "ABC123" {
+		t.Error("List id did not match")
+	}
+	data := res.Data.Attributes.Profiles.Data
+	if len(data) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(data))
+	}
+	if data[0].Attributes.Subscriptions["email"]["marketing"].Consent != "SUBSCRIBED" {
+		t.Error("Expected email marketing consent by default")
+	}
+	if _, ok := data[0].Attributes.Subscriptions["sms"]; ok {
+		t.Error("Did not expect sms consent without a phone number")
+	}
+	if data[1].Attributes.Subscriptions["sms"]["transactional"].Consent != "SUBSCRIBED" {
+		t.Error("Expected sms transactional consent")
+	}
+
+	profiles = []SubscriptionProfile{{Email: "kitty@monstercat.com", Channels: []ConsentChannel{"push"}}}
+	if _, err := newSubscriptionJob("profile-subscription-bulk-create-job", "SUBSCRIBED", "ABC123", profiles); err != ErrUnknownConsentChannel {
+		t.Errorf("Expected ErrUnknownConsentChannel, got %v", err)
+	}
+}
+
+func TestSubscriptionProfile_ConsentedAt(t *testing.T) {
+	p := SubscriptionProfile{Email: "kitty@monstercat.com", ConsentedAt: time.Date(2022, 11, 8, 13, 14, 15, 0, time.UTC)}
+	r, err := p.resource("SUBSCRIBED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs := r["attributes"].(map[string]interface{})["subscriptions"].(map[string]map[string]interface{})
+	email := subs["email"]["marketing"].(map[string]string)
+	if email["consented_at"] != "2022-11-08T13:14:15Z" {
+		t.Errorf("Unexpected consent %v", email)
+	}
+
+	r, err = p.resource("UNSUBSCRIBED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs = r["attributes"].(map[string]interface{})["subscriptions"].(map[string]map[string]interface{})
+	if _, ok := subs["email"]["marketing"].(map[string]string)["consented_at"]; ok {
+		t.Error("Did not expect consented_at when unsubscribing")
+	}
+}
+
+func TestConsentChannel_Channel(t *testing.T) {
+	if ch := ConsentSMSTransactional.Channel(); ch != ChannelSMS {
+		t.Errorf("Expected sms, got %s", ch)
+	}
+	if ch := ConsentChannel("push").Channel(); ch != "" {
+		t.Errorf("Expected no channel for an unknown consent channel, got %s", ch)
+	}
+}
+
+func TestClient_UnsubscribeAll(t *testing.T) {
+	var removed map[string][]string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		switch {
+		case r.URL.Path == "/api/profiles":
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE1"}]}`))
+		case r.URL.Path == "/api/v1/person/PROFILE1":
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1", "$email": "kitty@monstercat.com", "$phone_number": "+15555555555"}`))
+		case r.URL.Path == "/api/v2/list/LIST1/members":
+			if r.URL.Query().Get("emails") != "kitty@monstercat.com" || r.URL.Query().Get("phone_numbers") != "+15555555555" {
+				t.Errorf("Unexpected membership check %s", r.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`[{"id": "PROFILE1", "email": "kitty@monstercat.com"}, {"id": "PROFILE1", "push_token": "TOKEN1"}]`))
+		case r.URL.Path == "/api/v2/list/LIST1/subscribe" && r.Method == http.MethodDelete:
+			if err := json.NewDecoder(r.Body).Decode(&removed); err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+	if err := client.UnsubscribeAll("LIST1", &Person{Email: "kitty@monstercat.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed["emails"]) != 1 || len(removed["phone_numbers"]) != 1 || len(removed["push_tokens"]) != 1 || removed["push_tokens"][0] != "TOKEN1" {
+		t.Errorf("Unexpected identifiers removed %v", removed)
+	}
+}
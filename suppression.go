@@ -0,0 +1,84 @@
+package klaviyo
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Klaviyo caps the amount of profiles in a single suppression job.
+const maxSuppressionJobProfiles = 100
+
+// Custom attributes SuppressOnBounce records on the profile, since Klaviyo suppressions do not have a reason.
+const (
+	AttributeSuppressionReason = "Suppression Reason"
+	AttributeSuppressedAt      = "Suppressed At"
+)
+
+// https://developers.klaviyo.com/en/reference/suppress_profiles
+// POST https://a.klaviyo.com/api/profile-suppression-bulk-create-jobs
+// Suppressed profiles will no longer receive email marketing. Jobs are processed asynchronously by Klaviyo, large
+// inputs are split into multiple jobs.
+func (c *Client) SuppressProfiles(emails []string) error {
+	return c.suppressionJobs("profile-suppression-bulk-create-job", emails)
+}
+
+// https://developers.klaviyo.com/en/reference/unsuppress_profiles
+// POST https://a.klaviyo.com/api/profile-suppression-bulk-delete-jobs
+// Only removes suppressions that were created manually or through the API, not bounces or unsubscribes.
+func (c *Client) UnsuppressProfiles(emails []string) error {
+	return c.suppressionJobs("profile-suppression-bulk-delete-job", emails)
+}
+
+// Suppresses an email address which hard bounced somewhere else, e.g. in the bounce pipeline of a transactional email
+// provider, so Klaviyo stops sending to it too. Klaviyo suppressions do not take a reason, so the reason and the time
+// of the suppression are saved on the profile as AttributeSuppressionReason and AttributeSuppressedAt where segments
+// and support can see them. The profile is created if Klaviyo does not know the email yet.
+func (c *Client) SuppressOnBounce(email, reason string) error {
+	if strings.TrimSpace(email) == "" {
+		return ErrNoProfileIdentifier
+	}
+	if err := c.SuppressProfiles([]string{email}); err != nil {
+		return err
+	}
+	attrs := Attributes{}
+	attrs.Set(AttributeSuppressionReason, reason)
+	attrs.Set(AttributeSuppressedAt, c.clock().Now())
+	return c.UpdatePersonByEmail(email, attrs)
+}
+
+func (c *Client) suppressionJobs(jobType string, emails []string) error {
+	u := newEndpoint(Endpoint, jobType+"s")
+	for len(emails) > 0 {
+		n := len(emails)
+		if n > maxSuppressionJobProfiles {
+			n = maxSuppressionJobProfiles
+		}
+		if err := c.sendV3(http.MethodPost, u, newSuppressionJob(jobType, emails[:n]), nil); err != nil {
+			return err
+		}
+		emails = emails[n:]
+	}
+	return nil
+}
+
+func newSuppressionJob(jobType string, emails []string) *document {
+	profiles := make([]map[string]interface{}, len(emails))
+	for i, email := range emails {
+		profiles[i] = map[string]interface{}{
+			"type": "profile",
+			"attributes": map[string]interface{}{
+				"email": email,
+			},
+		}
+	}
+	return &document{
+		Data: map[string]interface{}{
+			"type": jobType,
+			"attributes": map[string]interface{}{
+				"profiles": map[string]interface{}{
+					"data": profiles,
+				},
+			},
+		},
+	}
+}
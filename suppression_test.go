@@ -0,0 +1,43 @@
+package klaviyo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_SuppressOnBounce(t *testing.T) {
+	var suppressed bool
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/profile-suppression-bulk-create-jobs":
+			suppressed = true
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/profiles":
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": [{"type": "profile", "id": "PROFILE1"}]}`))
+		case "/api/v1/person/PROFILE1":
+			if !suppressed {
+				t.Error("Expected the profile to be suppressed first")
+			}
+			q := r.URL.Query()
+			if q.Get(AttributeSuppressionReason) != "mailbox does not exist" {
+				t.Errorf("Unexpected reason %q", q.Get(AttributeSuppressionReason))
+			}
+			if q.Get(AttributeSuppressedAt) != "2024-01-02T03:04:05Z" {
+				t.Errorf("Unexpected suppression time %q", q.Get(AttributeSuppressedAt))
+			}
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	client.Clock = &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := client.SuppressOnBounce("kitty@monstercat.com", "mailbox does not exist"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SuppressOnBounce(" ", "bounced"); err != ErrNoProfileIdentifier {
+		t.Errorf("Expected ErrNoProfileIdentifier, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var ErrTemplateNotWriteable = errors.New("template was made in the drag and drop editor and cannot be changed through the API")
+
+type EmailRecipient struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type TemplateSendResult struct {
+	Id     string `json:"id"`
+	Status string `json:"status"` // e.g. queued
+}
+
+// https://apidocs.klaviyo.com/reference/templates#send-template
+// POST https://a.klaviyo.com/api/v1/email-template/template_id/send
+// Renders the template with context and sends it to the recipients right away. The context is available in the
+// template as {{ key }}.
+func (c *Client) SendTemplateEmail(templateId, from, fromName, subject string, to []EmailRecipient, context map[string]interface{}) (*TemplateSendResult, error) {
+	toJSON, err := json.Marshal(to)
+	if err != nil {
+		return nil, err
+	}
+	if context == nil {
+		context = map[string]interface{}{}
+	}
+	contextJSON, err := json.Marshal(context)
+	if err != nil {
+		return nil, err
+	}
+	u := newEndpoint(EndpointV1, fmt.Sprintf("email-template/%s/send", templateId))
+	values := url.Values{}
+	values.Set("from_email", from)
+	values.Set("from_name", fromName)
+	values.Set("subject", subject)
+	values.Set("to", string(toJSON))
+	values.Set("context", string(contextJSON))
+
+	var res TemplateSendResult
+	err = c.sendForm(http.MethodPost, ContentJSON, u, values, &res)
+	return &res, err
+}
+
+// A template rendered by RenderTemplatePreview.
+type TemplatePreview struct {
+	Id   string
+	Name string
+	HTML string
+	Text string
+}
+
+// https://developers.klaviyo.com/en/reference/render_template
+// POST https://a.klaviyo.com/api/template-render
+// Renders the template with context without sending it, e.g. to snapshot test email content before a campaign goes
+// out. The context is available in the template as {{ key }}.
+func (c *Client) RenderTemplatePreview(templateId string, context map[string]interface{}) (*TemplatePreview, error) {
+	if context == nil {
+		context = map[string]interface{}{}
+	}
+	doc := &document{
+		Data: map[string]interface{}{
+			"type": "template",
+			"id":   templateId,
+			"attributes": map[string]interface{}{
+				"context": context,
+			},
+		},
+	}
+	var res struct {
+		Data struct {
+			Id         string `json:"id"`
+			Attributes struct {
+				Name string `json:"name"`
+				HTML string `json:"html"`
+				Text string `json:"text"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.sendV3(http.MethodPost, newEndpoint(Endpoint, "template-render"), doc, &res); err != nil {
+		return nil, err
+	}
+	return &TemplatePreview{
+		Id:   res.Data.Id,
+		Name: res.Data.Attributes.Name,
+		HTML: res.Data.Attributes.HTML,
+		Text: res.Data.Attributes.Text,
+	}, nil
+}
+
+// Same as RenderTemplatePreview with the person fetched through GetPerson available as {{ person.first_name }},
+// {{ person|lookup:"LikesGold" }} and so on, the way Klaviyo renders it when sending to them. context can add to it
+// and may be nil.
+func (c *Client) RenderTemplatePreviewForPerson(templateId, personId string, context map[string]interface{}) (*TemplatePreview, error) {
+	p, err := c.GetPerson(personId)
+	if err != nil {
+		return nil, err
+	}
+	person := map[string]interface{}{}
+	for k, v := range p.Attributes {
+		person[k] = v
+	}
+	for k, v := range p.profileAttributes() {
+		if k != "properties" {
+			person[k] = v
+		}
+	}
+	ctx := map[string]interface{}{}
+	for k, v := range context {
+		ctx[k] = v
+	}
+	ctx["person"] = person
+	return c.RenderTemplatePreview(templateId, ctx)
+}
+
+type Template struct {
+	Object
+	Name string `json:"name"`
+	HTML string `json:"html"`
+
+	// False for templates made in the drag and drop editor, their HTML cannot be changed through the API.
+	IsWriteable bool `json:"is_writeable"`
+
+	Created KTime `json:"created"`
+	Updated KTime `json:"updated"`
+}
+
+// https://apidocs.klaviyo.com/reference/templates#get-template
+// GET https://a.klaviyo.com/api/v1/email-template/template_id
+func (c *Client) GetTemplate(templateId string) (*Template, error) {
+	u := newEndpoint(EndpointV1, fmt.Sprintf("email-template/%s", templateId))
+	var res Template
+	if err := c.send(http.MethodGet, ContentJSON, u, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// https://apidocs.klaviyo.com/reference/templates#clone-template
+// POST https://a.klaviyo.com/api/v1/email-template/template_id/clone
+// Copies the template under a new name and returns the copy.
+func (c *Client) CloneTemplate(templateId, newName string) (*Template, error) {
+	u := newEndpoint(EndpointV1, fmt.Sprintf("email-template/%s/clone", templateId))
+	values := url.Values{}
+	values.Set("name", newName)
+
+	var res Template
+	err := c.sendForm(http.MethodPost, ContentJSON, u, values, &res)
+	return &res, err
+}
+
+// What a template was before UpdateTemplateHTML replaced its HTML, pass it to RestoreTemplate to roll back.
+type TemplateBackup struct {
+	Id   string
+	Name string
+	HTML string
+
+	// When the backed up version was last changed. Compare it to GetTemplate before restoring to make sure no one
+	// else changed the template since.
+	Updated KTime
+}
+
+// https://apidocs.klaviyo.com/reference/templates#update-template
+// PUT https://a.klaviyo.com/api/v1/email-template/template_id
+// Replaces the HTML of the template and returns what it was before. The backup is returned even when the update
+// fails since Klaviyo may have applied it anyway. Returns ErrTemplateNotWriteable without changing anything for
+// templates made in the drag and drop editor.
+func (c *Client) UpdateTemplateHTML(templateId, html string) (*TemplateBackup, error) {
+	t, err := c.GetTemplate(templateId)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsWriteable {
+		return nil, ErrTemplateNotWriteable
+	}
+	backup := &TemplateBackup{
+		Id:      t.Id,
+		Name:    t.Name,
+		HTML:    t.HTML,
+		Updated: t.Updated,
+	}
+	if backup.Id == "" {
+		backup.Id = templateId
+	}
+	return backup, c.updateTemplate(templateId, t.Name, html)
+}
+
+// Puts back the name and HTML a template had when UpdateTemplateHTML backed it up.
+func (c *Client) RestoreTemplate(backup *TemplateBackup) error {
+	return c.updateTemplate(backup.Id, backup.Name, backup.HTML)
+}
+
+func (c *Client) updateTemplate(templateId, name, html string) error {
+	u := newEndpoint(EndpointV1, fmt.Sprintf("email-template/%s", templateId))
+	values := url.Values{}
+	values.Set("name", name)
+	values.Set("html", html)
+	return c.sendForm(http.MethodPut, ContentJSON, u, values, nil)
+}
@@ -0,0 +1,215 @@
+package klaviyo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrMissingTemplateContext = errors.New("template context is missing variables")
+
+var (
+	templateVariableRegexp = regexp.MustCompile(`(?s){{(.*?)}}`)
+	templateTagRegexp      = regexp.MustCompile(`(?s){%(.*?)%}`)
+
+	// String literals, filters with their argument and variable paths such as person.first_name, in that order so
+	// words inside literals and filter names are not taken for variables.
+	templateTokenRegexp = regexp.MustCompile(`"[^"]*"|'[^']*'|\|\s*\w+(?::\s*(?:"[^"]*"|'[^']*'|[^\s|]+))?|[A-Za-z_$][\w$]*(?:\.[\w$]+)*`)
+)
+
+// Names Klaviyo fills in itself or which are part of the template language, they never have to be in the context.
+var templateBuiltins = map[string]bool{
+	"organization": true,
+	"forloop":      true,
+	"and":          true,
+	"or":           true,
+	"not":          true,
+	"in":           true,
+	"is":           true,
+	"None":         true,
+	"True":         true,
+	"False":        true,
+}
+
+type templateReference struct {
+	path string
+
+	// Rendering does not depend on it being set, e.g. it has a default filter or an if checks for it.
+	optional bool
+}
+
+// What ValidateTemplateContext found comparing a template to its context.
+type TemplateContextReport struct {
+	// Variables the template needs which are not in the context, Klaviyo renders them as empty. Nested ones are
+	// reported by their full path, e.g. person.first_name.
+	Missing []string
+
+	// Keys of the context the template never uses, often a typo of one in Missing.
+	Extra []string
+}
+
+// Returns the context variables a template's HTML refers to as {{ key }}, {% if key %} or {% for item in key %},
+// sorted and by their full path, e.g. person.first_name. Loop variables and what Klaviyo fills in itself such as
+// {{ organization.name }} are left out.
+func TemplateVariables(html string) []string {
+	seen := map[string]bool{}
+	var vars []string
+	for _, ref := range templateReferences(html) {
+		if !seen[ref.path] {
+			seen[ref.path] = true
+			vars = append(vars, ref.path)
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// Checks that context has every variable the template's HTML refers to, to catch broken personalization before
+// calling SendTemplateEmail or RenderTemplatePreview. Variables with a default filter or checked by an if, along with
+// everything under them, are not required. Nested maps in context are followed for paths like person.first_name,
+// other values are assumed to have the rest of the path. The report is always returned, the error wraps
+// ErrMissingTemplateContext when something is missing. Extra keys are not an error.
+func ValidateTemplateContext(html string, context map[string]interface{}) (*TemplateContextReport, error) {
+	refs := templateReferences(html)
+	optional := map[string]bool{}
+	for _, ref := range refs {
+		if ref.optional {
+			optional[ref.path] = true
+		}
+	}
+	report := &TemplateContextReport{}
+	used := map[string]bool{}
+	missing := map[string]bool{}
+	for _, ref := range refs {
+		used[strings.SplitN(ref.path, ".", 2)[0]] = true
+		if !isOptionalTemplateVariable(optional, ref.path) && !missing[ref.path] && !hasTemplateVariable(context, ref.path) {
+			missing[ref.path] = true
+			report.Missing = append(report.Missing, ref.path)
+		}
+	}
+	for k := range context {
+		if !used[k] {
+			report.Extra = append(report.Extra, k)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	if len(report.Missing) > 0 {
+		return report, fmt.Errorf("%w: %s", ErrMissingTemplateContext, strings.Join(report.Missing, ", "))
+	}
+	return report, nil
+}
+
+// Same as ValidateTemplateContext with the HTML fetched through GetTemplate.
+func (c *Client) ValidateTemplate(templateId string, context map[string]interface{}) (*TemplateContextReport, error) {
+	t, err := c.GetTemplate(templateId)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateTemplateContext(t.HTML, context)
+}
+
+func templateReferences(html string) []templateReference {
+	var refs []templateReference
+	locals := map[string]bool{}
+	for _, m := range templateTagRegexp.FindAllStringSubmatch(html, -1) {
+		fields := strings.Fields(m[1])
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "for":
+			// {% for item in items %} or {% for key, value in items.items %}
+			expr := strings.TrimSpace(strings.TrimSpace(m[1])[len("for"):])
+			i := strings.Index(expr, " in ")
+			if i < 0 {
+				continue
+			}
+			for _, name := range strings.Split(expr[:i], ",") {
+				locals[strings.TrimSpace(name)] = true
+			}
+			// Looping over a map's items is not a key of the map.
+			source := strings.TrimSuffix(strings.TrimSpace(expr[i+len(" in "):]), ".items")
+			refs = append(refs, parseTemplateExpression(source, false)...)
+		case "if", "elif":
+			refs = append(refs, parseTemplateExpression(strings.Join(fields[1:], " "), true)...)
+		case "with":
+			// {% with name=person.first_name %}
+			for _, f := range fields[1:] {
+				parts := strings.SplitN(f, "=", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				locals[parts[0]] = true
+				refs = append(refs, parseTemplateExpression(parts[1], false)...)
+			}
+		}
+	}
+	for _, m := range templateVariableRegexp.FindAllStringSubmatch(html, -1) {
+		refs = append(refs, parseTemplateExpression(m[1], false)...)
+	}
+
+	n := 0
+	for _, ref := range refs {
+		root := strings.SplitN(ref.path, ".", 2)[0]
+		if !locals[root] && !templateBuiltins[root] {
+			refs[n] = ref
+			n++
+		}
+	}
+	return refs[:n]
+}
+
+func parseTemplateExpression(expr string, optional bool) []templateReference {
+	var refs []templateReference
+	for _, token := range templateTokenRegexp.FindAllString(expr, -1) {
+		switch {
+		case token[0] == '"' || token[0] == '\'':
+		case token[0] == '|':
+			name := strings.TrimSpace(strings.SplitN(token[1:], ":", 2)[0])
+			if (name == "default" || name == "default_if_none") && len(refs) > 0 {
+				refs[len(refs)-1].optional = true
+			}
+		default:
+			refs = append(refs, templateReference{path: token, optional: optional})
+		}
+	}
+	return refs
+}
+
+// Whether path or one of its parents is optional, e.g. {{ promo.code }} inside {% if promo %}.
+func isOptionalTemplateVariable(optional map[string]bool, path string) bool {
+	for {
+		if optional[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+func hasTemplateVariable(context map[string]interface{}, path string) bool {
+	var value interface{} = context
+	for _, key := range strings.Split(path, ".") {
+		var m map[string]interface{}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			m = v
+		case Attributes:
+			m = v
+		default:
+			// Lists, structs and the like, the template language is more flexible than worth following here.
+			return true
+		}
+		var ok bool
+		if value, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package klaviyo
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const testTemplateHTML = `<html>
+<p>Hi {{ person.first_name|default:"friend" }},</p>
+<p>{{ release.title }} by {{release.artist}} is out now on {{ organization.name }}.</p>
+{% if promo_code %}<p>Use {{ promo_code }} for {{ discount|floatformat:0 }}% off.</p>{% endif %}
+{% for track in tracks %}<li>{{ forloop.counter }}. {{ track.name }}</li>{% endfor %}
+{% with link=release.url %}<a href="{{ link }}">Listen</a>{% endwith %}
+<p>{{ person|lookup:"Favourite Genre" }}</p>
+{% unsubscribe 'Unsubscribe' %}
+</html>`
+
+func TestTemplateVariables(t *testing.T) {
+	vars := TemplateVariables(testTemplateHTML)
+	expected := []string{"discount", "person", "person.first_name", "promo_code", "release.artist", "release.title", "release.url", "tracks"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("Expected %v, got %v", expected, vars)
+	}
+}
+
+func TestValidateTemplateContext(t *testing.T) {
+	context := map[string]interface{}{
+		"person": Attributes{"Favourite Genre": "Drum & Bass"},
+		"release": map[string]interface{}{
+			"title":  "Uncaged",
+			"artist": "Monstercat",
+		},
+		"tracks":   []map[string]interface{}{{"name": "Intro"}},
+		"discount": 20,
+		"relase":   "typo",
+	}
+	report, err := ValidateTemplateContext(testTemplateHTML, context)
+	if !errors.Is(err, ErrMissingTemplateContext) {
+		t.Errorf("Expected ErrMissingTemplateContext, got %v", err)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"release.url"}) {
+		t.Errorf("Unexpected missing %v", report.Missing)
+	}
+	if !reflect.DeepEqual(report.Extra, []string{"relase"}) {
+		t.Errorf("Unexpected extra %v", report.Extra)
+	}
+
+	context["release"].(map[string]interface{})["url"] = "https://monstercat.com"
+	delete(context, "relase")
+	report, err = ValidateTemplateContext(testTemplateHTML, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Missing) != 0 || len(report.Extra) != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	report, err = ValidateTemplateContext(`{% for key, value in stats.items %}{{ key }}: {{ value }}{% endfor %}`, map[string]interface{}{
+		"stats": map[string]interface{}{"plays": 1},
+	})
+	if err != nil || len(report.Extra) != 0 {
+		t.Errorf("Unexpected report %+v, %v", report, err)
+	}
+}
+
+func TestClient_ValidateTemplate(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/email-template/TEMPLATE1" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"object": "email-template", "id": "TEMPLATE1", "html": "<p>Hi {{ name }}</p>"}`))
+	})
+	report, err := client.ValidateTemplate("TEMPLATE1", map[string]interface{}{"nmae": "Kitty"})
+	if !errors.Is(err, ErrMissingTemplateContext) {
+		t.Errorf("Expected ErrMissingTemplateContext, got %v", err)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"name"}) || !reflect.DeepEqual(report.Extra, []string{"nmae"}) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
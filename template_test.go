@@ -0,0 +1,189 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_SendTemplateEmail(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/email-template/ABC123/send" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("subject") != "Hello" || r.PostForm.Get("from_email") != "dev@monstercat.com" {
+			t.Errorf("Unexpected form %v", r.PostForm)
+		}
+		var to []EmailRecipient
+		if err := json.Unmarshal([]byte(r.PostForm.Get("to")), &to); err != nil || len(to) != 1 {
+			t.Errorf("Unexpected to %s", r.PostForm.Get("to"))
+		}
+		var ctx map[string]interface{}
+		if err := json.Unmarshal([]byte(r.PostForm.Get("context")), &ctx); err != nil || ctx["name"] != "Kitty" {
+			t.Errorf("Unexpected context %s", r.PostForm.Get("context"))
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"id": "XYZ", "status": "queued"}`))
+	})
+	to := []EmailRecipient{{Email: "kitty@monstercat.com", Name: "Kitty Cat"}}
+	res, err := client.SendTemplateEmail("ABC123", "dev@monstercat.com", "Monstercat", "Hello", to, map[string]interface{}{"name": "Kitty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Id != "XYZ" || res.Status != "queued" {
+		t.Errorf("Unexpected result %+v", res)
+	}
+}
+
+func TestClient_RenderTemplatePreview(t *testing.T) {
+	var context map[string]interface{}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/person/PROFILE1":
+			w.Header().Set("Content-Type", ContentJSON)
+			w.Write([]byte(`{"object": "person", "id": "PROFILE1", "$email": "kitty@monstercat.com", "$first_name": "Kitty", "LikesGold": true}`))
+		case "/api/template-render":
+			if r.Method != http.MethodPost {
+				t.Errorf("Expected POST, got %s", r.Method)
+			}
+			var body struct {
+				Data struct {
+					Id         string `json:"id"`
+					Attributes struct {
+						Context map[string]interface{} `json:"context"`
+					} `json:"attributes"`
+				} `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Data.Id != "TEMPLATE1" {
+				t.Errorf("Unexpected template %s", body.Data.Id)
+			}
+			context = body.Data.Attributes.Context
+			w.Header().Set("Content-Type", ContentJSONAPI)
+			w.Write([]byte(`{"data": {"type": "template", "id": "TEMPLATE1", "attributes": {"name": "Welcome", "html": "<p>Hi Kitty</p>", "text": "Hi Kitty"}}}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	})
+
+	res, err := client.RenderTemplatePreview("TEMPLATE1", map[string]interface{}{"name": "Kitty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "Welcome" || res.HTML != "<p>Hi Kitty</p>" || res.Text != "Hi Kitty" {
+		t.Errorf("Unexpected preview %+v", res)
+	}
+	if context["name"] != "Kitty" {
+		t.Errorf("Unexpected context %v", context)
+	}
+
+	if _, err := client.RenderTemplatePreviewForPerson("TEMPLATE1", "PROFILE1", map[string]interface{}{"coupon": "GOLD"}); err != nil {
+		t.Fatal(err)
+	}
+	person, _ := context["person"].(map[string]interface{})
+	if context["coupon"] != "GOLD" || person["first_name"] != "Kitty" || person["LikesGold"] != true {
+		t.Errorf("Unexpected context %v", context)
+	}
+}
+
+func TestClient_RenderTemplatePreviewDryRun(t *testing.T) {
+	var hits int
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", ContentJSONAPI)
+		w.Write([]byte(`{"data": {"type": "template", "id": "TEMPLATE1", "attributes": {"html": "<p>Hi</p>"}}}`))
+	})
+	client.DryRun = true
+	res, err := client.RenderTemplatePreview("TEMPLATE1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 || res.HTML != "<p>Hi</p>" {
+		t.Errorf("Expected rendering to be sent in dry runs, got %d requests", hits)
+	}
+}
+
+func TestClient_CloneTemplate(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/email-template/TEMPLATE1/clone" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("name") != "Release 002" {
+			t.Errorf("Unexpected form %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"object": "email-template", "id": "TEMPLATE2", "name": "Release 002", "html": "<p>Out now</p>", "is_writeable": true}`))
+	})
+	template, err := client.CloneTemplate("TEMPLATE1", "Release 002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Id != "TEMPLATE2" || template.Name != "Release 002" || template.HTML != "<p>Out now</p>" || !template.IsWriteable {
+		t.Errorf("Unexpected template %+v", template)
+	}
+}
+
+func TestClient_UpdateTemplateHTML(t *testing.T) {
+	html := "<p>Old</p>"
+	writeable := true
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/email-template/TEMPLATE1" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", ContentJSON)
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"object":       "email-template",
+				"id":           "TEMPLATE1",
+				"name":         "Release",
+				"html":         html,
+				"is_writeable": writeable,
+				"updated":      "2022-01-02 03:04:05",
+			})
+		case http.MethodPut:
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if r.PostForm.Get("name") != "Release" {
+				t.Errorf("Expected the name to be kept, got %s", r.PostForm.Get("name"))
+			}
+			html = r.PostForm.Get("html")
+			w.Write([]byte(`{"object": "email-template", "id": "TEMPLATE1"}`))
+		default:
+			t.Errorf("Unexpected method %s", r.Method)
+		}
+	})
+
+	backup, err := client.UpdateTemplateHTML("TEMPLATE1", "<p>New</p>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup.Id != "TEMPLATE1" || backup.Name != "Release" || backup.HTML != "<p>Old</p>" || backup.Updated.IsZero() {
+		t.Errorf("Unexpected backup %+v", backup)
+	}
+	if html != "<p>New</p>" {
+		t.Errorf("Expected the new HTML to be uploaded, got %s", html)
+	}
+
+	if err := client.RestoreTemplate(backup); err != nil {
+		t.Fatal(err)
+	}
+	if html != "<p>Old</p>" {
+		t.Errorf("Expected the old HTML to be restored, got %s", html)
+	}
+
+	writeable = false
+	if _, err := client.UpdateTemplateHTML("TEMPLATE1", "<p>New</p>"); err != ErrTemplateNotWriteable {
+		t.Errorf("Expected ErrTemplateNotWriteable, got %v", err)
+	}
+	if html != "<p>Old</p>" {
+		t.Errorf("Expected a drag and drop template not to be changed, got %s", html)
+	}
+}
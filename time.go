@@ -0,0 +1,61 @@
+package klaviyo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+var (
+	// Formats seen in Klaviyo responses, tried in order. Strings without a timezone are in UTC.
+	kTimeFormats = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	}
+)
+
+// KTime implements the UnmarshalJSON interface to do special processing for Klaviyo. Depending on the endpoint,
+// Klaviyo returns timestamps as unix seconds (number or string), ISO 8601 strings or "2006-01-02 15:04:05" formatted
+// strings. Empty strings and null are treated as the zero time.
+type KTime struct {
+	time.Time
+}
+
+func (t *KTime) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*t = KTime{}
+		return nil
+	}
+	// Strip the quotes the same way KFloat and KInt do so unix timestamps sent as strings also work.
+	s := string(frontBackQuotesRegexp.ReplaceAll(b, nil))
+	if s == "" {
+		*t = KTime{}
+		return nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac := math.Modf(secs)
+		*t = KTime{time.Unix(int64(whole), int64(frac*1e9)).UTC()}
+		return nil
+	}
+	for _, format := range kTimeFormats {
+		if v, err := time.Parse(format, s); err == nil {
+			*t = KTime{v}
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized time format %q", s)
+}
+
+// Times are always sent as ISO 8601 which every Klaviyo endpoint accepts. The zero time is sent as null.
+func (t KTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339))
+}
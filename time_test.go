@@ -0,0 +1,68 @@
+package klaviyo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestKTime_UnmarshalJSON(t *testing.T) {
+	expected := time.Date(2022, 11, 8, 13, 14, 15, 0, time.UTC)
+	tests := []string{
+		`1667913255`,
+		`"1667913255"`,
+		`"2022-11-08T13:14:15Z"`,
+		`"2022-11-08T13:14:15+00:00"`,
+		`"2022-11-08T05:14:15-08:00"`,
+		`"2022-11-08T13:14:15"`,
+		`"2022-11-08 13:14:15"`,
+	}
+	for _, test := range tests {
+		var k KTime
+		if err := json.Unmarshal([]byte(test), &k); err != nil {
+			t.Errorf("Failed to parse %s: %s", test, err)
+			continue
+		}
+		if !k.Equal(expected) {
+			t.Errorf("Parsed %s as %s", test, k)
+		}
+	}
+
+	for _, test := range []string{`""`, `null`} {
+		k := KTime{expected}
+		if err := json.Unmarshal([]byte(test), &k); err != nil {
+			t.Errorf("Failed to parse %s: %s", test, err)
+		} else if !k.IsZero() {
+			t.Errorf("Expected zero time for %s", test)
+		}
+	}
+
+	var k KTime
+	if err := json.Unmarshal([]byte(`"yesterday"`), &k); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestKTime_MarshalJSON(t *testing.T) {
+	a := struct {
+		Created KTime `json:"created"`
+		Updated KTime `json:"updated"`
+	}{
+		Created: KTime{time.Date(2022, 11, 8, 13, 14, 15, 0, time.UTC)},
+	}
+	xs, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(xs) != `{"created":"2022-11-08T13:14:15Z","updated":null}` {
+		t.Errorf("Unexpected JSON %s", xs)
+	}
+	b := a
+	b.Created = KTime{}
+	if err := json.Unmarshal(xs, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Created.Equal(a.Created.Time) || !b.Updated.IsZero() {
+		t.Error("Times did not match after encoding/decoding process")
+	}
+}
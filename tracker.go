@@ -0,0 +1,152 @@
+package klaviyo
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTrackerSize     = 100
+	defaultTrackerInterval = 5 * time.Second
+)
+
+var (
+	ErrTrackerClosed = errors.New("tracker buffer is closed")
+)
+
+// Options for NewTrackerBuffer. Zero values use the defaults.
+type TrackerOptions struct {
+	// Number of buffered events which triggers a flush, defaults to 100.
+	Size int
+
+	// How often the buffer is flushed regardless of its size, defaults to 5 seconds.
+	Interval time.Duration
+
+	// Called from the flushing goroutine for every event which could not be sent. Errors are dropped otherwise.
+	OnError func(e *NewEvent, err error)
+}
+
+// TrackerBuffer creates events in the background so request handlers never wait on Klaviyo. Track only appends to
+// an in-memory buffer which is flushed when it reaches Size events or every Interval, whichever comes first. Buffered
+// events are lost if the process exits without calling Close, use RequestQueue when they must survive restarts.
+type TrackerBuffer struct {
+	client *Client
+	opts   TrackerOptions
+
+	mu     sync.Mutex
+	events []*NewEvent
+	closed bool
+
+	// Only one flush sends events at a time.
+	flushMu sync.Mutex
+
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Creates the buffer and starts flushing it in the background. Call Close when done.
+func NewTrackerBuffer(client *Client, opts TrackerOptions) *TrackerBuffer {
+	if opts.Size <= 0 {
+		opts.Size = defaultTrackerSize
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTrackerInterval
+	}
+	b := &TrackerBuffer{
+		client: client,
+		opts:   opts,
+		full:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *TrackerBuffer) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		case <-b.full:
+		}
+		b.Flush()
+	}
+}
+
+// Buffers the event to be created later. The event is checked right away and its time is set to now when empty, so
+// the delay does not change when it happened. Never blocks on Klaviyo.
+func (b *TrackerBuffer) Track(e *NewEvent) error {
+	if e.Metric == "" {
+		return ErrNoMetricName
+	}
+	if e.Person == nil || !e.Person.HasProfileIdentifier() {
+		return ErrNoProfileIdentifier
+	}
+	// Copied with its person and properties, the caller may change them before the flush.
+	ev := copyEvent(e)
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrTrackerClosed
+	}
+	b.events = append(b.events, ev)
+	if len(b.events) >= b.opts.Size {
+		select {
+		case b.full <- struct{}{}:
+		default:
+			// A flush is already pending.
+		}
+	}
+	return nil
+}
+
+// Sends every buffered event and waits for it to finish. Returns the first error, every failed event is also passed
+// to OnError.
+func (b *TrackerBuffer) Flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	events := b.events
+	b.events = nil
+	b.mu.Unlock()
+
+	var first error
+	for _, e := range events {
+		if err := b.client.CreateEvent(e); err != nil {
+			if first == nil {
+				first = err
+			}
+			if b.opts.OnError != nil {
+				b.opts.OnError(e, err)
+			}
+		}
+	}
+	return first
+}
+
+// Stops the background flushing and sends whatever is left. Track returns ErrTrackerClosed afterwards.
+func (b *TrackerBuffer) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+	return b.Flush()
+}
@@ -0,0 +1,81 @@
+package klaviyo
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTrackerBuffer(t *testing.T) {
+	var created int32
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&created, 1)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	b := NewTrackerBuffer(client, TrackerOptions{Size: 2, Interval: time.Hour})
+	person := &Person{Email: "kitty@monstercat.com"}
+	if err := b.Track(&NewEvent{Person: person}); err != ErrNoMetricName {
+		t.Errorf("Expected ErrNoMetricName, got %v", err)
+	}
+
+	// Reaching the size triggers a flush without waiting for the interval.
+	for i := 0; i < 2; i++ {
+		if err := b.Track(&NewEvent{Metric: "Viewed Release", Person: person}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&created) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&created); n != 2 {
+		t.Fatalf("Expected 2 events after reaching the size, got %d", n)
+	}
+
+	if err := b.Track(&NewEvent{Metric: "Viewed Release", Person: person}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&created); n != 3 {
+		t.Errorf("Expected Close to flush the last event, got %d", n)
+	}
+	if err := b.Track(&NewEvent{Metric: "Viewed Release", Person: person}); err != ErrTrackerClosed {
+		t.Errorf("Expected ErrTrackerClosed, got %v", err)
+	}
+}
+
+func TestTrackerBuffer_OnError(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	var failed []*NewEvent
+	b := NewTrackerBuffer(client, TrackerOptions{
+		Interval: time.Hour,
+		OnError: func(e *NewEvent, err error) {
+			failed = append(failed, e)
+		},
+	})
+	e := &NewEvent{
+		Metric:     "Viewed Release",
+		Person:     &Person{Email: "kitty@monstercat.com"},
+		Properties: map[string]interface{}{"release": "Uncaged"},
+	}
+	if err := b.Track(e); err != nil {
+		t.Fatal(err)
+	}
+	// Changes after Track must not reach the buffered event.
+	e.Person.Email = "changed@monstercat.com"
+	e.Properties["release"] = "Changed"
+	if err := b.Close(); err == nil {
+		t.Error("Expected an error from the final flush")
+	}
+	if len(failed) != 1 || failed[0].Time.IsZero() {
+		t.Fatalf("Unexpected failed events %+v", failed)
+	}
+	if failed[0].Person.Email != "kitty@monstercat.com" || failed[0].Properties["release"] != "Uncaged" {
+		t.Errorf("Expected the event to be copied when buffered, got %+v", failed[0])
+	}
+}
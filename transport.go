@@ -0,0 +1,117 @@
+package klaviyo
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// Used when Client.DefaultTimeout is 0.
+	DefaultRequestTimeout = 10 * time.Second
+
+	// Every call goes to the same couple of hosts, so keep more idle connections around than the standard library's
+	// default of 2 per host.
+	defaultMaxIdleConnsPerHost = 16
+
+	// The dialer settings of http.DefaultTransport.
+	defaultDialTimeout = 30 * time.Second
+	defaultKeepAlive   = 30 * time.Second
+)
+
+// Returns a client for the account with the given keys and DefaultRequestTimeout, configure the rest through its
+// fields.
+func NewClient(publicKey, privateKey string) *Client {
+	return &Client{
+		PublicKey:      publicKey,
+		PrivateKey:     privateKey,
+		DefaultTimeout: DefaultRequestTimeout,
+	}
+}
+
+// Shared by every Client without a Transport so connections are reused across clients and calls.
+var defaultTransport = NewTransport(TransportOptions{})
+
+// Keep-alive and timeout tuning for NewTransport. Zero values use the defaults of http.DefaultTransport, except
+// MaxIdleConnsPerHost which defaults to 16.
+type TransportOptions struct {
+	// How long establishing a TCP connection may take, defaults to 30s.
+	ConnectTimeout time.Duration
+
+	// How long the TLS handshake may take once connected, defaults to 10s.
+	TLSHandshakeTimeout time.Duration
+
+	// How long to wait for the response headers once the request was sent, which catches a stalled Klaviyo without
+	// limiting how long large response bodies take to read. No limit by default besides Client.DefaultTimeout.
+	ResponseHeaderTimeout time.Duration
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+
+	// How long an idle connection is kept in the pool.
+	IdleConnTimeout time.Duration
+
+	// Interval between TCP keep-alive probes.
+	KeepAlive time.Duration
+
+	DisableKeepAlives bool
+}
+
+// Creates a transport for Client.Transport. Create one and share it between clients to reuse connections.
+func NewTransport(o TransportOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.KeepAlive > 0 || o.ConnectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: defaultKeepAlive,
+		}
+		if o.ConnectTimeout > 0 {
+			dialer.Timeout = o.ConnectTimeout
+		}
+		if o.KeepAlive > 0 {
+			dialer.KeepAlive = o.KeepAlive
+		}
+		t.DialContext = dialer.DialContext
+	}
+	if o.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+	if o.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	}
+	t.DisableKeepAlives = o.DisableKeepAlives
+	return t
+}
+
+func (c *Client) transport() http.RoundTripper {
+	if c.Transport != nil {
+		return c.Transport
+	}
+	return defaultTransport
+}
+
+// The http.Client only holds the transport and timeout so it is cheap to create per call, the connections live in
+// the shared transport.
+func (c *Client) httpClient() *http.Client {
+	timeout := c.DefaultTimeout
+	if c.timeout != 0 {
+		timeout = c.timeout
+	}
+	switch {
+	case timeout == 0:
+		timeout = DefaultRequestTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+	return &http.Client{Transport: chainMiddleware(c.transport(), c.Middleware), Timeout: timeout}
+}
@@ -0,0 +1,94 @@
+package klaviyo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewTransport(t *testing.T) {
+	tr := NewTransport(TransportOptions{})
+	if tr.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost {
+		t.Errorf("Expected %d idle connections per host, got %d", defaultMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+	}
+	tr = NewTransport(TransportOptions{
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     time.Minute,
+		DisableKeepAlives:   true,
+	})
+	if tr.MaxIdleConns != 10 || tr.MaxIdleConnsPerHost != 5 || tr.IdleConnTimeout != time.Minute || !tr.DisableKeepAlives {
+		t.Error("Options were not applied")
+	}
+	tr = NewTransport(TransportOptions{
+		ConnectTimeout:        time.Second,
+		TLSHandshakeTimeout:   2 * time.Second,
+		ResponseHeaderTimeout: 3 * time.Second,
+	})
+	if tr.TLSHandshakeTimeout != 2*time.Second || tr.ResponseHeaderTimeout != 3*time.Second {
+		t.Error("Timeouts were not applied")
+	}
+}
+
+func TestClient_DefaultTimeout(t *testing.T) {
+	client := NewClient("public", "private")
+	if client.DefaultTimeout != DefaultRequestTimeout || client.PrivateKey != "private" {
+		t.Errorf("Unexpected client %+v", client)
+	}
+	if timeout := (&Client{}).httpClient().Timeout; timeout != DefaultRequestTimeout {
+		t.Errorf("Expected the zero value to time out after %s, got %s", DefaultRequestTimeout, timeout)
+	}
+	if timeout := (&Client{DefaultTimeout: -1}).httpClient().Timeout; timeout != 0 {
+		t.Errorf("Expected a negative timeout to disable it, got %s", timeout)
+	}
+	if timeout := NewClient("", "").WithTimeout(-1).httpClient().Timeout; timeout != 0 {
+		t.Errorf("Expected WithTimeout to disable the timeout, got %s", timeout)
+	}
+}
+
+func TestClient_Transport(t *testing.T) {
+	a, b := &Client{}, &Client{}
+	if a.transport() != defaultTransport || b.httpClient().Transport != a.httpClient().Transport {
+		t.Error("Clients without a Transport should share the default transport")
+	}
+	tr := NewTransport(TransportOptions{})
+	a.Transport = tr
+	if a.httpClient().Transport != tr {
+		t.Error("Expected the configured transport to be used")
+	}
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", ContentJSON)
+		w.Write([]byte(`{"list_name": "Test"}`))
+	})
+	if _, err := client.WithTimeout(10 * time.Millisecond).GetList(testListId); err == nil {
+		t.Error("Expected the call to time out")
+	}
+	if _, err := client.GetList(testListId); err != nil {
+		t.Errorf("The original client should keep its timeout, got %s", err)
+	}
+}
+
+func TestClient_WithContext(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.MaxRetries = 5
+	client.RetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.WithContext(ctx).GetList(testListId)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Waiting between retries should stop when the context is done")
+	}
+}
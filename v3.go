@@ -0,0 +1,157 @@
+// Klaviyo's v3 API follows the JSON:API specification. Resources are wrapped in a "data" object with their type, id and
+// attributes, relationships point to other resources and collections are paginated through links.
+// https://developers.klaviyo.com/en/reference/api_overview
+
+package klaviyo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The v3 API is versioned by date through the revision header rather than the URL.
+const APIRevision = "2024-02-15"
+
+// Error object returned by v3 endpoints. Multiple can be returned for a single request.
+type ErrorObject struct {
+	Id     string `json:"id"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Source struct {
+		Pointer   string `json:"pointer"`
+		Parameter string `json:"parameter"`
+	} `json:"source"`
+}
+
+func (e ErrorObject) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Title
+}
+
+// Links are returned alongside v3 collections and are used for pagination.
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Prev  string `json:"prev"`
+	Next  string `json:"next"`
+}
+
+// Returns the page[cursor] value of the next page, or an empty string if this was the last page.
+func (l Links) NextCursor() string {
+	if l.Next == "" {
+		return ""
+	}
+	u, err := url.Parse(l.Next)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("page[cursor]")
+}
+
+// Query holds the common query parameters supported by v3 collection endpoints. Not every endpoint supports every
+// parameter, please check Klaviyo's documentation for the endpoint you are calling.
+type Query struct {
+	// e.g. equals(metric_id,"ABC123")
+	Filter string
+
+	// The attribute to sort by, prefix with "-" for descending order.
+	Sort string
+
+	// Returned by the previous page, leave empty to get the first page.
+	Cursor string
+
+	// Leave as 0 to use Klaviyo's default.
+	PageSize int
+
+	// Sparse fieldsets, only the listed attributes are returned for each resource type. This cuts down the payload size
+	// for large exports. e.g. {"profile": {"email", "first_name"}} becomes fields[profile]=email,first_name
+	Fields map[string][]string
+
+	// Related resources to return in the "included" section of the response, e.g. "lists".
+	Include []string
+}
+
+func (q *Query) apply(u *url.URL) {
+	if q == nil {
+		return
+	}
+	values := u.Query()
+	if q.Filter != "" {
+		values.Set("filter", q.Filter)
+	}
+	if q.Sort != "" {
+		values.Set("sort", q.Sort)
+	}
+	if q.Cursor != "" {
+		values.Set("page[cursor]", q.Cursor)
+	}
+	if q.PageSize > 0 {
+		values.Set("page[size]", strconv.Itoa(q.PageSize))
+	}
+	for resource, fields := range q.Fields {
+		if len(fields) > 0 {
+			values.Set(fmt.Sprintf("fields[%s]", resource), strings.Join(fields, ","))
+		}
+	}
+	if len(q.Include) > 0 {
+		values.Set("include", strings.Join(q.Include, ","))
+	}
+	u.RawQuery = values.Encode()
+}
+
+// Resource is a generic v3 resource. Included resources can be of any type so they are decoded into this.
+type Resource struct {
+	Type       string                 `json:"type"`
+	Id         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// Identifies a single resource, used in relationships.
+type resourceIdentifier struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type relationshipOne struct {
+	Data resourceIdentifier `json:"data"`
+}
+
+// Top level document used for v3 requests.
+type document struct {
+	Data interface{} `json:"data"`
+}
+
+func (c *Client) sendV3(method string, u *url.URL, in interface{}, out interface{}) error {
+	return c.sendV3Revision(APIRevision, method, u, in, out)
+}
+
+// Same as sendV3 for endpoints which are not available in APIRevision yet.
+func (c *Client) sendV3Revision(revision, method string, u *url.URL, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		xs, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = xs
+	}
+	req, err := c.newBodyRequest(method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	// Authorization is added by doReq.
+	req.Header.Add("Revision", revision)
+	req.Header.Add("Accept", ContentJSON)
+	if in != nil {
+		req.Header.Add("Content-Type", ContentJSON)
+	}
+	return c.doReq(req, out)
+}
@@ -0,0 +1,79 @@
+package vcr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/monstercat/go-klaviyo"
+)
+
+// Sends every request to the test server instead of Klaviyo.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRecorder(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", klaviyo.ContentJSON)
+		w.Write([]byte(`{"records": [{"id": "abc", "email": "kitty@monstercat.com"}]}`))
+	}))
+	target, _ := url.Parse(server.URL)
+
+	dir := t.TempDir()
+	rec := New(dir, ModeRecord)
+	rec.Transport = &rewriteTransport{target: target}
+	client := &klaviyo.Client{PrivateKey: "secret", Transport: rec}
+
+	members, _, err := client.GetGroupMembers("ABC123", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].Id != "abc" {
+		t.Fatalf("Unexpected members %+v", members)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 recording, got %d", len(files))
+	}
+	buf, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(buf), "secret") {
+		t.Error("Recording should not contain the private key")
+	}
+
+	// Replays must not reach the server and should not care about which key is used.
+	server.Close()
+	client = &klaviyo.Client{PrivateKey: "another", Transport: New(dir, ModeReplay)}
+	members, _, err = client.GetGroupMembers("ABC123", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].Email != "kitty@monstercat.com" {
+		t.Fatalf("Unexpected replayed members %+v", members)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", hits)
+	}
+
+	if _, _, err := client.GetGroupMembers("DEF456", 0); err == nil || !strings.Contains(err.Error(), ErrNoRecording.Error()) {
+		t.Errorf("Expected ErrNoRecording, got %v", err)
+	}
+}
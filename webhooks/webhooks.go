@@ -0,0 +1,209 @@
+// Package webhooks receives Klaviyo's event webhooks. Register a function per metric name and mount the Handler:
+//
+//	h := webhooks.New(os.Getenv("KLAVIYO_WEBHOOK_SECRET"))
+//	h.Handle(klaviyo.MetricOpenedEmail, func(ctx context.Context, e *webhooks.Event) error {
+//		return markOpened(ctx, e.ProfileId, e.Properties)
+//	})
+//	http.Handle("/klaviyo/webhooks", h)
+//
+// Requests are checked against the signature Klaviyo sends before anything is decoded. When a function returns an
+// error the handler answers with a 500 so Klaviyo retries the delivery, functions should therefore be idempotent.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	klaviyo "github.com/monstercat/go-klaviyo"
+)
+
+const (
+	SignatureHeader = "Klaviyo-Signature"
+	TimestampHeader = "Klaviyo-Timestamp"
+
+	// Deliveries signed longer ago than this are rejected to stop replays.
+	DefaultTolerance = 5 * time.Minute
+
+	// Largest body read from a delivery.
+	maxBodySize = 5 * 1024 * 1024
+)
+
+var (
+	ErrNoSecret         = errors.New("webhook secret is not set")
+	ErrNoSignature      = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpired          = errors.New("webhook timestamp is outside of the tolerance")
+)
+
+// An event delivered by a webhook along with the name of its metric and the profile it belongs to when Klaviyo
+// included them.
+type Event struct {
+	klaviyo.Event
+
+	Metric  string
+	Profile *klaviyo.Resource
+}
+
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Handler is an http.Handler which verifies, decodes and dispatches webhook deliveries. Safe for concurrent use.
+type Handler struct {
+	// Shared secret of the webhook, used to verify signatures.
+	Secret string
+
+	// How old a delivery may be, defaults to DefaultTolerance. Set to a negative value to accept any age.
+	Tolerance time.Duration
+
+	// Optional, called with every delivery which was rejected or failed, e.g. to log it.
+	OnError func(r *http.Request, err error)
+
+	mu       sync.RWMutex
+	handlers map[string][]HandlerFunc
+	all      []HandlerFunc
+}
+
+func New(secret string) *Handler {
+	return &Handler{Secret: secret}
+}
+
+// Calls fn for every event of the metric with the given name, e.g. klaviyo.MetricOpenedEmail.
+func (h *Handler) Handle(metric string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.handlers == nil {
+		h.handlers = map[string][]HandlerFunc{}
+	}
+	h.handlers[metric] = append(h.handlers[metric], fn)
+}
+
+// Calls fn for every event, after the functions registered for its metric.
+func (h *Handler) HandleAll(fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.all = append(h.all, fn)
+}
+
+// Returns the signature of a delivery, the base64 encoded HMAC-SHA256 of the timestamp header followed by the body.
+// Useful for sending test deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Checks the signature and timestamp headers of a delivery against its body. Returns ErrNoSecret when Secret is empty
+// since anyone could sign deliveries with an empty key.
+func (h *Handler) Verify(header http.Header, body []byte) error {
+	if h.Secret == "" {
+		return ErrNoSecret
+	}
+	signature := header.Get(SignatureHeader)
+	timestamp := header.Get(TimestampHeader)
+	if signature == "" || timestamp == "" {
+		return ErrNoSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(h.Secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	tolerance := h.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	if tolerance > 0 {
+		secs, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		if age := time.Since(time.Unix(secs, 0)); age > tolerance || age < -tolerance {
+			return ErrExpired
+		}
+	}
+	return nil
+}
+
+// Body of a delivery, which has the same shape as the events endpoint with the metric and profile included.
+type payload struct {
+	Data     []klaviyo.Event    `json:"data"`
+	Included []klaviyo.Resource `json:"included"`
+}
+
+// Decodes the events of a delivery body.
+func Decode(body []byte) ([]*Event, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	included := map[string]*klaviyo.Resource{}
+	for i := range p.Included {
+		r := &p.Included[i]
+		included[r.Type+":"+r.Id] = r
+	}
+	events := make([]*Event, len(p.Data))
+	for i, e := range p.Data {
+		events[i] = &Event{Event: e, Profile: included["profile:"+e.ProfileId]}
+		if m, ok := included["metric:"+e.MetricId]; ok {
+			events[i].Metric, _ = m.Attributes["name"].(string)
+		}
+	}
+	return events, nil
+}
+
+func (h *Handler) funcs(metric string) []HandlerFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append(append([]HandlerFunc(nil), h.handlers[metric]...), h.all...)
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("unexpected method %s", r.Method))
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.Verify(r.Header, body); err != nil {
+		status := http.StatusUnauthorized
+		if err == ErrNoSecret {
+			// A configuration problem, answer so Klaviyo retries once it is fixed.
+			status = http.StatusInternalServerError
+		}
+		h.fail(w, r, status, err)
+		return
+	}
+	events, err := Decode(body)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	for _, e := range events {
+		for _, fn := range h.funcs(e.Metric) {
+			if err := fn(r.Context(), e); err != nil {
+				h.fail(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	klaviyo "github.com/monstercat/go-klaviyo"
+)
+
+const testBody = `{
+	"data": [{
+		"type": "event",
+		"id": "EVENT1",
+		"attributes": {"timestamp": 1700000000, "event_properties": {"Subject": "Hello"}},
+		"relationships": {
+			"metric": {"data": {"type": "metric", "id": "METRIC1"}},
+			"profile": {"data": {"type": "profile", "id": "PROFILE1"}}
+		}
+	}],
+	"included": [
+		{"type": "metric", "id": "METRIC1", "attributes": {"name": "Opened Email"}},
+		{"type": "profile", "id": "PROFILE1", "attributes": {"email": "kitty@monstercat.com"}}
+	]
+}`
+
+func newDelivery(secret, body string, at time.Time) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	ts := strconv.FormatInt(at.Unix(), 10)
+	r.Header.Set(TimestampHeader, ts)
+	r.Header.Set(SignatureHeader, Sign(secret, ts, []byte(body)))
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	h := New("secret")
+	var opened, all []*Event
+	h.Handle(klaviyo.MetricOpenedEmail, func(ctx context.Context, e *Event) error {
+		opened = append(opened, e)
+		return nil
+	})
+	h.Handle(klaviyo.MetricClickedEmail, func(ctx context.Context, e *Event) error {
+		t.Error("Unexpected call for another metric")
+		return nil
+	})
+	h.HandleAll(func(ctx context.Context, e *Event) error {
+		all = append(all, e)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newDelivery("secret", testBody, time.Now()))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Unexpected status %d", w.Code)
+	}
+	if len(opened) != 1 || len(all) != 1 {
+		t.Fatalf("Expected the event to be dispatched once to each, got %d and %d", len(opened), len(all))
+	}
+	e := opened[0]
+	if e.Id != "EVENT1" || e.Metric != klaviyo.MetricOpenedEmail || e.ProfileId != "PROFILE1" {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if e.Properties["Subject"] != "Hello" {
+		t.Errorf("Unexpected properties %v", e.Properties)
+	}
+	if e.Profile == nil || e.Profile.Attributes["email"] != "kitty@monstercat.com" {
+		t.Errorf("Expected the included profile, got %+v", e.Profile)
+	}
+}
+
+func TestHandler_Rejected(t *testing.T) {
+	var errs []error
+	h := New("secret")
+	h.OnError = func(r *http.Request, err error) {
+		errs = append(errs, err)
+	}
+	h.HandleAll(func(ctx context.Context, e *Event) error {
+		t.Error("Unexpected call for a rejected delivery")
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		r      *http.Request
+		status int
+		err    error
+	}{
+		{"wrong secret", newDelivery("other", testBody, time.Now()), http.StatusUnauthorized, ErrInvalidSignature},
+		{"expired", newDelivery("secret", testBody, time.Now().Add(-time.Hour)), http.StatusUnauthorized, ErrExpired},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(testBody)), http.StatusUnauthorized, ErrNoSignature},
+		{"bad body", newDelivery("secret", `{"data": {`, time.Now()), http.StatusBadRequest, nil},
+		{"method", httptest.NewRequest(http.MethodGet, "/webhooks", nil), http.StatusMethodNotAllowed, nil},
+	}
+	for _, test := range tests {
+		errs = nil
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, test.r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, w.Code)
+		}
+		if len(errs) != 1 || (test.err != nil && !errors.Is(errs[0], test.err)) {
+			t.Errorf("%s: expected %v to be reported, got %v", test.name, test.err, errs)
+		}
+	}
+}
+
+func TestHandler_Error(t *testing.T) {
+	h := New("secret")
+	h.HandleAll(func(ctx context.Context, e *Event) error {
+		return errors.New("database is down")
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newDelivery("secret", testBody, time.Now()))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a 500 so Klaviyo retries, got %d", w.Code)
+	}
+}
+
+func TestHandler_NoSecret(t *testing.T) {
+	h := New("")
+	h.HandleAll(func(ctx context.Context, e *Event) error {
+		t.Error("Unexpected call without a secret")
+		return nil
+	})
+	// Signed with the empty key, which anyone could do.
+	r := newDelivery("", testBody, time.Now())
+	body := []byte(testBody)
+	if err := h.Verify(r.Header, body); err != ErrNoSecret {
+		t.Errorf("Expected ErrNoSecret, got %v", err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a 500 so Klaviyo retries once the secret is set, got %d", w.Code)
+	}
+}